@@ -11,37 +11,55 @@ import (
 	pb "github.com/jfomu/DNSDistribuido/internal/proto"
 	"github.com/jfomu/DNSDistribuido/internal/config"
 	"github.com/jfomu/DNSDistribuido/internal/nodo"
+	"github.com/jfomu/DNSDistribuido/internal/nombres"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/jfomu/DNSDistribuido/internal/sesion"
+	"github.com/jfomu/DNSDistribuido/internal/validacion"
 	//"google.golang.org/grpc"
 )
 
-//// ESTRUCTURAS
-type RegistroCambio struct {
-	Reloj []int32
-	IP string
-	Port string
-}
+//// CONSTANTES
+const USO_CREATE = "[ERROR] Usar:\n\t create <nombre>.<dominio> <IP>\n\t create <nombre>.<dominio> [<ttl>] <tipo> <datos>\n\t <tipo> puede ser A, AAAA, CNAME, MX, TXT, NS, SRV o PTR, por ejemplo:\n\t create example.com 300 MX 10 mail.example.com\n"
 
 
 //// VARIABLES GLOBALES
 var configuracion *config.Config
-var dominioRegistro map[string]*RegistroCambio // Almacena para cada dominio la información del último cambio
+var sesionAdmin *sesion.Sesion // versiones escritas y leídas de cada dominio, para las garantías de sesión
 
 //// FUNCIONES
 
-func separarNombreDominio(nombreDominio string) (string, string) {
-	split := strings.Split(nombreDominio, ".")
-	var nombre string
-	var dominio string
+// Obtiene la zona de un nombre de dominio, considerando las zonas declaradas en la configuración
+// y las zonas ya utilizadas por el administrador
+func obtenerZona(nombreDominio string) (string, error) {
+	zonas := append([]string{}, configuracion.Zonas...)
+	zonas = append(zonas, sesionAdmin.Dominios()...)
+	_, zona, err := nombres.Separar(nombreDominio, zonas)
+	return zona, err
+}
+
 
-	if len(split) == 2{
-	nombre = split[0]
-	dominio = split[1]
-	} else {
-		log.Fatalf("[ERROR] Error dividiendo la variable NombreDominio")
+// Muestra la vista de la membresía del clúster, un miembro por linea en el orden de sus posiciones en los relojes
+func mostrarVista(vista *pb.Vista) {
+	log.Printf("Vista de la membresía, versión %d:\n", vista.Version)
+	for i, m := range vista.Miembros {
+		fmt.Printf("%d. %s %s:%s %s\n", i + 1, m.Id, m.Ip, m.Port, m.Estado)
 	}
-	return nombre, dominio
 }
 
+func mostrarSalud(salud *pb.Latidos) {
+	log.Printf("Estado de los servidores DNS según %s:\n", salud.IdNodo)
+	for _, l := range salud.Latidos {
+		fmt.Printf("- %s %s:%s %s (último latido hace %ds)\n", l.Id, l.Ip, l.Port, l.Estado, l.Segundos)
+	}
+	if salud.Lider != "" {
+		fmt.Printf("Coordinador de las rondas de consistencia: %s (término %d)\n", salud.Lider, salud.Termino)
+	} else {
+		fmt.Println("Sin coordinador vigente de las rondas de consistencia")
+	}
+	if salud.LiderRaft != "" {
+		fmt.Printf("Líder del log replicado de las zonas de consistencia fuerte: %s (término %d)\n", salud.LiderRaft, salud.TerminoRaft)
+	}
+}
 
 func main() {
 	log.Printf("= INICIANDO ADMIN =\n")
@@ -52,7 +70,7 @@ func main() {
 
 	// Inicializar variables
 	log.Println("Inicializando variables")
-	dominioRegistro = make(map[string]*RegistroCambio)
+	sesionAdmin = sesion.NuevaSesion()
 	
 	log.Println("Estableciendo conexión con el Broker")
 	conn, err := nodo.ConectarNodo(configuracion.Broker.Ip, configuracion.Broker.Port)
@@ -72,174 +90,165 @@ func main() {
 	for {
 		fmt.Print("-> ")
 		text, _ := reader.ReadString('\n')
-		text = strings.Replace(text, "\n", "", -1)
-		text = strings.ToLower(text)
-		words := strings.Split(text, " ")
+		text = strings.TrimSpace(strings.Replace(text, "\n", "", -1))
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			continue
+		}
+		// Solo el comando se pasa a minúsculas, el texto de un registro TXT se conserva
+		words[0] = strings.ToLower(words[0])
 		
 		//// Comando CREATE
 		if strings.Compare("create", words[0]) == 0{ 
-			// Verificar el número de parámetros y puntos en el nombre de dominio
-			if len(words) != 3 ||  len(strings.Split(words[1], ".")) != 2 {
-				log.Printf("[ERROR] Usar:\n\t create <nombre>.<dominio> <IP>\n")
+			// Verificar el número de parámetros y el formato del nombre de dominio
+			if len(words) < 3 {
+				log.Printf(USO_CREATE)
 				continue
 			}
-			
-			
-			_, dominio := separarNombreDominio(words[1])
-			var ipDNS string
-			var portDNS string
-			var registroCambio *RegistroCambio
-
-			
-			// Verificar si hay que solicitar un servidor DNS al broker o usar el registrado
-			if _, ok := dominioRegistro[dominio]; ok { // Si el registro está en memoria
-				registroCambio = dominioRegistro[dominio]
-				ipDNS = registroCambio.IP
-				portDNS = registroCambio.Port
-			} else { // Si el registro no está en memoria
-				// Solicitar un servidor DNS aleatorio al Broker
-				resp, err := broker.Get(context.Background(), new(pb.Consulta))
-				if err != nil {
-				log.Fatalf("Error al llamar a Get(): %s", err)
-				}
-				ipDNS = resp.Ip
-				portDNS = resp.Port
-
-				// Iniciar el registro en memoria
-				registroCambio = new(RegistroCambio)
-			}
-			
-			// Conectar al servidor DNS
-			log.Println("Estableciendo conexión con el nodo DNS")	
-			conn, err := nodo.ConectarNodo(ipDNS, portDNS)
+			dominio, err := obtenerZona(words[1])
 			if err != nil {
-				log.Fatalf("Error al intentar conectar al servidor DNS: %s", err)
+				log.Printf("[ERROR] %s\n", err)
+				continue
+			}
+
+			// Interpretar el registro a agregar, una IP sola corresponde a un registro A o AAAA
+			var rr *recursos.Recurso
+			if len(words) == 3 {
+				rr = &recursos.Recurso{Nombre: words[1], Tipo: recursos.TipoDireccion(words[2]), Valor: words[2]}
+			} else if rr, err = recursos.ParsearLinea(words[1] + " " + recursos.QuitarCampos(text, 2)); err != nil {
+				log.Printf("[ERROR] %s\n", err)
+				log.Printf(USO_CREATE)
+				continue
 			}
-			dns := pb.NewServicioNodoClient(conn)
 
-			// Generar la consulta y enviarla
+			// Enviar la consulta al broker, fijando el servidor DNS si la sesión ya tiene versiones del dominio
 			consulta := new(pb.Consulta)
 			consulta.NombreDominio = words[1]
-			consulta.Ip = words[2]
-			dnsResp, err := dns.Create(context.Background(), consulta)
+			consulta.Recurso = rr.Proto()
+			resp, resultado, err := sesionAdmin.Create(context.Background(), broker, dominio, consulta)
 			if err != nil {
-				log.Printf("Error al llamar a Create(): %s", err)
+				log.Printf("Error al llamar a Create(): %s", validacion.Describir(err))
 				continue
 				}
-			log.Printf("Create exitoso! - Reloj: %+v", dnsResp.Reloj)
-
-			// Actualizar la información del reloj en el registro
-			registroCambio.Reloj = dnsResp.Reloj
-			registroCambio.IP = dnsResp.Ip
-			registroCambio.Port = dnsResp.Port
-			dominioRegistro[dominio] = registroCambio
-			
-			
+			log.Printf("Create exitoso! - Reloj: %+v - Servidor: %s:%s", resp.Reloj, resp.Ip, resp.Port)
+			log.Printf("Garantías: %s\n", resultado)
 
 
 		//// Comando UPDATE
 		} else if strings.Compare("update", words[0]) == 0 {
-			// Verificar el número de parámetros y puntos en el nombre de dominio
-			if len(words) != 4 ||  len(strings.Split(words[1], ".")) != 2 || (words[2] != "ip" && words[2] != "name") { 
-				log.Printf("[ERROR] Usar:\n\t update <nombre>.<dominio> <opcion> <parámetro>\n\t <opcion> puede tomar los valores de ip o name\n")
+			// Verificar el número de parámetros y el formato del nombre de dominio
+			if len(words) != 4 || (words[2] != "ip" && words[2] != "name" && words[2] != "ttl") { 
+				log.Printf("[ERROR] Usar:\n\t update <nombre>.<dominio> <opcion> <parámetro>\n\t <opcion> puede tomar los valores de ip, name o ttl\n")
 				continue
 			}
-			
-			_, dominio := separarNombreDominio(words[1])
-			var ipDNS string
-			var portDNS string
-			var registroCambio *RegistroCambio
-
-			// Verificar si hay que solicitar un servidor DNS al broker o usar el registrado
-			if _, ok := dominioRegistro[dominio]; ok { // Si el registro está en memoria
-				registroCambio = dominioRegistro[dominio]
-				ipDNS = registroCambio.IP
-				portDNS = registroCambio.Port
-			} else { // Si el registro no está en memoria
-				// Solicitar un servidor DNS aleatorio al Broker
-				resp, err := broker.Get(context.Background(), new(pb.Consulta))
-				if err != nil {
-				log.Fatalf("Error al llamar a Get(): %s", err)
-				}
-				ipDNS = resp.Ip
-				portDNS = resp.Port
-
-				// Iniciar el registro en memoria
-				registroCambio = new(RegistroCambio)
-			}
-
-			// Conectar al servidor DNS
-			log.Println("Estableciendo conexión con el nodo DNS")
-			conn, err := nodo.ConectarNodo(ipDNS, portDNS)
+			dominio, err := obtenerZona(words[1])
 			if err != nil {
-				log.Fatalf("Error al intentar conectar al servidor DNS: %s", err)
+				log.Printf("[ERROR] %s\n", err)
+				continue
 			}
-			dns := pb.NewServicioNodoClient(conn)
 
 			consulta := new(pb.ConsultaUpdate)
 			consulta.NombreDominio = words[1]
 			consulta.Opcion = words[2]
 			consulta.Param = words[3]
 
-			dnsResp, err := dns.Update(context.Background(), consulta)
+			resp, resultado, err := sesionAdmin.Update(context.Background(), broker, dominio, consulta)
 			if err != nil {
-				log.Printf("Error al llamar a Update(): %s", err)
+				log.Printf("Error al llamar a Update(): %s", validacion.Describir(err))
 				continue
 				}
-			log.Printf("Update exitoso! - Reloj: %+v", dnsResp.Reloj)
+			log.Printf("Update exitoso! - Reloj: %+v - Servidor: %s:%s", resp.Reloj, resp.Ip, resp.Port)
+			log.Printf("Garantías: %s\n", resultado)
 			
 
 		//// Comando DELETE
 		} else if strings.Compare("delete", words[0]) == 0 {
-			// Verificar el número de parámetros y puntos en el nombre de dominio
-			if len(words) != 2 ||  len(strings.Split(words[1], ".")) != 2 {
-				log.Printf("[ERROR] Usar:\n\t delete <nombre>.<dominio>\n")
+			// Verificar el número de parámetros y el formato del nombre de dominio
+			if len(words) < 2 {
+				log.Printf("[ERROR] Usar:\n\t delete <nombre>.<dominio> [<tipo> [<datos>]]\n")
 				continue
 			}
-
-			_, dominio := separarNombreDominio(words[1])
-			var ipDNS string
-			var portDNS string
-			var registroCambio *RegistroCambio
-
-			// Verificar si hay que solicitar un servidor DNS al broker o usar el registrado
-			if _, ok := dominioRegistro[dominio]; ok { // Si el registro está en memoria
-				registroCambio = dominioRegistro[dominio]
-				ipDNS = registroCambio.IP
-				portDNS = registroCambio.Port
-			} else { // Si el registro no está en memoria
-				// Solicitar un servidor DNS aleatorio al Broker
-				resp, err := broker.Get(context.Background(), new(pb.Consulta))
-				if err != nil {
-				log.Fatalf("Error al llamar a Get(): %s", err)
-				}
-				ipDNS = resp.Ip
-				portDNS = resp.Port
-
-				// Iniciar el registro en memoria
-				registroCambio = new(RegistroCambio)
+			dominio, err := obtenerZona(words[1])
+			if err != nil {
+				log.Printf("[ERROR] %s\n", err)
+				continue
 			}
 
-			// Conectar al servidor DNS
-			log.Println("Estableciendo conexión con el nodo DNS")
-			conn, err := nodo.ConectarNodo(ipDNS, portDNS)
-			if err != nil {
-				log.Fatalf("Error al intentar conectar al servidor DNS: %s", err)
+			// Si se indica un tipo solo se eliminan esos registros, y si se indican datos solo ese registro
+			var rr *recursos.Recurso
+			if len(words) == 3 {
+				rr = &recursos.Recurso{Tipo: strings.ToUpper(words[2])}
+			} else if len(words) > 3 {
+				if rr, err = recursos.ParsearDatos(words[2], recursos.QuitarCampos(text, 3)); err != nil {
+					log.Printf("[ERROR] %s\n", err)
+					continue
+				}
 			}
-			dns := pb.NewServicioNodoClient(conn)
 
 			consulta := new(pb.ConsultaAdmin)
 			consulta.NombreDominio = words[1]
+			if rr != nil {
+				consulta.Recurso = rr.Proto()
+			}
 
-			dnsResp, err := dns.Delete(context.Background(), consulta)
+			resp, resultado, err := sesionAdmin.Delete(context.Background(), broker, dominio, consulta)
 			if err != nil {
-				log.Printf("Error al llamar a Delete(): %s", err)
+				log.Printf("Error al llamar a Delete(): %s", validacion.Describir(err))
 				continue
 				}
-			log.Printf("Delete exitoso! - Reloj: %+v", dnsResp.Reloj)
+			log.Printf("Delete exitoso! - Reloj: %+v - Servidor: %s:%s", resp.Reloj, resp.Ip, resp.Port)
+			log.Printf("Garantías: %s\n", resultado)
 			
+		//// Comando NODO
+		} else if strings.Compare("nodo", words[0]) == 0 && len(words) <= 2 {
+			// Consultar al broker el servidor DNS asignado, con afinidad al fijado por la sesión para el dominio indicado
+			solicitud := new(pb.SolicitudNodo)
+			if len(words) == 2 {
+				solicitud.NombreDominio = words[1]
+				if dominio, err := obtenerZona(words[1]); err == nil {
+					solicitud.Afinidad = sesionAdmin.Fijacion(dominio)
+				}
+			}
+			resp, err := broker.AsignarNodo(context.Background(), solicitud)
+			if err != nil {
+				log.Printf("Error al llamar a AsignarNodo(): %s", validacion.Describir(err))
+				continue
+			}
+			log.Printf("Servidor DNS asignado: %s (%s:%s) - Reloj: %+v", resp.Id, resp.Ip, resp.Port, resp.Reloj)
+
+		//// Comando MIEMBROS
+		} else if strings.Compare("miembros", words[0]) == 0 && len(words) == 1 {
+			// Una vista con versión 0 solo consulta la vista de la membresía del broker
+			resp, err := broker.ActualizarVista(context.Background(), new(pb.Vista))
+			if err != nil {
+				log.Printf("Error al llamar a ActualizarVista(): %s", validacion.Describir(err))
+				continue
+			}
+			mostrarVista(resp)
+
+		//// Comando RETIRAR
+		} else if strings.Compare("retirar", words[0]) == 0 && len(words) == 2 {
+			// Retirar un servidor DNS del clúster, por ejemplo uno que ya no responde
+			resp, err := broker.Leave(context.Background(), &pb.Miembro{Id: words[1]})
+			if err != nil {
+				log.Printf("Error al llamar a Leave(): %s", validacion.Describir(err))
+				continue
+			}
+			log.Printf("Servidor DNS %s retirado\n", words[1])
+			mostrarVista(resp)
+
+		//// Comando SALUD
+		} else if strings.Compare("salud", words[0]) == 0 && len(words) == 1 {
+			// Estado de los servidores DNS según la detección de fallas por latidos
+			resp, err := broker.ObtenerSalud(context.Background(), new(pb.Vacio))
+			if err != nil {
+				log.Printf("Error al llamar a ObtenerSalud(): %s", validacion.Describir(err))
+				continue
+			}
+			mostrarSalud(resp)
+
 		} else { // En caso de no recibir un comando válido
-			fmt.Println("Usar:\n\t create <nombre>.<dominio> <IP>\n\t update <nombre>.<dominio> <opción> <parámetro>\n\t delete <nombre>.<dominio>")
+			fmt.Println("Usar:\n\t create <nombre>.<dominio> <IP>\n\t create <nombre>.<dominio> [<ttl>] <tipo> <datos>\n\t update <nombre>.<dominio> <opción> <parámetro>\n\t delete <nombre>.<dominio> [<tipo> [<datos>]]\n\t nodo [<nombre>.<dominio>]\n\t miembros\n\t retirar <id>\n\t salud")
 		}
 	
 	  } 
@@ -4,14 +4,38 @@ import (
 	"log"
 	"context"
 	"net"
-	"math/rand"
+	"strings"
+	"sync"
+	"time"
 
 	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/cache"
 	"github.com/jfomu/DNSDistribuido/internal/config"
+	"github.com/jfomu/DNSDistribuido/internal/conexiones"
+	"github.com/jfomu/DNSDistribuido/internal/membresia"
 	"github.com/jfomu/DNSDistribuido/internal/nodo"
+	"github.com/jfomu/DNSDistribuido/internal/nombres"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/jfomu/DNSDistribuido/internal/registros"
+	"github.com/jfomu/DNSDistribuido/internal/resolucion"
+	"github.com/jfomu/DNSDistribuido/internal/seleccion"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+//// CONSTANTES
+const TIEMPO_CONSULTA_DNS = 2 * time.Second // tiempo máximo para resolver una consulta DNS estándar
+const INTERVALO_VERIFICACION = 5 * time.Second // intervalo entre verificaciones del estado de los servidores DNS
+const TIEMPO_VERIFICACION = 1 * time.Second // tiempo máximo de respuesta de ObtenerEstado
+const INTENTOS_REENVIO = 3 // servidores DNS distintos a intentar por cada solicitud reenviada
+const TIEMPO_INTENTO = 2 * time.Second // tiempo máximo de cada intento
+const TIEMPO_LIMITE_REENVIO = 5 * time.Second // tiempo máximo de una solicitud considerando todos los intentos
+const INTERVALO_METRICAS = 1 * time.Minute // intervalo entre registros del estado del pool de conexiones
+const TIEMPO_TRANSFERENCIA = 30 * time.Second // tiempo máximo para transferir las zonas de un servidor DNS a otro
+const TIEMPO_DRENADO = 10 * time.Second // tiempo máximo de espera de las solicitudes en curso de un servidor que se retira
+const RUTA_MEMBRESIA = "membresia.json" // archivo donde se persiste la vista de la membresía
+
 //// ESTRUCTURAS
 type Server struct{
 	nodo.Server
@@ -19,13 +43,54 @@ type Server struct{
 
 //// VARIABLES GLOBALES
 var configuracion *config.Config
+var selector *seleccion.Selector
+var pool *conexiones.Pool // conexiones con los servidores DNS, compartidas entre solicitudes
+var respuestas *cache.Cache // caché de respuestas de Get, nil si está desactivada
+var relojZona map[string][]int32 // reloj más reciente recibido desde cualquier servidor DNS para cada zona
+var muZonas sync.Mutex // protege relojZona
+var vista *membresia.Vista // vista de la membresía de los servidores DNS
+var muVista sync.Mutex // protege vista y serializa los cambios de membresía
 
 //// FUNCIONES
-func dnsAleatorio() (string, string){
-	idRandom := rand.Intn(3)
-	//idRandom := 0
-	//log.Printf("Servidor DNS obtenido de forma aleatoria: DNS%d\n", idRandom+1)
-	return configuracion.DNS[idRandom].Ip, configuracion.DNS[idRandom].Port
+
+// Obtiene el nombre completo normalizado de una consulta y su zona. Si el nombre es inválido se
+// retorna tal como se recibió, el servidor DNS responderá con el error correspondiente.
+func separarConsulta(nombreDominio string) (string, string) {
+	relativo, zona, err := nombres.Separar(nombreDominio, configuracion.Zonas)
+	if err != nil {
+		return nombreDominio, nombreDominio
+	}
+	return nombres.Unir(relativo, zona), zona
+}
+
+// Obtiene la clave con que se elige el servidor DNS para un nombre: su zona, de modo que con
+// hash consistente las consultas de una misma zona lleguen al mismo servidor
+func claveSeleccion(nombreDominio string) string {
+	_, zona := separarConsulta(nombreDominio)
+	return zona
+}
+
+// Verifica que un servidor DNS responda a ObtenerEstado dentro del tiempo límite
+func verificarNodo(n *seleccion.Nodo) error {
+	conn, err := pool.Obtener(n.Ip, n.Port)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_VERIFICACION)
+	defer cancel()
+	_, err = pb.NewServicioNodoClient(conn).ObtenerEstado(ctx, &pb.Consulta{})
+	return err
+}
+
+// Registra periódicamente el estado del pool de conexiones
+func registrarMetricas() {
+	for range time.Tick(INTERVALO_METRICAS) {
+		log.Printf("Pool de conexiones: %s\n", pool.Metricas())
+		if respuestas != nil {
+			log.Printf("Caché de respuestas: %s\n", respuestas.Metricas())
+		}
+	}
 }
 
 //// FUNCIONES DEL SERVER
@@ -36,58 +101,507 @@ func (s *Server) ObtenerEstado(ctx context.Context, message *pb.Consulta) (*pb.E
 }
 
 func (s *Server) Get(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
-	var dnsIP string
-	var dnsPort string
-	var respuesta *pb.Respuesta
+	respuesta, err := consultar(ctx, message)
+	if err != nil{
+		log.Printf("Error al intentar conectar al servidor del servicio: %s\n", err)
+		return nil, err
+	}
+	return respuesta, nil
+}
 
-	// Seleccionar servidor DNS
-	if message.Ip != "" && message.Port != "" {  // Si se recibieron IP y puerto como argumentos
-		dnsIP = message.Ip
-		dnsPort = message.Port
-	} else { // Si se debe entregar un servidor aleatorio
-		dnsIP, dnsPort = dnsAleatorio()
+// Comando CREATE
+func (s *Server) Create(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
+	var respuesta *pb.Respuesta
+	err := reenviarEscritura(ctx, message.NombreDominio, message.Fijacion, func(ctx context.Context, dns pb.ServicioNodoClient) ([]int32, error) {
+		var err error
+		if respuesta, err = dns.Create(ctx, message); err != nil {
+			return nil, err
+		}
+		return respuesta.Reloj, nil
+	})
+	if err != nil {
+		log.Printf("Error al reenviar Create %s: %s\n", message.NombreDominio, err)
+		return nil, err
 	}
+	return respuesta, nil
+}
 
-	if message.Ip == "" && message.Port == "" && message.NombreDominio == "" { // Se es una consulta del administrador
-		//log.Println("Enviando DNS aleatorio al Admin")
-		respuesta = &pb.Respuesta{Ip: dnsIP, Port: dnsPort}
-		return respuesta, nil
+// Comando DELETE
+func (s *Server) Delete(ctx context.Context, message *pb.ConsultaAdmin) (*pb.RespuestaAdmin, error){
+	var respuesta *pb.RespuestaAdmin
+	err := reenviarEscritura(ctx, message.NombreDominio, message.Fijacion, func(ctx context.Context, dns pb.ServicioNodoClient) ([]int32, error) {
+		var err error
+		if respuesta, err = dns.Delete(ctx, message); err != nil {
+			return nil, err
+		}
+		return respuesta.Reloj, nil
+	})
+	if err != nil {
+		log.Printf("Error al reenviar Delete %s: %s\n", message.NombreDominio, err)
+		return nil, err
 	}
+	return respuesta, nil
+}
 
-	conn, err := nodo.ConectarNodo(dnsIP, dnsPort)
-	if err != nil{
-		log.Printf("Error al intentar realizar conexión gRPC: %s\n", err)
+// Comando UPDATE
+func (s *Server) Update(ctx context.Context, message *pb.ConsultaUpdate) (*pb.RespuestaAdmin, error){
+	var respuesta *pb.RespuestaAdmin
+	err := reenviarEscritura(ctx, message.NombreDominio, message.Fijacion, func(ctx context.Context, dns pb.ServicioNodoClient) ([]int32, error) {
+		var err error
+		if respuesta, err = dns.Update(ctx, message); err != nil {
+			return nil, err
+		}
+		return respuesta.Reloj, nil
+	})
+	if err != nil {
+		log.Printf("Error al reenviar Update %s: %s\n", message.NombreDominio, err)
 		return nil, err
 	}
+	return respuesta, nil
+}
 
-	dnsServer := pb.NewServicioNodoClient(conn)
-	respuesta, err = dnsServer.Get(context.Background(), message)
-	if err != nil{
-		log.Printf("Error al intentar conectar al servidor del servicio: %s\n", err)
+// Asigna un servidor DNS activo según la estrategia configurada. Con afinidad se prefiere el
+// servidor indicado, por ejemplo el de la última escritura de la sesión del administrador, si
+// sigue activo.
+func (s *Server) AsignarNodo(ctx context.Context, message *pb.SolicitudNodo) (*pb.NodoAsignado, error){
+	asignado := new(pb.NodoAsignado)
+	zona := ""
+	if message.NombreDominio != "" {
+		_, zona = separarConsulta(message.NombreDominio)
+	}
+
+	var elegido *seleccion.Nodo
+	if f := message.Afinidad; fijado(f) {
+		if preferido, ok := selector.Buscar(f.Ip, f.Port); ok && preferido.Activo() {
+			elegido = preferido
+		}
+	}
+	if elegido == nil {
+		var err error
+		if elegido, err = selector.Elegir(zona); err != nil {
+			log.Printf("Error al seleccionar servidor DNS: %s\n", err)
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	}
+
+	asignado.Id = elegido.Id
+	asignado.Ip = elegido.Ip
+	asignado.Port = elegido.Port
+	if zona != "" {
+		muZonas.Lock()
+		asignado.Reloj = append([]int32{}, relojZona[zona]...)
+		muZonas.Unlock()
+	}
+	return asignado, nil
+}
+
+//// MEMBRESÍA
+
+// Agrega un servidor DNS al clúster: lo incorpora a la vista como UNIENDOSE, le transfiere el
+// estado de las zonas desde un miembro activo y luego lo activa para que reciba consultas. Un
+// nodo que ya era miembro, por ejemplo tras reiniciarse, recibe los cambios que no conoce.
+func (s *Server) Join(ctx context.Context, message *pb.Miembro) (*pb.Vista, error){
+	muVista.Lock()
+	defer muVista.Unlock()
+
+	nuevo := membresia.MiembroDesdeProto(message)
+	nueva, err := vista.Unir(nuevo)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("Uniendo al servidor DNS %s (%s:%s)\n", nuevo.Id, nuevo.Ip, nuevo.Port)
+	establecerVista(nueva)
+
+	// Transferir las zonas desde cada miembro activo, ya que entre rondas de coordinación las
+	// réplicas pueden tener cambios distintos; el nodo fusiona los estados que recibe. Si ninguno
+	// responde, por ejemplo al iniciar el clúster, el nodo se activa con su propio estado y recibe
+	// los cambios de los demás en la siguiente ronda de coordinación.
+	transferidos := 0
+	for _, donante := range nueva.Nodos(membresia.ACTIVO) {
+		if err = transferir(donante, membresia.MiembroProto(nuevo, membresia.UNIENDOSE)); err != nil {
+			log.Printf("Error al transferir las zonas de %s a %s: %s\n", donante.Id, nuevo.Id, err)
+			continue
+		}
+		transferidos += 1
+	}
+	if transferidos == 0 {
+		log.Printf("Ningún miembro activo pudo transferir las zonas a %s, se activa con su propio estado\n", nuevo.Id)
+	}
+
+	activa, _ := vista.CambiarEstado(nuevo.Id, membresia.ACTIVO)
+	establecerVista(activa)
+	return activa.Proto(), nil
+}
+
+// Retira un servidor DNS del clúster: deja de elegirlo para nuevas solicitudes, espera las que
+// tiene en curso y le pide transferir su estado a un miembro activo antes de marcarlo retirado.
+// También permite retirar un nodo que ya no responde, cuyos cambios no replicados se pierden.
+func (s *Server) Leave(ctx context.Context, message *pb.Miembro) (*pb.Vista, error){
+	muVista.Lock()
+	defer muVista.Unlock()
+
+	i, ok := vista.Buscar(message.Id)
+	if !ok || vista.Miembros[i].Estado == membresia.RETIRADO {
+		return nil, status.Error(codes.NotFound, "El nodo " + message.Id + " no es miembro del clúster")
+	}
+	saliente := vista.Miembros[i].NodeInfo
+	var destinos []config.NodeInfo
+	for _, n := range vista.Nodos(membresia.ACTIVO) {
+		if n.Id != saliente.Id {
+			destinos = append(destinos, n)
+		}
+	}
+	if len(destinos) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "No se puede retirar el último servidor DNS activo")
+	}
+
+	log.Printf("Retirando al servidor DNS %s\n", saliente.Id)
+	elegible, _ := selector.Buscar(saliente.Ip, saliente.Port)
+	saliendo, _ := vista.CambiarEstado(saliente.Id, membresia.SALIENDO)
+	establecerVista(saliendo)
+	drenar(elegible)
+
+	// Entregar el estado del nodo a un miembro activo
+	var err error
+	for _, destino := range destinos {
+		if err = transferir(saliente, membresia.MiembroProto(destino, membresia.ACTIVO)); err == nil {
+			break
+		}
+		log.Printf("Error al transferir las zonas de %s a %s: %s\n", saliente.Id, destino.Id, err)
+	}
+	if err != nil {
+		log.Printf("No se pudo transferir el estado de %s, sus cambios no replicados se pierden\n", saliente.Id)
+	}
+
+	retirada, _ := vista.CambiarEstado(saliente.Id, membresia.RETIRADO)
+	establecerVista(retirada, saliente) // El nodo retirado también recibe la vista
+	return retirada.Proto(), nil
+}
+
+// Entrega la vista de la membresía si se recibe con version 0, o adopta la recibida si es más
+// reciente, por ejemplo la que conoce un servidor DNS si se perdió el archivo del broker
+func (s *Server) ActualizarVista(ctx context.Context, message *pb.Vista) (*pb.Vista, error){
+	muVista.Lock()
+	defer muVista.Unlock()
+	if message.Version > vista.Version {
+		establecerVista(membresia.DesdeProto(message))
+	}
+	return vista.Proto(), nil
+}
+
+// Entrega el estado de los servidores DNS según la detección de fallas de alguno de ellos
+func (s *Server) ObtenerSalud(ctx context.Context, message *pb.Vacio) (*pb.Latidos, error){
+	var respuesta *pb.Latidos
+	err := reenviar(ctx, "", seleccion.EsFallaDeNodo, func(ctx context.Context, dns pb.ServicioNodoClient) (err error) {
+		respuesta, err = dns.ObtenerSalud(ctx, message)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+	return respuesta, nil
+}
 
+// Establece una nueva vista: la persiste, actualiza los servidores elegibles (solo los activos) y
+// las conexiones (con los miembros vigentes), y la envía a los miembros vigentes y a los nodos
+// indicados. Debe llamarse manteniendo muVista.
+func establecerVista(nueva *membresia.Vista, avisar ...config.NodeInfo) {
+	vista = nueva
+	if err := nueva.Escribir(RUTA_MEMBRESIA); err != nil {
+		log.Printf("Error al guardar la vista de la membresía: %s\n", err)
+	}
+	destinos := append(nueva.Vigentes(), avisar...)
+	selector.Actualizar(nueva.Nodos(membresia.ACTIVO))
+	pool.Sincronizar(destinos)
+	log.Printf("Vista de la membresía: %s\n", nueva)
+	difundirVista(nueva, destinos)
+}
+
+// Envía una vista a los nodos indicados en paralelo y retorna la más reciente entre la enviada y
+// las que los nodos conocen
+func difundirVista(v *membresia.Vista, nodos []config.NodeInfo) *membresia.Vista {
+	reciente := v
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, n := range nodos {
+		wg.Add(1)
+		go func(n config.NodeInfo) {
+			defer wg.Done()
+			var respuesta *pb.Vista
+			err := llamarMiembro(n, TIEMPO_INTENTO, func(ctx context.Context, dns pb.ServicioNodoClient) (err error) {
+				respuesta, err = dns.ActualizarVista(ctx, v.Proto())
+				return err
+			})
+			if err != nil {
+				log.Printf("Error al enviar la vista de la membresía a %s: %s\n", n.Id, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if respuesta.Version > reciente.Version {
+				reciente = membresia.DesdeProto(respuesta)
+			}
+		}(n)
+	}
+	wg.Wait()
+	return reciente
+}
+
+// Envía la vista a los servidores DNS al iniciar el broker. Si alguno conoce una vista más
+// reciente, por ejemplo porque se perdió el archivo de membresía, se adopta esa vista.
+func sincronizarVista() {
+	muVista.Lock()
+	defer muVista.Unlock()
+	if reciente := difundirVista(vista, vista.Vigentes()); reciente.Version > vista.Version {
+		establecerVista(reciente)
+	}
+}
+
+// Pide a un servidor DNS que envíe el estado de todas sus zonas a otro miembro
+func transferir(origen config.NodeInfo, destino *pb.Miembro) error {
+	return llamarMiembro(origen, TIEMPO_TRANSFERENCIA, func(ctx context.Context, dns pb.ServicioNodoClient) error {
+		_, err := dns.TransferirEstado(ctx, destino)
+		return err
+	})
+}
+
+// Realiza una solicitud de membresía a un servidor DNS. Como el nodo puede estar iniciándose, se
+// reinicia la espera entre reintentos de su conexión y se espera hasta TIEMPO_INTENTO a que
+// responda a ObtenerEstado antes de realizar la solicitud, que tiene el tiempo límite indicado.
+func llamarMiembro(n config.NodeInfo, tiempo time.Duration, llamar func(context.Context, pb.ServicioNodoClient) error) error {
+	conn, err := pool.Obtener(n.Ip, n.Port)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	conn.ResetConnectBackoff()
+	dns := pb.NewServicioNodoClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_INTENTO)
+	_, err = dns.ObtenerEstado(ctx, &pb.Consulta{}, grpc.WaitForReady(true))
+	cancel()
+	if err != nil {
+		return err
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), tiempo)
+	defer cancel()
+	return llamar(ctx, dns)
+}
+
+// Espera a que terminen las solicitudes en curso de un servidor DNS, hasta TIEMPO_DRENADO
+func drenar(n *seleccion.Nodo) {
+	if n == nil {
+		return
+	}
+	limite := time.Now().Add(TIEMPO_DRENADO)
+	for n.Pendientes() > 0 && time.Now().Before(limite) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if pendientes := n.Pendientes(); pendientes > 0 {
+		log.Printf("El servidor DNS %s se retira con %d solicitudes en curso\n", n.Id, pendientes)
+	}
+}
+
+//// REENVÍO DE CONSULTAS
+
+// Obtiene la respuesta a un Get desde la caché o, si no está, reenviándolo a un servidor DNS.
+// Las consultas que fijan el servidor no se responden desde la caché, pero su reloj se registra.
+func consultar(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error) {
+	nombre, zona := separarConsulta(message.NombreDominio)
+	tipo := strings.ToUpper(message.Tipo)
+	if tipo == "" {
+		tipo = recursos.A
+	}
+	if respuestas != nil && !fijado(message.Fijacion) {
+		if respuesta, ok := respuestas.Obtener(zona, nombre, tipo); ok {
+			return respuesta, nil
+		}
+	}
+
+	respuesta, err := reenviarGet(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	registrarReloj(zona, respuesta.Reloj)
+	if respuestas != nil {
+		respuestas.Guardar(zona, nombre, tipo, respuesta)
+	}
 	return respuesta, nil
 }
-/*
-func (s *Server) Create(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
-	return nil, errors.New("Método Create() no implementado en este nodo")
+
+// Indica si una consulta fija el servidor DNS al que se reenvía
+func fijado(fijacion *pb.Fijacion) bool {
+	return fijacion.GetIp() != "" && fijacion.GetPort() != ""
 }
 
-func (s *Server) Delete(ctx context.Context, message *pb.ConsultaAdmin) (*pb.RespuestaAdmin, error){
-	return nil, errors.New("Método Delete() no implementado en este nodo")
+// Realiza una solicitud a un servidor DNS, registrando el resultado en el selector si el servidor es conocido
+func llamarNodo(ctx context.Context, elegido *seleccion.Nodo, dnsIP string, dnsPort string, llamar func(context.Context, pb.ServicioNodoClient) error) error {
+	conn, err := pool.Obtener(dnsIP, dnsPort)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, TIEMPO_INTENTO)
+	defer cancel()
+	if elegido != nil {
+		selector.Iniciar(elegido)
+	}
+	err = llamar(ctx, pb.NewServicioNodoClient(conn))
+	if elegido != nil {
+		selector.Terminar(elegido, err)
+	}
+	return err
 }
 
+// Reenvía una solicitud a un servidor DNS elegido por el selector. Mientras el servidor no esté
+// disponible y el error permita reintentar, se reintenta en otra réplica activa hasta agotar los
+// intentos o el tiempo límite.
+func reenviar(ctx context.Context, clave string, reintentar func(error) bool, llamar func(context.Context, pb.ServicioNodoClient) error) error {
+	ctx, cancel := context.WithTimeout(ctx, TIEMPO_LIMITE_REENVIO)
+	defer cancel()
 
-func (s *Server) Update(ctx context.Context, message *pb.ConsultaUpdate) (*pb.RespuestaAdmin, error){
-	return nil, errors.New("Método Update() no implementado en este nodo")
-}*/
+	var intentados []*seleccion.Nodo
+	var ultimoError error
+	for len(intentados) < INTENTOS_REENVIO {
+		elegido, err := selector.Elegir(clave, intentados...)
+		if err != nil { // No quedan réplicas activas sin intentar
+			if ultimoError == nil {
+				ultimoError = status.Error(codes.Unavailable, err.Error())
+			}
+			break
+		}
+		intentados = append(intentados, elegido)
+
+		err = llamarNodo(ctx, elegido, elegido.Ip, elegido.Port, llamar)
+		if err == nil || !reintentar(err) {
+			return err
+		}
+		log.Printf("Servidor DNS %s no disponible: %s\n", elegido.Id, err)
+		ultimoError = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return ultimoError
+}
+
+// Reenvía una consulta Get a un servidor DNS, reintentando en otra réplica si no está disponible.
+// Si la consulta fija el servidor (lecturas monotónicas) no se reintenta en otro: se retorna un
+// error reconocible con seleccion.EsNodoFijadoNoDisponible para que el cliente decida.
+func reenviarGet(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error) {
+	var respuesta *pb.Respuesta
+	get := func(ctx context.Context, dns pb.ServicioNodoClient) (err error) {
+		respuesta, err = dns.Get(ctx, message)
+		return err
+	}
+
+	if f := message.Fijacion; fijado(f) {
+		ctx, cancel := context.WithTimeout(ctx, TIEMPO_LIMITE_REENVIO)
+		defer cancel()
+		elegido, _ := selector.Buscar(f.Ip, f.Port)
+		err := llamarNodo(ctx, elegido, f.Ip, f.Port, get)
+		if seleccion.EsFallaDeNodo(err) {
+			return nil, seleccion.ErrorNodoFijado(f.Ip, f.Port, err)
+		}
+		return respuesta, err
+	}
+
+	err := reenviar(ctx, claveSeleccion(message.NombreDominio), seleccion.EsFallaDeNodo, get)
+	return respuesta, err
+}
+
+// Solo se reintenta una escritura si no alcanzó a llegar al servidor, para no aplicarla dos veces
+func escrituraNoEnviada(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// Reenvía una escritura a un servidor DNS, reintentando en otra réplica solo si no alcanzó a
+// llegar. El reloj resultante se registra en la caché para descartar las respuestas anteriores
+// al cambio. Si la escritura fija el servidor solo se envía a ese servidor y, si no está
+// disponible, se retorna un error reconocible con seleccion.EsNodoFijadoNoDisponible. La
+// lectura de escrituras propias la aplica la sesión de cada administrador al fijar el servidor
+// de sus escrituras anteriores (paquete internal/sesion).
+func reenviarEscritura(ctx context.Context, nombreDominio string, fijacion *pb.Fijacion, escribir func(context.Context, pb.ServicioNodoClient) ([]int32, error)) error {
+	_, zona := separarConsulta(nombreDominio)
+	var reloj []int32
+	llamar := func(ctx context.Context, dns pb.ServicioNodoClient) (err error) {
+		reloj, err = escribir(ctx, dns)
+		return err
+	}
+	var err error
+	if fijado(fijacion) {
+		ctx, cancel := context.WithTimeout(ctx, TIEMPO_LIMITE_REENVIO)
+		defer cancel()
+		elegido, _ := selector.Buscar(fijacion.Ip, fijacion.Port)
+		if err = llamarNodo(ctx, elegido, fijacion.Ip, fijacion.Port, llamar); escrituraNoEnviada(err) {
+			return seleccion.ErrorNodoFijado(fijacion.Ip, fijacion.Port, err)
+		}
+	} else {
+		err = reenviar(ctx, zona, escrituraNoEnviada, llamar)
+	}
+	if err != nil {
+		return err
+	}
+	registrarReloj(zona, reloj)
+	if respuestas != nil {
+		respuestas.Observar(zona, reloj)
+	}
+	return nil
+}
+
+// Registra el reloj de una zona recibido desde un servidor DNS
+func registrarReloj(zona string, reloj []int32) {
+	muZonas.Lock()
+	defer muZonas.Unlock()
+	relojZona[zona] = registros.FusionarRelojes(relojZona[zona], reloj)
+}
+
+//// CONSULTAS DNS ESTÁNDAR
+
+// Resuelve una consulta DNS estándar mediante Get en un servidor DNS elegido por el broker.
+// Los códigos gRPC de Get se traducen a códigos DNS y los CNAME se siguen con nuevas consultas.
+func resolverBroker(nombre string, tipo string) *resolucion.Respuesta {
+	ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_CONSULTA_DNS)
+	defer cancel()
+	if tipo == "" {
+		tipo = "ANY"
+	}
+
+	respuesta := &resolucion.Respuesta{Codigo: resolucion.NOERROR, Autoritativa: true}
+	for i := 0; i < 8; i++ { // Límite de alias a seguir, para evitar ciclos
+		resp, err := consultar(ctx, &pb.Consulta{NombreDominio: nombre, Tipo: tipo})
+		if err != nil {
+			if i > 0 { // El destino de un alias fuera de las zonas no impide responder con el alias
+				break
+			}
+			switch status.Code(err) {
+			case codes.NotFound:
+				return &resolucion.Respuesta{Codigo: resolucion.NXDOMAIN, Autoritativa: true}
+			case codes.FailedPrecondition, codes.InvalidArgument:
+				return &resolucion.Respuesta{Codigo: resolucion.REFUSED}
+			}
+			log.Printf("Error al resolver %s: %s\n", nombre, err)
+			return &resolucion.Respuesta{Codigo: resolucion.SERVFAIL}
+		}
+
+		for _, rr := range resp.Recursos {
+			if recurso, err := recursos.DesdeProto(rr); err == nil {
+				respuesta.Recursos = append(respuesta.Recursos, recurso)
+			}
+		}
+		ultimo := len(respuesta.Recursos) - 1
+		if len(resp.Recursos) != 1 || tipo == recursos.CNAME || tipo == "ANY" || respuesta.Recursos[ultimo].Tipo != recursos.CNAME {
+			break
+		}
+		nombre = respuesta.Recursos[ultimo].Valor
+	}
+	return respuesta
+}
 
 
 // FUNCIONES
 func iniciarNodo(port string) {
 	// Iniciar servidor gRPC
-	log.Printf("Iniciando servidor gRPC en el puerto " + port)
+	log.Printf("Iniciando servidor gRPC en el puerto %s", port)
 	lis, err := net.Listen("tcp", ":" + port)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
@@ -111,7 +625,7 @@ func iniciarNodo(port string) {
 /*
 func conectarNodo(ip string, port string) (*grpc.ClientConn, error) {
 	var conn *grpc.ClientConn
-	log.Printf("Intentando iniciar conexión con " + ip + ":" + port)
+	log.Printf("Intentando iniciar conexión con %s:%s", ip, port)
 	host := ip + ":" + port
 	conn, err := grpc.Dial(host, grpc.WithInsecure())
 	if err != nil {
@@ -128,5 +642,42 @@ func main() {
 	// Cargar archivo de configuración
 	configuracion = config.GenConfig("config.json")
 
+	// Preparar la selección de servidores DNS y verificar periódicamente su estado
+	if configuracion.Estrategia == "" {
+		configuracion.Estrategia = seleccion.ALEATORIA
+	}
+	estrategia, err := seleccion.NuevaEstrategia(configuracion.Estrategia)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	// La vista de la membresía persistida prevalece sobre los servidores DNS de la configuración
+	if vista, err = membresia.Leer(RUTA_MEMBRESIA); err != nil {
+		log.Fatalf("Error al leer la vista de la membresía: %s", err)
+	}
+	if vista == nil {
+		vista = membresia.DesdeConfig(configuracion.DNS)
+	}
+	selector = seleccion.NuevoSelector(vista.Nodos(membresia.ACTIVO), estrategia)
+	log.Printf("Estrategia de selección de servidores DNS: %s\n", configuracion.Estrategia)
+	pool = conexiones.NuevoPool()
+	relojZona = make(map[string][]int32)
+	if configuracion.CacheTTL > 0 {
+		respuestas = cache.NuevaCache(time.Duration(configuracion.CacheTTL) * time.Second)
+		log.Printf("Caché de respuestas activada, TTL máximo: %d segundos\n", configuracion.CacheTTL)
+	}
+	go selector.IniciarVerificaciones(INTERVALO_VERIFICACION, verificarNodo)
+	go registrarMetricas()
+	go sincronizarVista()
+
+	// Atender consultas DNS estándar si se configuró su puerto
+	if configuracion.Broker.DnsPort != "" {
+		go func() {
+			log.Printf("Atendiendo consultas DNS en el puerto %s (UDP y TCP)\n", configuracion.Broker.DnsPort)
+			if err := resolucion.Iniciar(configuracion.Broker.DnsPort, resolucion.Manejador(resolverBroker)); err != nil {
+				log.Printf("Error al atender consultas DNS: %s\n", err)
+			}
+		}()
+	}
+
 	iniciarNodo(configuracion.Broker.Port)
 }
\ No newline at end of file
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	"github.com/jfomu/DNSDistribuido/internal/conexiones"
+	"github.com/jfomu/DNSDistribuido/internal/membresia"
+	"github.com/jfomu/DNSDistribuido/internal/nodo"
+	"github.com/jfomu/DNSDistribuido/internal/seleccion"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Servidor DNS de prueba, responde las solicitudes con su ID y el reloj indicado, o con el error indicado
+type dnsPrueba struct{
+	nodo.Server
+	id string
+	mu sync.Mutex
+	err error
+	reloj []int32
+	solicitudes int // Get y Create recibidos
+	transferencias []string // destinos de TransferirEstado
+}
+
+func (d *dnsPrueba) responder() ([]int32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.solicitudes += 1
+	return d.reloj, d.err
+}
+
+func (d *dnsPrueba) fallar(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+}
+
+func (d *dnsPrueba) recibidas() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.solicitudes
+}
+
+func (d *dnsPrueba) ObtenerEstado(ctx context.Context, message *pb.Consulta) (*pb.Estado, error){
+	return &pb.Estado{Estado: "OK"}, nil
+}
+
+func (d *dnsPrueba) Get(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
+	reloj, err := d.responder()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Respuesta{Respuesta: d.id, Reloj: reloj}, nil
+}
+
+func (d *dnsPrueba) Create(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
+	reloj, err := d.responder()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Respuesta{Respuesta: d.id, Reloj: reloj}, nil
+}
+
+func (d *dnsPrueba) ActualizarVista(ctx context.Context, message *pb.Vista) (*pb.Vista, error){
+	return message, nil
+}
+
+func (d *dnsPrueba) TransferirEstado(ctx context.Context, message *pb.Miembro) (*pb.Estado, error){
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.transferencias = append(d.transferencias, message.Id)
+	return &pb.Estado{Estado: "OK"}, nil
+}
+
+// Atiende un servidor DNS de prueba en un puerto libre y retorna su información
+func servirDNS(t *testing.T, id string) (*dnsPrueba, config.NodeInfo) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	dns := &dnsPrueba{id: id}
+	grpcServer := grpc.NewServer()
+	pb.RegisterServicioNodoServer(grpcServer, dns)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return dns, config.NodeInfo{Id: id, Ip: "127.0.0.1", Port: port}
+}
+
+// Información de un servidor DNS que no responde, en un puerto libre sin servidor
+func nodoCaido(t *testing.T, id string) config.NodeInfo {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	lis.Close()
+	return config.NodeInfo{Id: id, Ip: "127.0.0.1", Port: port}
+}
+
+// Inicia el estado del broker con los servidores DNS indicados y selección round-robin, dentro de
+// un directorio temporal
+func iniciarBroker(t *testing.T, nodos ...config.NodeInfo) {
+	dir, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(dir) })
+
+	configuracion = &config.Config{DNS: nodos, Estrategia: seleccion.ROUND_ROBIN}
+	estrategia, _ := seleccion.NuevaEstrategia(configuracion.Estrategia)
+	vista = membresia.DesdeConfig(nodos)
+	selector = seleccion.NuevoSelector(vista.Nodos(membresia.ACTIVO), estrategia)
+	pool = conexiones.NuevoPool()
+	t.Cleanup(pool.Cerrar)
+	respuestas = nil
+	relojZona = make(map[string][]int32)
+}
+
+// Busca en el selector un servidor DNS por su información
+func elegible(n config.NodeInfo) *seleccion.Nodo {
+	elegido, _ := selector.Buscar(n.Ip, n.Port)
+	return elegido
+}
+
+func TestGetReintenta(t *testing.T) {
+	caido := nodoCaido(t, "DNS1")
+	dns2, nodo2 := servirDNS(t, "DNS2")
+	iniciarBroker(t, caido, nodo2)
+	s := new(Server)
+
+	// Cualquiera sea el servidor elegido primero, la consulta la responde el que está disponible
+	for i := 0; i < 2; i++ {
+		respuesta, err := s.Get(context.Background(), &pb.Consulta{NombreDominio: "google.com"})
+		assert.Nil(t, err)
+		assert.Equal(t, "DNS2", respuesta.Respuesta)
+	}
+	assert.Equal(t, 2, dns2.recibidas())
+
+	// Un error de la solicitud no se reintenta ni cuenta como falla del servidor
+	dns2.fallar(status.Error(codes.NotFound, "No existe"))
+	for i := 0; i < seleccion.UMBRAL_FALLOS; i++ {
+		_, err := s.Get(context.Background(), &pb.Consulta{NombreDominio: "google.com"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+	assert.Equal(t, 2 + seleccion.UMBRAL_FALLOS, dns2.recibidas())
+	assert.True(t, elegible(nodo2).Activo())
+}
+
+func TestGetIntentosLimitados(t *testing.T) {
+	var servidores []*dnsPrueba
+	var nodos []config.NodeInfo
+	for _, id := range []string{"DNS1", "DNS2", "DNS3", "DNS4"} {
+		dns, n := servirDNS(t, id)
+		dns.fallar(status.Error(codes.Unavailable, "Sobrecargado"))
+		servidores = append(servidores, dns)
+		nodos = append(nodos, n)
+	}
+	iniciarBroker(t, nodos...)
+
+	// Se intentan INTENTOS_REENVIO servidores distintos, cada uno una vez
+	_, err := new(Server).Get(context.Background(), &pb.Consulta{NombreDominio: "google.com"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	total := 0
+	for _, dns := range servidores {
+		assert.LessOrEqual(t, dns.recibidas(), 1)
+		total += dns.recibidas()
+	}
+	assert.Equal(t, INTENTOS_REENVIO, total)
+}
+
+func TestGetFijado(t *testing.T) {
+	caido := nodoCaido(t, "DNS1")
+	dns2, nodo2 := servirDNS(t, "DNS2")
+	iniciarBroker(t, caido, nodo2)
+	s := new(Server)
+
+	// Si el servidor fijado no responde no se reintenta en otro
+	_, err := s.Get(context.Background(), &pb.Consulta{NombreDominio: "google.com", Fijacion: &pb.Fijacion{Ip: caido.Ip, Port: caido.Port}})
+	assert.True(t, seleccion.EsNodoFijadoNoDisponible(err))
+	assert.Equal(t, 0, dns2.recibidas())
+
+	respuesta, err := s.Get(context.Background(), &pb.Consulta{NombreDominio: "google.com", Fijacion: &pb.Fijacion{Ip: nodo2.Ip, Port: nodo2.Port}})
+	assert.Nil(t, err)
+	assert.Equal(t, "DNS2", respuesta.Respuesta)
+}
+
+func TestEscrituraRechazada(t *testing.T) {
+	dns1, nodo1 := servirDNS(t, "DNS1")
+	dns2, nodo2 := servirDNS(t, "DNS2")
+	iniciarBroker(t, nodo1, nodo2)
+	s := new(Server)
+
+	// Una escritura rechazada por el servidor no se reintenta en otro ni lo marca inactivo
+	dns1.fallar(status.Error(codes.AlreadyExists, "Ya existe"))
+	dns2.fallar(status.Error(codes.AlreadyExists, "Ya existe"))
+	for i := 0; i < 2 * seleccion.UMBRAL_FALLOS; i++ {
+		_, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	}
+	assert.Equal(t, 2 * seleccion.UMBRAL_FALLOS, dns1.recibidas() + dns2.recibidas())
+	assert.True(t, elegible(nodo1).Activo())
+	assert.True(t, elegible(nodo2).Activo())
+
+	// Sin fijar el servidor, las escrituras se reparten según la estrategia
+	dns1.fallar(nil)
+	dns2.fallar(nil)
+	for i := 0; i < 2; i++ {
+		_, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "yahoo.com", Ip: "1.1.1.1"})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2 * seleccion.UMBRAL_FALLOS + 2, dns1.recibidas() + dns2.recibidas())
+	assert.Equal(t, dns1.recibidas(), dns2.recibidas())
+}
+
+func TestEscrituraFijada(t *testing.T) {
+	caido := nodoCaido(t, "DNS1")
+	dns2, nodo2 := servirDNS(t, "DNS2")
+	iniciarBroker(t, caido, nodo2)
+	s := new(Server)
+
+	// Una escritura fijada no se reintenta en otro servidor
+	_, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8", Fijacion: &pb.Fijacion{Ip: caido.Ip, Port: caido.Port}})
+	assert.True(t, seleccion.EsNodoFijadoNoDisponible(err))
+	assert.Equal(t, 0, dns2.recibidas())
+
+	// La IP del registro A no fija el servidor
+	respuesta, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8", Fijacion: &pb.Fijacion{Ip: nodo2.Ip, Port: nodo2.Port}})
+	assert.Nil(t, err)
+	assert.Equal(t, "DNS2", respuesta.Respuesta)
+	assert.Equal(t, 1, dns2.recibidas())
+}
+
+func TestAsignarNodo(t *testing.T) {
+	dns1, nodo1 := servirDNS(t, "DNS1")
+	dns2, nodo2 := servirDNS(t, "DNS2")
+	dns1.reloj, dns2.reloj = []int32{1,0}, []int32{0,1}
+	iniciarBroker(t, nodo1, nodo2)
+	s := new(Server)
+
+	// Sin escrituras se asigna cualquier servidor activo, sin reloj conocido
+	asignado, err := s.AsignarNodo(context.Background(), &pb.SolicitudNodo{NombreDominio: "google.com"})
+	assert.Nil(t, err)
+	assert.Contains(t, []string{"DNS1", "DNS2"}, asignado.Id)
+	assert.Empty(t, asignado.Reloj)
+
+	// Con afinidad se asigna el servidor indicado, con el reloj registrado para la zona
+	_, err = s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+	assert.Nil(t, err)
+	for i := 0; i < 2; i++ {
+		asignado, err = s.AsignarNodo(context.Background(), &pb.SolicitudNodo{NombreDominio: "yahoo.com", Afinidad: &pb.Fijacion{Ip: nodo2.Ip, Port: nodo2.Port}})
+		assert.Nil(t, err)
+		assert.Equal(t, "DNS2", asignado.Id)
+		assert.Equal(t, nodo2.Ip, asignado.Ip)
+		assert.Equal(t, nodo2.Port, asignado.Port)
+		assert.Equal(t, relojZona["com"], asignado.Reloj)
+		assert.NotEmpty(t, asignado.Reloj)
+	}
+
+	// Sin afinidad se reparte entre los servidores según la estrategia
+	ids := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		asignado, err = s.AsignarNodo(context.Background(), &pb.SolicitudNodo{NombreDominio: "google.com"})
+		assert.Nil(t, err)
+		ids[asignado.Id] = true
+	}
+	assert.Len(t, ids, 2)
+}
+
+// Destinos de TransferirEstado recibidos por un servidor DNS de prueba
+func (d *dnsPrueba) transferidos() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.transferencias...)
+}
+
+// Estado de un miembro en la vista del broker
+func estadoMiembro(id string) string {
+	i, ok := vista.Buscar(id)
+	if !ok {
+		return ""
+	}
+	return vista.Miembros[i].Estado
+}
+
+func TestJoinLeave(t *testing.T) {
+	dns1, nodo1 := servirDNS(t, "DNS1")
+	dns2, nodo2 := servirDNS(t, "DNS2")
+	dns3, nodo3 := servirDNS(t, "DNS3")
+	iniciarBroker(t, nodo1, nodo2)
+	s := new(Server)
+
+	// El nodo que se une recibe las zonas de cada miembro activo y luego se activa
+	_, err := s.Join(context.Background(), membresia.MiembroProto(nodo3, membresia.UNIENDOSE))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DNS3"}, dns1.transferidos())
+	assert.Equal(t, []string{"DNS3"}, dns2.transferidos())
+	assert.Empty(t, dns3.transferidos())
+	assert.Equal(t, membresia.ACTIVO, estadoMiembro("DNS3"))
+	assert.NotNil(t, elegible(nodo3))
+
+	// El nodo que se retira entrega su estado a otro miembro activo y deja de ser elegible
+	_, err = s.Leave(context.Background(), &pb.Miembro{Id: "DNS1"})
+	assert.Nil(t, err)
+	transferidos := dns1.transferidos()
+	assert.Len(t, transferidos, 2)
+	assert.Contains(t, []string{"DNS2", "DNS3"}, transferidos[1])
+	assert.Equal(t, membresia.RETIRADO, estadoMiembro("DNS1"))
+	assert.Nil(t, elegible(nodo1))
+
+	// Un nodo retirado no puede retirarse otra vez, ni el último activo
+	_, err = s.Leave(context.Background(), &pb.Miembro{Id: "DNS1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.Leave(context.Background(), &pb.Miembro{Id: "DNS2"})
+	assert.Nil(t, err)
+	_, err = s.Leave(context.Background(), &pb.Miembro{Id: "DNS3"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, membresia.ACTIVO, estadoMiembro("DNS3"))
+
+	// La vista se persiste con cada cambio
+	guardada, err := membresia.Leer(RUTA_MEMBRESIA)
+	assert.Nil(t, err)
+	assert.Equal(t, vista.Version, guardada.Version)
+}
@@ -11,19 +11,16 @@ import (
 	pb "github.com/jfomu/DNSDistribuido/internal/proto"
 	"github.com/jfomu/DNSDistribuido/internal/config"
 	"github.com/jfomu/DNSDistribuido/internal/nodo"
+	"github.com/jfomu/DNSDistribuido/internal/nombres"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/jfomu/DNSDistribuido/internal/sesion"
+	"github.com/jfomu/DNSDistribuido/internal/validacion"
 	//"google.golang.org/grpc"
 )
 
-//// ESTRUCTURAS
-type RegistroConsulta struct {
-	Reloj []int32
-	IP string
-	Port string
-}
-
 //// VARIABLES GLOBALES
 var configuracion *config.Config
-var dominioConsulta map[string]*RegistroConsulta
+var sesionCliente *sesion.Sesion // versiones leídas de cada dominio, para las lecturas monotónicas
 
 func main() {
 
@@ -34,13 +31,13 @@ func main() {
 
 	// Inicializar variables
 	log.Printf("Inicializando variables")
-	dominioConsulta = make(map[string]*RegistroConsulta)
+	sesionCliente = sesion.NuevaSesion(sesion.LECTURAS_MONOTONICAS)
 	
 
 	// Conectando con el Broker
 	conn, err := nodo.ConectarNodo(configuracion.Broker.Ip, configuracion.Broker.Port)
 	if err != nil {
-		log.Fatalf(err.Error())
+		log.Fatalf("%v", err)
 	}
 	broker := pb.NewServicioNodoClient(conn)
 
@@ -57,53 +54,47 @@ func main() {
 		text, _ := reader.ReadString('\n')
 		text = strings.Replace(text, "\n", "", -1)
 		text = strings.ToLower(text)
-		words := strings.Split(text, " ")
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			continue
+		}
 
 
-		if strings.Compare("get", words[0]) == 0 && len(words) == 2  { // Si el comando ingresado es get
+		if strings.Compare("get", words[0]) == 0 && (len(words) == 2 || len(words) == 3) { // Si el comando ingresado es get
 			consulta := new(pb.Consulta)
 			consulta.NombreDominio = words[1]
-			//log.Println("nombreDominio: " + consulta.NombreDominio)
-			consulta.Ip = ""
-			consulta.Port = ""
-			resp, err := broker.Get(context.Background(), consulta)
+			if len(words) == 3 { // Tipo de registro consultado, por defecto A
+				consulta.Tipo = strings.ToUpper(words[2])
+			}
+			_, dominio, err := nombres.Separar(words[1], configuracion.Zonas)
 			if err != nil {
-				log.Printf("Error al llamar a Get(): %s\n", err)
+				log.Printf("[ERROR] %s\n", err)
 				continue
 			}
-			
-			
-			// Verificar la respuesta obtenida con el registro en memoria
-			if _, ok := dominioConsulta[words[0]]; !ok { // Si no existe un registro para la consulta de ese dominio
-				log.Println("Registrada consulta en memoria")
-				dominioConsulta[words[0]] = &RegistroConsulta{IP: resp.Ip, Port: resp.Port, Reloj: resp.Reloj}
-			} else{ // Si existe el registro para la consulta de ese dominio
-				//log.Println("Registro encontrado en memoria")
-				// Comparar relojes
-				//log.Printf("Reloj memoria: %+v", dominioConsulta[words[0]].Reloj)
-				//log.Printf("Reloj consulta: %+v", resp.Reloj)
-				for i, valor := range dominioConsulta[words[0]].Reloj {
-					if valor > resp.Reloj[i] {
-						// Realizar consistencia
-						//log.Println("REALIZAR CONSISTENCIA")
-						consulta.Ip = dominioConsulta[words[0]].IP
-						consulta.Port = dominioConsulta[words[0]].Port
-						resp, err = broker.Get(context.Background(), consulta)
-						if err != nil {
-							log.Printf("Error al llamar a Get(): %s\n", err)
-							continue
-						}
-						//log.Printf("IP: %s, Reloj: %v", resp.Respuesta, resp.Reloj)
-					}
-				}
+
+			// La sesión verifica la respuesta con las versiones ya leídas del dominio
+			resp, resultado, err := sesionCliente.Get(context.Background(), broker, dominio, consulta)
+			if err != nil {
+				log.Printf("Error al llamar a Get(): %s\n", validacion.Describir(err))
+				continue
 			}
+			log.Printf("Garantías: %s - Servidor: %s:%s\n", resultado, resp.Ip, resp.Port)
 
+			if len(resp.Recursos) == 0 {
+				log.Printf("%s no tiene registros del tipo consultado, Reloj: %v", words[1], resp.Reloj)
+				continue
+			}
 			log.Printf("IP: %s, Reloj: %v", resp.Respuesta, resp.Reloj)
+			for _, rr := range resp.Recursos {
+				if recurso, err := recursos.DesdeProto(rr); err == nil {
+					fmt.Println(recurso.Linea())
+				}
+			}
 			
 			
 		  
 		} else {
-			fmt.Println("Uso:\n get <nombre>.<dominio>")
+			fmt.Println("Uso:\n get <nombre>.<dominio> [<tipo>]")
 		}	
 	
 	  }
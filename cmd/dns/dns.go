@@ -2,23 +2,42 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"log"
 	"net"
 	"context"
-	"strings"
+	"encoding/json"
 	"strconv"
+	"strings"
 	"errors"
-	"bufio"
 	"time"
 	"math"
+	"math/rand"
 	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"os"
+	"os/signal"
+	"syscall"
 	
 	pb "github.com/jfomu/DNSDistribuido/internal/proto"
 	"github.com/jfomu/DNSDistribuido/internal/config"
 	"github.com/jfomu/DNSDistribuido/internal/nodo"
-	//"github.com/jfomu/DNSDistribuido/internal/registros"
+	"github.com/jfomu/DNSDistribuido/internal/cambios"
+	"github.com/jfomu/DNSDistribuido/internal/deteccion"
+	"github.com/jfomu/DNSDistribuido/internal/eleccion"
+	"github.com/jfomu/DNSDistribuido/internal/membresia"
+	"github.com/jfomu/DNSDistribuido/internal/raft"
+	"github.com/jfomu/DNSDistribuido/internal/registros"
+	"github.com/jfomu/DNSDistribuido/internal/ronda"
+	"github.com/jfomu/DNSDistribuido/internal/relojes"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/jfomu/DNSDistribuido/internal/validacion"
+	"github.com/jfomu/DNSDistribuido/internal/resolucion"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 //// ESTRUCTURAS
@@ -26,37 +45,69 @@ type Server struct{
 	nodo.Server
 }
 
-type RegistroZF struct{
-	ruta string  // ruta dentro del sistema donde se almacena el archivo de Registro ZF
-	rutaLog string // ruta dentro del sistema donde se almacena el archivo de Logs de Cambios.
-	reloj []int32
-	dominioLinea map[string]int // relaciona el nombre de dominio a la linea que ocupa dentro del archivo de registro
-	cantLineas int
+// Cambio de una zona de consistencia fuerte, replicado en el log de Raft
+type comandoZona struct{
+	Nombre string // relativo a la zona
+	Zona string
+	Operacion *cambios.Operacion
 }
 
+// Máquina de estados del grupo Raft: las zonas de consistencia fuerte de los registros del nodo
+type maquinaZonas struct{}
+
+// Envía las solicitudes de Raft por las conexiones con los demás miembros
+type transporteRaft struct{}
+
 
 const ( //// CONSTANTES
-	RUTA_REGISTROS = "registros/"
-	RUTA_LOGS = "logs/"
 	CONFIG_FILENAME = "config.json"
+	INTERVALO_COORDINACION = 5 * time.Minute
+	INTERVALO_INSTANTANEA = 30 * time.Second // cada cuanto se escriben en disco los registros ZF modificados
+	TAMANO_CHUNK = 1 * (1 << 20)
+	TIEMPO_UNION = 60 * time.Second // tiempo máximo para unirse al clúster, incluida la transferencia de las zonas
+	TIEMPO_SALIDA = 60 * time.Second // tiempo máximo para retirarse del clúster
+	INTERVALO_LATIDO = 1 * time.Second // cada cuanto se intercambian latidos con otros nodos
+	NODOS_LATIDO = 2 // nodos elegidos al azar en cada intercambio de latidos
+	TIEMPO_SOSPECHA = 5 * time.Second // sin latidos de un nodo, se considera SOSPECHOSO
+	TIEMPO_MUERTE = 15 * time.Second // sin latidos de un nodo, se considera MUERTO
+	DURACION_LIDERAZGO = 10 * time.Second // lease del coordinador de las rondas de consistencia
+	INTERVALO_RENOVACION = 3 * time.Second // cada cuanto el coordinador renueva su liderazgo
+	ESPERA_ELECCION = 2 * time.Second // espera aleatoria máxima antes de postularse como coordinador
+	TIEMPO_VOTACION = 1 * time.Second // tiempo máximo de respuesta a SolicitarVoto y RenovarLiderazgo
+	TIEMPO_RONDA = 30 * time.Second // duración máxima de una ronda de consistencia, tras la cual los participantes la resuelven
+	INTERVALO_VIGILANCIA = 1 * time.Second // cada cuanto se revisa si la ronda en curso está vencida
+	RUTA_RAFT = "raft/" // directorio del log replicado de las zonas de consistencia fuerte, dentro del cual cada nodo tiene el suyo
+	RUTA_RONDAS = "rondas/" // directorio donde cada nodo registra las rondas de consistencia confirmadas
+	MAX_REENVIOS = 2 // reenvíos de una solicitud de una zona de consistencia fuerte: hacia un miembro del grupo Raft y hacia su líder
+	CLAVE_REENVIOS = "reenvios" // metadato gRPC con los reenvíos que lleva la solicitud
 )
 
+
 var ( //// VARIABLES GLOBALES
-	configuracion = config.GenConfig(CONFIG_FILENAME)
-	dominioRegistro map[string]*RegistroZF // relaciona el nombre de dominio con su Registro ZF respectivo
-	//reg registros.Registros
+	configuracion *config.Config
+	reg *registros.Registros // registros ZF del nodo, relaciona cada dominio con su Registro ZF respectivo
 	conexionesNodos map[string]*grpc.ClientConn
 	conexionesGRPC map[string]pb.ServicioNodoClient
+	muConexiones sync.RWMutex // protege conexionesNodos y conexionesGRPC
+	vista *membresia.Vista // vista de la membresía del clúster, los demás miembros vigentes están en conexionesGRPC
+	muVista sync.Mutex
+	detector *deteccion.Detector // estado de los demás miembros según sus latidos
+	liderazgo *eleccion.Eleccion // coordinador de las rondas de consistencia
 	ticker *time.Ticker
+	retomarRonda = make(chan bool, 1) // adelanta la ronda si el coordinador anterior no la terminó
+	participante *ronda.Participante // ronda de consistencia en curso, congela las escrituras
+	grupo *raft.Nodo // replica con Raft las escrituras de las zonas de consistencia fuerte, nil si el nodo no participa
+	consultas uint64 // número de consultas Get atendidas, utilizado para rotar los registros
 	ID_DNS string
 	IP_DNS string
 	PORT_DNS string
+	PORT_CONSULTAS string // puerto UDP/TCP donde se atienden consultas DNS estándar
 )
 
 //// FUNCIONES
 func iniciarNodo(port string) {
 	// Iniciar servidor gRPC
-	log.Printf("Iniciando servidor gRPC en el puerto " + port)
+	log.Printf("Iniciando servidor gRPC en el puerto %s", port)
 	lis, err := net.Listen("tcp", ":" + port)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
@@ -69,10 +120,12 @@ func iniciarNodo(port string) {
 	log.Printf("Registrando servicios en servidor gRPC\n")
 	pb.RegisterServicioNodoServer(grpcServer, &s)
 
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %s", err)
-	}
-
+	// El puerto ya está escuchando al retornar, de modo que otros nodos pueden enviar el estado de las zonas
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("failed to serve: %s", err)
+		}
+	}()
 }
 
 func obtenerListaIPs() []string{
@@ -105,622 +158,1432 @@ func Find(slice []string, val string) (int, bool) {
     return -1, false
 }
 
-func separarNombreDominio(nombreDominio string) (string, string, error) {
-	split := strings.Split(nombreDominio, ".")
-	if len(split) == 2{
-		return split[0], split[1], nil
-	} 
-	return "", "", errors.New(nombreDominio + " no cumple el formato, debe contener solo un punto") 
-}
-
 //// FUNCIONES DEL OBJETO SERVER
 func (s *Server) ObtenerEstado(ctx context.Context, message *pb.Consulta) (*pb.Estado, error){
-
-	if message.NombreDominio != "" && message.Ip != "" && message.Port != "" { 
-		conn, err := nodo.ConectarNodo(message.Ip, message.Port)
-		if err != nil{
-			// Falla la conexión gRPC 
-			log.Printf("Error al intentar realizar conexión gRPC: %s", err)
-			return nil, err
-		} 
-		// Registrar servicio gRPC
-		c := pb.NewServicioNodoClient(conn)
-		conexionesNodos[message.NombreDominio] = conn
-		conexionesGRPC[message.NombreDominio] = c
-	}
-
 	return &pb.Estado{Estado: "OK"}, nil
 }
 
 // Comando GET
 func (s *Server) Get(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
 	// Separar nombre y el dominio en diferentes strings
-	nombre, dominio, err := separarNombreDominio(message.NombreDominio)
+	nombre, dominio, err := reg.SepararNombre(message.NombreDominio)
 	if err != nil{
-		return nil, err
+		return nil, validacion.ErrorGRPC(err)
 	}
 
-	// Remover linea de registro ZF
-	if registro, ok := dominioRegistro[dominio]; ok { // Verificar si se encuentra el dominio en nuestro registro ZF
-		if _, ok := registro.dominioLinea[nombre]; ok { // Verificar si se encuentra la linea donde está el nombre
-			
-			// Abrir el archivo de registro ZF para leer y almacenar en memoria las lineas
-			var readFile, err = os.OpenFile(dominioRegistro[dominio].ruta, os.O_RDWR, 0644)
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
-			
-			fileScanner := bufio.NewScanner(readFile)
-			fileScanner.Split(bufio.ScanLines)
-			
-			var fileTextLines []string
-			for fileScanner.Scan() {
-				fileTextLines = append(fileTextLines, fileScanner.Text())
-			}
-			readFile.Close() // Cerramos el archivo
-
-			linea := fileTextLines[registro.dominioLinea[nombre] - 1]
+	// Por defecto se consultan los registros A, ANY consulta todos los registros del nombre
+	tipo := strings.ToUpper(message.Tipo)
+	if tipo == "" {
+		tipo = recursos.A
+	} else if tipo == "ANY" {
+		tipo = ""
+	}
+	if tipo != "" && !recursos.TipoValido(tipo) {
+		return nil, validacion.ErrorGRPC(validacion.Nuevo("tipo", "Tipo de registro no soportado: " + tipo))
+	}
 
-			// Verificar que la linea a leer no se encuentre vacía
-			if linea == "" {
-				log.Println("[ERROR] La linea del registro ZF asociada al nombre " + nombre + " está vacía")
-				return nil, errors.New("La linea del registro ZF asociada al nombre " + nombre + " está vacía")
-			}
+	// Un nodo fuera del grupo Raft no tiene las zonas de consistencia fuerte y reenvía la consulta
+	if configuracion.Fuerte(dominio) && grupo == nil {
+		dns, ctx, err := destinoFuerte(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dns.Get(ctx, message)
+	}
 
-			// Verificar contenido dentro de la linea a actualizar
-			lineaDividida := strings.Split(linea, " IN A ")
-			if len(lineaDividida) != 2 || lineaDividida[0] == "" || lineaDividida[1] == ""{
-				log.Println("[ERROR] Datos corruptos en el registro ZF: " + linea)
-				return nil, errors.New("Datos corruptos en el registro ZF: " + linea)
-			}
+	// Un dominio que este nodo no conoce (FailedPrecondition) se distingue de un nombre
+	// inexistente (NotFound), de modo que puedan traducirse a REFUSED y NXDOMAIN
+	if !reg.ExisteRegistroMemoria(dominio) {
+		return nil, status.Error(codes.FailedPrecondition, "No se encuentra el dominio registrado: " + dominio)
+	}
+	conjunto, reloj, err := reg.Obtener(nombre, dominio, tipo)
+	if err != nil {
+		log.Printf("[ERROR] %s\n", err)
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 
-			// Generamos y retornamos la respuesta a la consulta
-			respuesta := new(pb.Respuesta)
-			respuesta.Respuesta = lineaDividida[1]
-			respuesta.Ip = IP_DNS
-			respuesta.Port = PORT_DNS
-			respuesta.Reloj = registro.reloj
-			return respuesta, nil
+	// Rotar los registros en cada consulta para repartir la carga entre direcciones (round-robin)
+	if len(conjunto) > 0 {
+		inicio := int(atomic.AddUint64(&consultas, 1) % uint64(len(conjunto)))
+		conjunto = append(conjunto[inicio:], conjunto[:inicio]...)
+	}
 
-		
-		} else{ // Si no se encuentra la linea donde se encuentra el nombre dentro del registro ZF
-			log.Printf("No es posible encontrar en el registro ZF la linea del nombre: " + nombre)
-			return nil, errors.New("No es posible encontrar en el registro ZF la linea del nombre: " + nombre)
-		}
-	} else { //Si no se encuentra el dominio registrado
-		log.Printf("No se encuentra el dominio registrado: " + dominio)
-		return nil, errors.New("No se encuentra el dominio registrado: " + dominio)
+	// Generamos y retornamos la respuesta a la consulta, sin registros si el nombre no tiene del tipo consultado
+	respuesta := new(pb.Respuesta)
+	if len(conjunto) > 0 {
+		respuesta.Respuesta = conjunto[0].Datos()
 	}
+	for _, rr := range conjunto {
+		respuesta.Recursos = append(respuesta.Recursos, rr.Proto())
+	}
+	respuesta.Ip = IP_DNS
+	respuesta.Port = PORT_DNS
+	respuesta.Reloj = reloj
+	return respuesta, nil
 }
 
 // Comando CREATE
 func (s *Server) Create(ctx context.Context, message *pb.Consulta) (*pb.Respuesta, error){
-	
 	// Separar nombre y el dominio en diferentes strings
-	nombre, dominio, err := separarNombreDominio(message.NombreDominio)
+	nombre, dominio, err := reg.SepararNombre(message.NombreDominio)
 	if err != nil{
-		return nil, err
+		return nil, validacion.ErrorGRPC(err)
 	}
 
-	salto := "\n"
-
-	// Agregar información a registro ZF
-	if _, ok := dominioRegistro[dominio]; !ok {  // Si no existe un registro ZF asociado al dominio
-
-		// Verificar que existan las carpetas donde se almacenan los archivos de registro y log
-		_, err1 := os.Stat(RUTA_REGISTROS)
-		_, err2 := os.Stat(RUTA_LOGS)
-		if os.IsNotExist(err1) || os.IsNotExist(err2) { 
-			log.Printf("Creando directorios: %s | %s", RUTA_REGISTROS, RUTA_LOGS)
-			if err = os.Mkdir(RUTA_REGISTROS, 0777); err != nil {
-				return nil, err
-			}
-			//os.ModeDir
-			if err =os.Mkdir(RUTA_LOGS, 0777); err != nil {
-				return nil, err
-			}
-		} 
-
-		rutaRegistros :=  RUTA_REGISTROS + ID_DNS + "/"
-		rutaLogs := RUTA_LOGS + ID_DNS + "/"
-
-		// Verificar que existan los directorios asociados al nodo
-		_, err1 = os.Stat(rutaRegistros)
-		_, err2 = os.Stat(rutaLogs)
-		if os.IsNotExist(err1) || os.IsNotExist(err2) { // Si alguno de los archivos ya existe
-			log.Printf("Creando directorios: %s | %s", rutaRegistros, rutaLogs)
-			if err = os.Mkdir(rutaRegistros, 0777); err != nil {
-				return nil, err
-			}
-			if err =os.Mkdir(rutaLogs, 0777); err != nil {
-				return nil, err
-			}
+	// Si no se indica el registro se agrega un registro de dirección con la IP
+	var rr *recursos.Recurso
+	if message.Recurso != nil {
+		if rr, err = recursos.DesdeProto(message.Recurso); err != nil {
+			return nil, validacion.ErrorGRPC(err)
 		}
-
-		// Verificar que no existan los archivos asociados al registro
-		_, err1 = os.Stat(rutaRegistros + dominio)
-	 	_, err2 = os.Stat(rutaLogs + dominio + ".log")
-		if !os.IsNotExist(err1) || !os.IsNotExist(err2) { // Si alguno de los archivos ya existe
-			log.Println("Se han encotrado los archivos asociados al registro pero el registro no se encuentra en memoria.")
-			return nil, errors.New("Se han encotrado los archivos asociados al registro pero el registro no se encuentra en memoria.")
-		} 
-
-		// Iniciar nuevo registro ZF en memoria
-		dominioRegistro[dominio] = new(RegistroZF)
-		
-		// Asociar las rutas correspondientes al registro ZF
-		dominioRegistro[dominio].ruta = rutaRegistros + dominio
-		dominioRegistro[dominio].rutaLog = rutaLogs + dominio + ".log"
-
-		// Inicializar variables del registro ZF
-		dominioRegistro[dominio].reloj = []int32{0, 0, 0}
-		dominioRegistro[dominio].dominioLinea = make(map[string]int)
-		dominioRegistro[dominio].cantLineas = 0
-
-		salto = ""
-
-		log.Println("Se ha inicializado un nuevo registro ZF en memoria")
-	}
-
-	// Verificar que la linea del registro no exista
-	if _, ok := dominioRegistro[dominio].dominioLinea[nombre]; ok {  // Si no existe una linea en el registro ZF asociada al nombre
-		log.Println("Se ha intentando registrar un nombre de dominio ya existente")
-		return nil, errors.New("El registro que se intenta agregar ya existe en este servidor")
-	}
-
-	// Agregar información a archivo de registro ZF
-	regFile, err := os.OpenFile(dominioRegistro[dominio].ruta,
-	os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	defer regFile.Close()
-	if _, err := regFile.WriteString(salto + nombre + "." + dominio + " IN A " + message.Ip); err != nil {
-		log.Println(err)
-		return nil, err
+	} else {
+		rr = &recursos.Recurso{Tipo: recursos.TipoDireccion(message.Ip), Valor: message.Ip}
 	}
-	dominioRegistro[dominio].cantLineas += 1
-	log.Println("Información agregada al archivo del registro ZF")
-
 
-	// Agregar información a Log de cambios
-	logFile, err := os.OpenFile(dominioRegistro[dominio].rutaLog,
-	os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	defer logFile.Close()
-	if _, err := logFile.WriteString(salto + "create " + nombre + "." + dominio + " " + message.Ip); err != nil {
-		log.Println(err)
-		return nil, err
+	// En una zona de consistencia fuerte el cambio lo aplica el líder del grupo Raft
+	if configuracion.Fuerte(dominio) && (grupo == nil || !grupo.EsLider()) {
+		dns, ctx, err := destinoFuerte(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dns.Create(ctx, message)
 	}
-	log.Println("Información agregada al Log de cambios")
 
-	//Actualizar reloj de vector
-	id, err := strconv.Atoi(string(ID_DNS[3]))
+	reloj, err := aplicarCambio(ctx, nombre, dominio, &cambios.Operacion{Tipo: cambios.CREATE, Recurso: rr})
 	if err != nil {
-		log.Println(err)
+		log.Printf("[ERROR] %s\n", err)
 		return nil, err
 	}
-	dominioRegistro[dominio].reloj[id - 1] += 1
-	
-	// Actualizar map de nombre a la linea en que se encuentra
-	dominioRegistro[dominio].dominioLinea[nombre] = dominioRegistro[dominio].cantLineas
+	log.Printf("Create %s - Reloj: %+v\n", message.NombreDominio, reloj)
 
 	//Generar respuesta y retornarla
 	respuesta := new(pb.Respuesta) 
-	respuesta.Reloj = dominioRegistro[dominio].reloj
+	respuesta.Reloj = reloj
 	respuesta.Ip = IP_DNS
 	respuesta.Port = PORT_DNS
-
 	return respuesta, nil
 }
 
 // Comando DELETE
 func (s *Server) Delete(ctx context.Context, message *pb.ConsultaAdmin) (*pb.RespuestaAdmin, error){
 	// Separar nombre y el dominio en diferentes strings
-	nombre, dominio, err := separarNombreDominio(message.NombreDominio)
+	nombre, dominio, err := reg.SepararNombre(message.NombreDominio)
 	if err != nil{
-		return nil, err
+		return nil, validacion.ErrorGRPC(err)
 	}
 
-	// Remover linea de registro ZF
-	if registro, ok := dominioRegistro[dominio]; ok { // Verificar si se encuentra el dominio en nuestro registro ZF
-		if _, ok := registro.dominioLinea[nombre]; ok { // Verificar si se encuentra la linea donde está el nombre
-			
-			// Abrir el archivo de registro ZF para leer y almacenar en memoria las lineas
-			var readFile, err = os.OpenFile(dominioRegistro[dominio].ruta, os.O_RDWR, 0644)
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
-			
-			fileScanner := bufio.NewScanner(readFile)
-			fileScanner.Split(bufio.ScanLines)
-			
-			var fileTextLines []string
-			for fileScanner.Scan() {
-				fileTextLines = append(fileTextLines, fileScanner.Text())
-			}
-		
-			readFile.Close() // Cerramos el archivo
-
-			// Verificar que la linea a borrar no se encuentre vacía
-			lineaBorrar := dominioRegistro[dominio].dominioLinea[nombre] - 1
-			if fileTextLines[lineaBorrar] == "" {
-				log.Println("[ERROR] La linea del registro ZF asociada al nombre " + nombre + " ya está vacía")
-				return nil, errors.New("La linea del registro ZF asociada al nombre " + nombre + " ya está vacía")
-			}
-
-			// Verificar consistencia del tamaño de las lineas leidas y las lineas del registro zf
-			diferencia := dominioRegistro[dominio].cantLineas - len(fileTextLines)
-			if diferencia != 0 {
-				for i := 0; i < diferencia; i++ {
-					fileTextLines = append(fileTextLines, "")
-				}
-			}
-
-			// Crear un nuevo archivo en blanco para el registro ZF
-			file1, err := os.Create(dominioRegistro[dominio].ruta)
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
-			defer file1.Close()
-
-			fileTextLines[lineaBorrar] = ""
-
-			_, err = file1.WriteString(strings.Join(fileTextLines, "\n"))
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
-
-		
-		} else{ // Si no se encuentra la linea donde se encuentra el nombre dentro del registro ZF
-			log.Printf("No es posible encontrar en el registro ZF la linea del nombre: " + nombre)
-			return nil, errors.New("No es posible encontrar en el registro ZF la linea del nombre: " + nombre)
+	// Seleccionar los registros a eliminar, por defecto todos los del nombre
+	var tipo, datos string
+	if message.Recurso != nil {
+		rr, err := recursos.DesdeProto(message.Recurso)
+		if err != nil {
+			return nil, validacion.ErrorGRPC(err)
+		}
+		tipo = rr.Tipo
+		if rr.Valor != "" {
+			datos = rr.Datos()
 		}
-	} else { //Si no se encuentra el dominio registrado
-		log.Printf("No se encuentra el dominio registrado: " + dominio)
-		return nil, errors.New("No se encuentra el dominio registrado: " + dominio)
 	}
 
-	// Agregar información a Log de cambios
-	logFile, err := os.OpenFile(dominioRegistro[dominio].rutaLog,
-	os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	defer logFile.Close()
-	if _, err := logFile.WriteString("\n" + "delete " + nombre + "." + dominio); err != nil {
-		log.Println(err)
-		return nil, err
+	if configuracion.Fuerte(dominio) && (grupo == nil || !grupo.EsLider()) {
+		dns, ctx, err := destinoFuerte(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dns.Delete(ctx, message)
 	}
-	log.Println("Información agregada al Log de cambios")
 
-	// Actualizar reloj de vector
-	id, err := strconv.Atoi(string(ID_DNS[3]))
+	reloj, err := aplicarCambio(ctx, nombre, dominio, &cambios.Operacion{Tipo: cambios.DELETE, Opcion: tipo, Valor: datos})
 	if err != nil {
-		log.Println(err)
+		log.Printf("[ERROR] %s\n", err)
 		return nil, err
 	}
-	dominioRegistro[dominio].reloj[id - 1] += 1
-	log.Println("Reloj actualizado")
-
-	// Remover mapeo de nombre a la linea en que se encuentra
-	delete(dominioRegistro[dominio].dominioLinea, nombre)
+	log.Printf("Delete %s - Reloj: %+v\n", message.NombreDominio, reloj)
 
 	// Generar respuesta y retornarla
 	respuesta := new(pb.RespuestaAdmin)
-	respuesta.Reloj = dominioRegistro[dominio].reloj 
+	respuesta.Reloj = reloj
+	respuesta.Ip = IP_DNS
+	respuesta.Port = PORT_DNS
 	return respuesta, nil
 }
 
 // Comando UPDATE
 func (s *Server) Update(ctx context.Context, message *pb.ConsultaUpdate) (*pb.RespuestaAdmin, error){
 	// Separar nombre y el dominio en diferentes strings
-	nombre, dominio, err := separarNombreDominio(message.NombreDominio)
+	nombre, dominio, err := reg.SepararNombre(message.NombreDominio)
 	if err != nil{
+		return nil, validacion.ErrorGRPC(err)
+	}
+
+	if configuracion.Fuerte(dominio) && (grupo == nil || !grupo.EsLider()) {
+		dns, ctx, err := destinoFuerte(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dns.Update(ctx, message)
+	}
+
+	reloj, err := aplicarCambio(ctx, nombre, dominio, &cambios.Operacion{Tipo: cambios.UPDATE, Opcion: message.Opcion, Valor: message.Param})
+	if err != nil {
+		log.Printf("[ERROR] %s\n", err)
 		return nil, err
 	}
+	log.Printf("Update %s - Reloj: %+v\n", message.NombreDominio, reloj)
 
-	// Actualizar linea de registro ZF
-	if registro, ok := dominioRegistro[dominio]; ok { // Verificar si se encuentra el dominio en nuestro registro ZF
-		if _, ok := registro.dominioLinea[nombre]; ok { // Verificar si se encuentra la linea donde está el nombre
-			
-			// Abrir el archivo de registro ZF para leer y almacenar en memoria las lineas
-			var readFile, err = os.OpenFile(dominioRegistro[dominio].ruta, os.O_RDWR, 0644)
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
-			
-			fileScanner := bufio.NewScanner(readFile)
-			fileScanner.Split(bufio.ScanLines)
-			
-			var fileTextLines []string
-			for fileScanner.Scan() {
-				fileTextLines = append(fileTextLines, fileScanner.Text())
-			}
-		
-			readFile.Close() // Cerramos el archivo
-
-			// Verificar que la linea a actualizar no se encuentre vacía
-			lineaActualizar := dominioRegistro[dominio].dominioLinea[nombre] - 1
-			if fileTextLines[lineaActualizar] == "" {
-				log.Println("[ERROR] La linea del registro ZF asociada al nombre " + nombre + " está vacía")
-				return nil, errors.New("La linea del registro ZF asociada al nombre " + nombre + " está vacía")
-			}
+	// Generar respuesta y retornarla
+	respuesta := new(pb.RespuestaAdmin)
+	respuesta.Reloj = reloj
+	respuesta.Ip = IP_DNS
+	respuesta.Port = PORT_DNS
+	return respuesta, nil
+}
 
-			// Verificar contenido dentro de la linea a actualizar
-			lineaVieja := strings.Split(fileTextLines[lineaActualizar], " IN A ")
-			if len(lineaVieja) != 2 || lineaVieja[0] == "" || lineaVieja[1] == ""{
-				log.Println("[ERROR] Datos corruptos en el registro ZF: " + fileTextLines[lineaActualizar])
-				return nil, errors.New("Datos corruptos en el registro ZF: " + fileTextLines[lineaActualizar])
-			}
 
-			ip := lineaVieja[1]
-			nombreOriginal := nombre
-			
-			// Actualizar los valores requeridos
-			var cambio string
-			if message.Opcion == "ip" {
-				ip = message.Param
-				cambio = ip
-			} else if message.Opcion == "name" {
-				nombre = message.Param
-				cambio = nombre + "." + dominio
-			}
-			
-			// Generar la nueva linea que se insertará en el registro ZF e insertarla
-			lineaNueva := fmt.Sprintf("%s.%s IN A %s", nombre, dominio, ip)
-			fmt.Println(lineaNueva)
-			fileTextLines[lineaActualizar] = lineaNueva
-
-			// Crear un nuevo archivo en blanco para el registro ZF
-			file1, err := os.Create(dominioRegistro[dominio].ruta)
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
-			defer file1.Close()
+func (s *Server) GetFile(message *pb.Consulta, srv pb.ServicioNodo_GetFileServer) error{
+	// Verificar que se recibio un dominio
+	if message.NombreDominio == "" {
+		return errors.New("No se ha especificado el dominio en la consulta")
+	}
 
-			// Escribir en el archivo las nuevas lineas
-			_, err = file1.WriteString(strings.Join(fileTextLines, "\n"))
-			if err != nil {
-				log.Println(err)
-				return nil, err
-			}
+	// Obtener una copia consistente del estado del dominio
+	estado, err := reg.Estado(message.NombreDominio)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
 
-			// Agregar información a Log de cambios
-			logFile, err := os.OpenFile(dominioRegistro[dominio].rutaLog,
-			os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-			if err != nil {
+	// Enviar el registro ZF y luego el log de cambios, ambos acompañados del reloj del dominio
+	if err := enviarEstado(srv.Send, message.NombreDominio, estado, ""); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (s *Server) SetFile(stream pb.ServicioNodo_SetFileServer) error{
+	// Recibir las piezas agrupando el contenido por dominio y tipo de archivo
+	contenidoRegistro := make(map[string][]byte)
+	contenidoLog := make(map[string][]byte)
+	relojesRecibidos := make(map[string][]int32)
+	var origen, idRonda string
+	var dominios []string
+	for {
+		pieza, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+
+		dominio, esLog, err := interpretarFileInfo(pieza.FileInfo)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if _, ok := relojesRecibidos[dominio]; !ok {
+			dominios = append(dominios, dominio)
+		}
+		relojesRecibidos[dominio] = pieza.Reloj
+		origen = pieza.IdNodo
+		idRonda = pieza.Ronda
+
+		if esLog {
+			contenidoLog[dominio] = append(contenidoLog[dominio], pieza.ChunkData...)
+		} else {
+			contenidoRegistro[dominio] = append(contenidoRegistro[dominio], pieza.ChunkData...)
+		}
+	}
+
+	if origen == "" || origen == ID_DNS { // Remitente sin identificar
+		origen = "remitente"
+	}
+
+	// En una ronda el estado fusionado solo se aplica al confirmarse
+	if idRonda != "" {
+		for _, dominio := range dominios {
+			estado := &registros.Estado{IdNodo: origen, Reloj: relojesRecibidos[dominio]}
+			estado.Registro = registros.DividirLineas(string(contenidoRegistro[dominio]))
+			estado.Log = registros.DividirLineas(string(contenidoLog[dominio]))
+			if err := participante.Recibir(idRonda, dominio, estado); err != nil {
 				log.Println(err)
-				return nil, err
+				return status.Error(codes.FailedPrecondition, err.Error())
 			}
-			defer logFile.Close()
-			if _, err := logFile.WriteString("\n" + "update " + nombreOriginal + "." + dominio + " " + cambio); err != nil {
+		}
+		return stream.SendAndClose(&pb.Estado{Estado: participante.Estado(idRonda)})
+	}
+
+	// El estado recibido fuera de una ronda, por ejemplo al unirse un nodo, es una escritura más
+	terminar, err := participante.IniciarEscritura(stream.Context())
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer terminar()
+
+	for _, dominio := range dominios {
+		// Las zonas de consistencia fuerte solo cambian mediante el log replicado
+		if configuracion.Fuerte(dominio) {
+			log.Printf("Se ignora el estado recibido de la zona de consistencia fuerte %s\n", dominio)
+			continue
+		}
+
+		// Los archivos que no se recibieron se mantienen como están en este nodo
+		var local *registros.Estado
+		estado := &registros.Estado{IdNodo: origen, Registro: []string{}, Log: []string{}}
+		if reg.ExisteRegistroMemoria(dominio) {
+			var err error
+			if local, err = reg.Estado(dominio); err != nil {
 				log.Println(err)
-				return nil, err
+				return err
 			}
-			log.Println("Información agregada al Log de cambios")
-		
-			// Actualizar reloj de vector
-			id, err := strconv.Atoi(string(ID_DNS[3]))
+			estado.Registro, estado.Log = local.Registro, local.Log
+		}
+		if contenido, ok := contenidoRegistro[dominio]; ok {
+			estado.Registro = registros.DividirLineas(string(contenido))
+		}
+		if contenido, ok := contenidoLog[dominio]; ok {
+			estado.Log = registros.DividirLineas(string(contenido))
+		}
+		estado.Reloj = relojesRecibidos[dominio]
+
+		// Si el estado recibido no incluye todos los cambios locales, por ejemplo una escritura
+		// recibida durante la ronda de coordinación o el estado de un nodo que se retira, ambos
+		// se fusionan en lugar de reemplazar el local
+		if local != nil && !relojes.Reloj(estado.Reloj).Incluye(local.Reloj) {
+			fusion, err := fusionarEstados([]*registros.Estado{local, estado})
 			if err != nil {
 				log.Println(err)
-				return nil, err
+				return err
 			}
-			dominioRegistro[dominio].reloj[id - 1] += 1
-			log.Println("Reloj actualizado")
-		
-			// Remover mapeo de nombre a la linea en que se encuentra
-			delete(dominioRegistro[dominio].dominioLinea, nombre)
-			dominioRegistro[dominio].dominioLinea[nombre] = lineaActualizar + 1
-		
-			// Generar respuesta y retornarla
-			respuesta := new(pb.RespuestaAdmin)
-			respuesta.Reloj = dominioRegistro[dominio].reloj 
-			return respuesta, nil
+			estado = fusion
+		}
 
-		} else{ // Si no se encuentra la linea donde se encuentra el nombre dentro del registro ZF
-			log.Printf("[ERROR] No es posible encontrar en el registro ZF la linea del nombre: " + nombre)
-			return nil, errors.New("No es posible encontrar en el registro ZF la linea del nombre: " + nombre)
+		if err := reg.Aplicar(dominio, estado); err != nil {
+			log.Println(err)
+			return err
 		}
-	} else { //Si no se encuentra el dominio registrado
-		log.Printf("[ERROR] No se encuentra el dominio registrado: " + dominio)
-		return nil, errors.New("No se encuentra el dominio registrado: " + dominio)
+		log.Printf("Estado del dominio %s recibido - Reloj: %+v\n", dominio, estado.Reloj)
 	}
+
+	return stream.SendAndClose(&pb.Estado{Estado: "OK"})
 }
 
+func (s *Server) GetDominios(ctx context.Context, message *pb.Vacio) (*pb.Dominios, error){
+	return &pb.Dominios{Dominios: reg.Dominios()}, nil
+}
 
-func (s *Server) GetFile(message *pb.Consulta, srv pb.ServicioNodo_GetFileServer) error{
-	// Verificar que se recibio un dominio
-	if message.NombreDominio == "" {
-		return errors.New("No se ha especificado el dominio en la consulta")
+// Adopta la vista de la membresía recibida si es más reciente que la conocida, y retorna la vista actual
+func (s *Server) ActualizarVista(ctx context.Context, message *pb.Vista) (*pb.Vista, error){
+	if message.Version > 0 {
+		aplicarVista(membresia.DesdeProto(message))
 	}
-	
-	//Abrir archivo correspondiente
-	file, err := os.Open(dominioRegistro[message.NombreDominio].ruta)
+	muVista.Lock()
+	defer muVista.Unlock()
+	return vista.Proto(), nil
+}
+
+// Envía el estado de todos los dominios del nodo al miembro indicado, que lo fusiona con el suyo.
+// Se utiliza para entregar las zonas a un nodo que se une y al retirarse este nodo.
+func (s *Server) TransferirEstado(ctx context.Context, message *pb.Miembro) (*pb.Estado, error){
+	conn, err := nodo.ConectarNodo(message.Ip, message.Port)
 	if err != nil {
-		log.Println(err)
-		return err
+		return nil, status.Error(codes.Unavailable, err.Error())
 	}
-	defer file.Close()
+	defer conn.Close()
+	destino := pb.NewServicioNodoClient(conn)
+
+	// Las zonas de consistencia fuerte no se transfieren, sus miembros las obtienen del log replicado
+	var dominios []string
+	for _, dominio := range reg.Dominios() {
+		if !configuracion.Fuerte(dominio) {
+			dominios = append(dominios, dominio)
+		}
+	}
+	for _, dominio := range dominios {
+		estado, err := reg.Estado(dominio)
+		if err != nil {
+			log.Printf("Error al leer el estado de %s: %s\n", dominio, err)
+			return nil, err
+		}
+		if err := propagarEstado(destino, dominio, estado, ""); err != nil {
+			log.Printf("Error al transferir %s a %s: %s\n", dominio, message.Id, err)
+			return nil, err
+		}
+	}
+	log.Printf("Estado de %d dominios transferido a %s\n", len(dominios), message.Id)
+	return &pb.Estado{Estado: "OK"}, nil
+}
 
-	fileInfo, _ := file.Stat()
-	var fileSize int64 = fileInfo.Size()
-	const fileChunk = 1 * (1 << 20) 
-	totalPartsNum := uint64(math.Ceil(float64(fileSize) / float64(fileChunk)))
-	
-	log.Printf("Registro dividido en %d piezas.\n", totalPartsNum)
+// Incorpora los latidos de otro nodo y responde con los conocidos por este (gossip push-pull)
+func (s *Server) IntercambiarLatidos(ctx context.Context, message *pb.Latidos) (*pb.Latidos, error){
+	registrarCambios(detector.Fusionar(message))
+	return detector.Latidos(), nil
+}
 
-	resp := new(pb.File)
-	resp.FileInfo = dominioRegistro[message.NombreDominio].ruta
-	for i := uint64(0); i < totalPartsNum; i++ {
+// Entrega el estado de los servidores DNS según la detección de fallas de este nodo
+func (s *Server) ObtenerSalud(ctx context.Context, message *pb.Vacio) (*pb.Latidos, error){
+	salud := detector.Latidos()
+	salud.Lider, salud.Termino, _ = liderazgo.Lider()
+	if grupo != nil {
+		salud.LiderRaft, salud.TerminoRaft = grupo.Lider()
+	}
+	return salud, nil
+}
 
-		partSize := int(math.Min(fileChunk, float64(fileSize-int64(i*fileChunk))))
-		partBuffer := make([]byte, partSize)
+func (s *Server) SolicitarVoto(ctx context.Context, message *pb.SolicitudVoto) (*pb.Voto, error){
+	concedido, termino := liderazgo.Votar(message.Termino, message.Candidato)
+	return &pb.Voto{Termino: termino, Concedido: concedido}, nil
+}
 
-		file.Read(partBuffer)
-		resp.ChunkData = partBuffer
-		srv.Send(resp)	
+func (s *Server) RenovarLiderazgo(ctx context.Context, message *pb.Liderazgo) (*pb.Voto, error){
+	anterior, _, _ := liderazgo.Lider()
+	aceptado, termino := liderazgo.Aceptar(message.Termino, message.Lider, message.RondaEnCurso)
+	if aceptado && anterior != message.Lider {
+		log.Printf("Coordinador de las rondas de consistencia: %s (término %d)\n", message.Lider, message.Termino)
 	}
-	return nil
+	return &pb.Voto{Termino: termino, Concedido: aceptado}, nil
 }
 
-func (s *Server) SetFile(stream pb.ServicioNodo_SetFileServer) error{
-	return errors.New("Función SetFile() no implementada para este nodo.")
+// Fases de una ronda de consistencia enviadas por su coordinador, o consulta de su resultado
+func (s *Server) CoordinarRonda(ctx context.Context, message *pb.Ronda) (*pb.EstadoRonda, error){
+	switch message.Fase {
+	case ronda.CONGELAR:
+		if lider, _, ok := liderazgo.Lider(); !ok || lider != message.Coordinador {
+			return nil, status.Error(codes.FailedPrecondition, message.Coordinador + " no es el coordinador vigente")
+		}
+		// Una ronda anterior sin terminar corresponde a un coordinador que cayó
+		if anterior := participante.EnCurso(); anterior != nil && anterior.Id != message.Id {
+			resolverRonda(anterior)
+		}
+		if err := participante.Congelar(message.Id, message.Coordinador); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	case ronda.PREPARAR:
+		if err := participante.Preparar(message.Id, message.Participantes); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	case ronda.CONFIRMAR:
+		if err := confirmarRonda(message.Id); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	case ronda.ABORTAR:
+		if err := participante.Abortar(message.Id); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	case ronda.CONSULTAR:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Fase desconocida: " + message.Fase)
+	}
+	return &pb.EstadoRonda{Id: message.Id, Estado: participante.Estado(message.Id)}, nil
 }
 
-func (s *Server) GetDominios(ctx context.Context, message *pb.Vacio) (*pb.Dominios, error){
-	ticker.Stop()
-	dominios := make([]string, 0, len(dominioRegistro))
-	for d := range dominioRegistro {
-        dominios = append(dominios, d)
+func (s *Server) SolicitarVotoRaft(ctx context.Context, message *pb.SolicitudVotoRaft) (*pb.Voto, error){
+	if grupo == nil {
+		return nil, status.Error(codes.FailedPrecondition, "El nodo " + ID_DNS + " no es parte del grupo Raft")
 	}
-	return &pb.Dominios{Dominios: dominios}, nil
+	return grupo.SolicitarVoto(message)
 }
 
+func (s *Server) AgregarEntradas(ctx context.Context, message *pb.EntradasRaft) (*pb.ResultadoRaft, error){
+	if grupo == nil {
+		return nil, status.Error(codes.FailedPrecondition, "El nodo " + ID_DNS + " no es parte del grupo Raft")
+	}
+	return grupo.AgregarEntradas(message)
+}
 
-func main() {
-	log.Printf("= INICIANDO DNS SERVER =")
+func (s *Server) InstalarSnapshot(ctx context.Context, message *pb.SnapshotRaft) (*pb.ResultadoRaft, error){
+	if grupo == nil {
+		return nil, status.Error(codes.FailedPrecondition, "El nodo " + ID_DNS + " no es parte del grupo Raft")
+	}
+	return grupo.InstalarSnapshot(message)
+}
 
-	// Cargar archivo de configuración
-	// configuracion = config.GenConfig(CONFIG_FILENAME)
 
-	//reg.Init(ID_DNS)
+//// CONSULTAS DNS ESTÁNDAR
 
-	// Inicializar variables
-	log.Printf("Inicializando variables")
-	dominioRegistro = make(map[string]*RegistroZF)
-	ID_DNS = ""
-	IP_DNS = ""
-	PORT_DNS = ""
+// Resuelve una consulta DNS con las zonas de este nodo, del cual es autoritativo. Los nombres
+// fuera de sus zonas se rechazan (REFUSED) y los CNAME se siguen dentro de las zonas del nodo.
+func resolverLocal(nombre string, tipo string) *resolucion.Respuesta {
+	nombreRelativo, zona, ok := reg.BuscarZona(nombre)
+	if !ok {
+		return &resolucion.Respuesta{Codigo: resolucion.REFUSED}
+	}
+	respuesta := &resolucion.Respuesta{Codigo: resolucion.NOERROR, Autoritativa: true}
+	conjunto, _, err := reg.Obtener(nombreRelativo, zona, tipo)
+	if err != nil { // El nombre no existe en la zona
+		respuesta.Codigo = resolucion.NXDOMAIN
+		return respuesta
+	}
+	respuesta.Recursos = conjunto
+
+	// Seguir la cadena de alias, con un límite para evitar ciclos
+	for i := 0; i < 8 && tipo != recursos.CNAME && len(conjunto) == 1 && conjunto[0].Tipo == recursos.CNAME; i++ {
+		nombreRelativo, zona, ok = reg.BuscarZona(conjunto[0].Valor)
+		if !ok {
+			break
+		}
+		if conjunto, _, err = reg.Obtener(nombreRelativo, zona, tipo); err != nil {
+			break
+		}
+		respuesta.Recursos = append(respuesta.Recursos, conjunto...)
+	}
+	return respuesta
+}
 
 
-	// Iniciar variables que mantenga las conexiones establecidas entre nodos
-	conexionesNodos = make(map[string]*grpc.ClientConn)
-	conexionesGRPC = make(map[string]pb.ServicioNodoClient)
+//// FUNCIONES DE CONSISTENCIA
 
-	// Identificar el servidor DNS correspondiente a la IP de la máquina
-	machineIPs := obtenerListaIPs() // Obtener lista de IPs asociadas a la máquina
-	var id string
-	var ip string
-	var port string
-	for _, dns := range configuracion.DNS{ // Iterar sobre las IP configuradas para servidores DNS
-		id = dns.Id
-		ip = dns.Ip
-		port = dns.Port
-
-		// Intentar establecer conexión con el nodo DNS
-		conn, err := nodo.ConectarNodo(ip, port)
-		if err != nil{ // Si falla la conexión con el nodo 
-			log.Printf("Error al intentar conectar al nodo %s | %s:%s | %s\n", id, ip, port, err)
-			continue
+// Obtiene mediante GetFile el estado que otro nodo tiene de un dominio
+func obtenerEstadoRemoto(id string, dns pb.ServicioNodoClient, dominio string) (*registros.Estado, error) {
+	stream, err := dns.GetFile(context.Background(), &pb.Consulta{NombreDominio: dominio})
+	if err != nil {
+		return nil, err
+	}
+
+	var contenidoRegistro []byte
+	var contenidoLog []byte
+	estado := new(registros.Estado)
+	estado.IdNodo = id
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
 		}
 
-		c := pb.NewServicioNodoClient(conn)
-		_, err = c.ObtenerEstado(context.Background(), new(pb.Consulta))
-		if err != nil { // Si el servidor no responde a una consulta gRPC
-			//log.Printf("Nodo %s con servidor DNS INACTIVO\n", id )
-
-			// Verificar si el nodo corresponde al asignado
-			_, found := Find(machineIPs, dns.Ip)
-			if found { // Si corresponde se asignan las variables para identificar el nodo
-				ID_DNS = id
-				IP_DNS = ip
-				PORT_DNS = port
-
-				// Presentarse a los otros nodos
-				infoNodo := &pb.Consulta{NombreDominio: ID_DNS, Ip: IP_DNS, Port: PORT_DNS}
-				for _, c := range conexionesGRPC{
-					c.ObtenerEstado(context.Background(), infoNodo)
-				}
-
-				go iniciarNodo(PORT_DNS)
-
-				//log.Println("Iniciando Timer")
-				ticker = time.NewTicker(5 * time.Minute)
-				quit := make(chan struct{})
-				
-				for {
-				select {
-					case <- ticker.C:
-						log.Println("Coordinando servidores DNS")
-						ticker.Stop()
-						for _, dns := range conexionesGRPC{
-							// Obtener dominios registrados en el servidor dns
-							respuesta, err := dns.GetDominios(context.Background(), new(pb.Vacio))
-							if err != nil{
-								log.Printf("Error al ejecutar GetDominios: %s\n", err)
-								continue
-							}
-							//log.Printf("Dominios registrados: %+v", respuesta.Dominios)
-
-							// Revisar si existen los dominios en el nodo dominante
-							for _, dom := range respuesta.Dominios{
-								if _, ok := dominioRegistro[dom]; !ok { // Si no existe el dominio
-									// Obtener los archivos de registros correspondientes
-									stream, err := dns.GetFile(context.Background(), &pb.Consulta{NombreDominio: dom})
-									if err != nil {
-										log.Fatalf("Error abriendo el Stream %v", err)
-									}
-
-									done := make(chan bool)
-
-									nombreArchivo := RUTA_REGISTROS + ID_DNS + "/" + dom
-									file, err := os.OpenFile(nombreArchivo, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-										if err != nil {
-												fmt.Println(err)
-												os.Exit(1)
-										}
-
-									go func() {
-										for {
-											resp, err := stream.Recv()
-											if err == io.EOF {
-												file.Close()
-												done <- true 
-												return
-											}
-											if err != nil {
-												log.Fatalf("Error al recibir %v", err)
-											}
-
-											_, err = file.Write(resp.ChunkData)
-											if err != nil {
-													fmt.Println(err)
-													os.Exit(1)
-											}
-											file.Sync() 
-										}
-									}()
-
-									<-done
-								}
-							}
-
-						}
-						
-						
-					case <- quit:
-						ticker.Stop()
-						break
-					}
-				}
-			}
-		} else { // Si el servidor responde la consulta gRPC
-			//log.Printf("Nodo %s con servidor DNS ACTIVO, almacenando conexión\n", id )
-			conexionesNodos[id] = conn
-			conexionesGRPC[id] = c
+		// El FileInfo indica si la pieza corresponde al registro ZF o al log de cambios
+		if strings.HasPrefix(resp.FileInfo, registros.RUTA_LOGS) {
+			contenidoLog = append(contenidoLog, resp.ChunkData...)
+		} else {
+			contenidoRegistro = append(contenidoRegistro, resp.ChunkData...)
 		}
+		estado.Reloj = resp.Reloj
 	}
 
-}
\ No newline at end of file
+	estado.Registro = registros.DividirLineas(string(contenidoRegistro))
+	estado.Log = registros.DividirLineas(string(contenidoLog))
+	return estado, nil
+}
+
+// Fusiona los estados que distintos nodos tienen de un mismo dominio reproduciendo
+// sus logs de cambios en el orden causal dado por los relojes de vector. El log resultante
+// queda compactado como base del registro fusionado, con el reloj de la ronda.
+func fusionarEstados(estados []*registros.Estado) (*registros.Estado, error) {
+	fusion := new(registros.Estado)
+	fusion.IdNodo = ID_DNS
+
+	logs := make(map[string][]string)
+	for _, estado := range estados {
+		fusion.Reloj = registros.FusionarRelojes(fusion.Reloj, estado.Reloj)
+
+		// Un registro sin log de cambios (archivos antiguos) se toma como su propia base
+		if len(estado.Log) == 0 || (len(estado.Log) == 1 && estado.Log[0] == "") {
+			base, err := cambios.Compactar(estado.Registro, estado.IdNodo, estado.Reloj)
+			if err != nil {
+				return nil, err
+			}
+			logs[estado.IdNodo] = base
+		} else {
+			logs[estado.IdNodo] = estado.Log
+		}
+	}
+
+	operaciones, err := cambios.Fusionar(logs)
+	if err != nil {
+		return nil, err
+	}
+	fusion.Registro = cambios.Reproducir(operaciones)
+	if fusion.Log, err = cambios.Compactar(fusion.Registro, ID_DNS, fusion.Reloj); err != nil {
+		return nil, err
+	}
+	return fusion, nil
+}
+
+// Obtiene el dominio al que corresponde un FileInfo y si se trata de su log de cambios.
+// Se aceptan rutas de la forma registros/[<ID>/]<dominio> y logs/[<ID>/]<dominio>.log
+func interpretarFileInfo(fileInfo string) (string, bool, error) {
+	var esLog bool
+	var resto string
+	if strings.HasPrefix(fileInfo, registros.RUTA_LOGS) && strings.HasSuffix(fileInfo, ".log") {
+		esLog = true
+		resto = strings.TrimSuffix(strings.TrimPrefix(fileInfo, registros.RUTA_LOGS), ".log")
+	} else if strings.HasPrefix(fileInfo, registros.RUTA_REGISTROS) {
+		resto = strings.TrimPrefix(fileInfo, registros.RUTA_REGISTROS)
+	} else {
+		return "", false, errors.New("FileInfo no reconocido: " + fileInfo)
+	}
+
+	partes := strings.Split(resto, "/")
+	dominio := partes[len(partes) - 1]
+	if len(partes) > 2 || dominio == "" || dominio == "." || dominio == ".." || strings.Contains(dominio, "\\") {
+		return "", false, errors.New("FileInfo no reconocido: " + fileInfo)
+	}
+	return dominio, esLog, nil
+}
+
+// Envía el estado de un dominio a través de un stream, primero el registro ZF y luego el log
+// de cambios, en piezas de TAMANO_CHUNK acompañadas del reloj del dominio. De cada archivo se
+// envía al menos una pieza, de modo que el receptor conozca los archivos vacíos. Si se indica
+// una ronda, el receptor prepara el estado para esta en lugar de aplicarlo.
+func enviarEstado(send func(*pb.File) error, dominio string, estado *registros.Estado, idRonda string) error {
+	piezas := []*pb.File{
+		{FileInfo: registros.RUTA_REGISTROS + dominio, ChunkData: []byte(strings.Join(estado.Registro, "\n"))},
+		{FileInfo: registros.RUTA_LOGS + dominio + ".log", ChunkData: []byte(strings.Join(estado.Log, "\n"))},
+	}
+	for _, pieza := range piezas {
+		for inicio := 0; inicio == 0 || inicio < len(pieza.ChunkData); inicio += TAMANO_CHUNK {
+			fin := int(math.Min(float64(inicio + TAMANO_CHUNK), float64(len(pieza.ChunkData))))
+			chunk := &pb.File{FileInfo: pieza.FileInfo, ChunkData: pieza.ChunkData[inicio:fin], Reloj: estado.Reloj, IdNodo: estado.IdNodo, Ronda: idRonda}
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Envía mediante SetFile el estado de un dominio a otro nodo, o lo prepara para la ronda indicada
+func propagarEstado(dns pb.ServicioNodoClient, dominio string, estado *registros.Estado, idRonda string) error {
+	stream, err := dns.SetFile(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := enviarEstado(stream.Send, dominio, estado, idRonda); err != nil {
+		return err
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// Ronda de coordinación con commit en dos fases. El coordinador elegido congela las escrituras
+// en todos los nodos, obtiene el estado de todos los dominios desde cada uno y los fusiona según
+// sus relojes. Luego envía el estado fusionado a cada nodo, que lo guarda sin aplicarlo, y prepara
+// la ronda en todos. Solo si todos la prepararon registra en disco la decisión de confirmarla y
+// la confirma: cada nodo reemplaza entonces sus registros y compacta sus logs de cambios. Ante
+// cualquier falla anterior a la decisión, o si pierde el liderazgo, aborta la ronda y los nodos
+// conservan su estado. Un nodo que no responde al congelar queda fuera de la ronda y conserva su
+// estado y su log de cambios, que se fusionan con el resultado en la siguiente ronda en que participe.
+func coordinarServidores() {
+	log.Println("Coordinando servidores DNS")
+	ticker.Stop()
+	defer ticker.Reset(INTERVALO_COORDINACION)
+	liderazgo.MarcarRonda(true)
+	defer liderazgo.MarcarRonda(false)
+
+	// Copiar las conexiones y ordenar los nodos para que la ronda sea determinista
+	muConexiones.RLock()
+	conexiones := make(map[string]pb.ServicioNodoClient)
+	ids := make([]string, 0, len(conexionesGRPC))
+	for id, c := range conexionesGRPC {
+		if detector.Estado(id) == deteccion.MUERTO {
+			log.Printf("Se omite el nodo %s en la coordinación, no se reciben sus latidos\n", id)
+			continue
+		}
+		conexiones[id] = c
+		ids = append(ids, id)
+	}
+	muConexiones.RUnlock()
+	sort.Strings(ids)
+
+	// Fase 1: congelar las escrituras, primero en este nodo
+	if anterior := participante.EnCurso(); anterior != nil {
+		resolverRonda(anterior)
+	}
+	_, termino, _ := liderazgo.Lider()
+	idRonda := fmt.Sprintf("%s-%d-%d", ID_DNS, termino, time.Now().UnixNano())
+	mensaje := &pb.Ronda{Id: idRonda, Fase: ronda.CONGELAR, Coordinador: ID_DNS}
+	if err := participante.Congelar(idRonda, ID_DNS); err != nil {
+		log.Printf("Error al iniciar la ronda %s: %s\n", idRonda, err)
+		return
+	}
+	var congelados []string
+	for _, id := range ids {
+		if err := llamarRonda(conexiones[id], mensaje); err != nil {
+			log.Printf("Se omite el nodo %s en la ronda %s: %s\n", id, idRonda, err)
+			continue
+		}
+		congelados = append(congelados, id)
+	}
+	ids = congelados
+	confirmada := false
+	defer func() {
+		if !confirmada {
+			abortarRonda(idRonda, conexiones, ids)
+		}
+	}()
+
+	// Obtener los dominios registrados en cada servidor dns
+	dominiosNodo := make(map[string][]string)
+	dominios := make(map[string]bool)
+	for _, d := range reg.Dominios() {
+		dominios[d] = true
+	}
+	for _, id := range ids {
+		respuesta, err := conexiones[id].GetDominios(context.Background(), new(pb.Vacio))
+		if err != nil {
+			log.Printf("Error al ejecutar GetDominios en %s: %s\n", id, err)
+			return
+		}
+		dominiosNodo[id] = respuesta.Dominios
+		for _, dom := range respuesta.Dominios {
+			dominios[dom] = true
+		}
+	}
+
+	fusiones := make(map[string]*registros.Estado)
+	for dom := range dominios {
+		// Las zonas de consistencia fuerte ya son iguales en todos los miembros del grupo Raft
+		if configuracion.Fuerte(dom) {
+			continue
+		}
+
+		// Reunir el estado del dominio en cada nodo que lo conoce
+		var estados []*registros.Estado
+		if reg.ExisteRegistroMemoria(dom) {
+			estado, err := reg.Estado(dom)
+			if err != nil {
+				log.Printf("Error al leer el estado local de %s: %s\n", dom, err)
+				return
+			}
+			estados = append(estados, estado)
+		}
+		for _, id := range ids {
+			if _, found := Find(dominiosNodo[id], dom); !found {
+				continue
+			}
+			estado, err := obtenerEstadoRemoto(id, conexiones[id], dom)
+			if err != nil {
+				log.Printf("Error al obtener el estado de %s desde %s: %s\n", dom, id, err)
+				return
+			}
+			estados = append(estados, estado)
+		}
+
+		// Un dominio cuyos logs no se pueden fusionar se mantiene como está en cada nodo
+		fusion, err := fusionarEstados(estados)
+		if err != nil {
+			log.Printf("Error al fusionar los logs de cambios de %s: %s\n", dom, err)
+			continue
+		}
+		fusiones[dom] = fusion
+	}
+
+	// Fase 2: enviar el estado fusionado a todos los nodos y preparar la ronda, indicando los
+	// nodos que participan en ella
+	for dom, fusion := range fusiones {
+		if err := participante.Recibir(idRonda, dom, fusion); err != nil {
+			log.Printf("Error al preparar %s: %s\n", dom, err)
+			return
+		}
+		for _, id := range ids {
+			if err := propagarEstado(conexiones[id], dom, fusion, idRonda); err != nil {
+				log.Printf("Error al preparar %s en %s: %s\n", dom, id, err)
+				return
+			}
+		}
+	}
+	mensaje = &pb.Ronda{Id: idRonda, Fase: ronda.PREPARAR, Coordinador: ID_DNS, Participantes: append([]string{ID_DNS}, ids...)}
+	if err := participante.Preparar(idRonda, mensaje.Participantes); err != nil {
+		log.Printf("Error al preparar la ronda %s: %s\n", idRonda, err)
+		return
+	}
+	for _, id := range ids {
+		if err := llamarRonda(conexiones[id], mensaje); err != nil {
+			log.Printf("Error al preparar la ronda %s en %s: %s\n", idRonda, id, err)
+			return
+		}
+	}
+
+	// Todos los nodos prepararon la ronda: la decisión de confirmarla se registra en disco antes de
+	// aplicarla en cualquier nodo, de modo que este nodo responda lo mismo a quien consulte el
+	// resultado aunque caiga. Desde entonces la ronda ya no se aborta.
+	if !liderazgo.EsLider() {
+		log.Printf("Ronda %s interrumpida, se perdió el liderazgo\n", idRonda)
+		return
+	}
+	if err := participante.Decidir(idRonda); err != nil {
+		log.Printf("Error al registrar la decisión de la ronda %s: %s\n", idRonda, err)
+		return
+	}
+	confirmada = true
+	if err := confirmarRonda(idRonda); err != nil {
+		log.Printf("Error al confirmar la ronda %s, se reintentará en la siguiente: %s\n", idRonda, err)
+	}
+	mensaje.Fase = ronda.CONFIRMAR
+	for _, id := range ids {
+		if err := llamarRonda(conexiones[id], mensaje); err != nil {
+			log.Printf("Error al confirmar la ronda %s en %s: %s\n", idRonda, id, err)
+		}
+	}
+	for dom, fusion := range fusiones {
+		log.Printf("Dominio %s coordinado - Reloj: %+v\n", dom, fusion.Reloj)
+	}
+}
+
+func llamarRonda(dns pb.ServicioNodoClient, mensaje *pb.Ronda) error {
+	ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_RONDA)
+	defer cancel()
+	_, err := dns.CoordinarRonda(ctx, mensaje)
+	return err
+}
+
+func abortarRonda(idRonda string, conexiones map[string]pb.ServicioNodoClient, ids []string) {
+	if err := participante.Abortar(idRonda); err != nil {
+		log.Printf("Error al abortar la ronda %s: %s\n", idRonda, err)
+		return
+	}
+	mensaje := &pb.Ronda{Id: idRonda, Fase: ronda.ABORTAR, Coordinador: ID_DNS}
+	for _, id := range ids {
+		if err := llamarRonda(conexiones[id], mensaje); err != nil {
+			log.Printf("Error al abortar la ronda %s en %s: %s\n", idRonda, id, err)
+		}
+	}
+	log.Printf("Ronda %s abortada, los nodos conservan su estado\n", idRonda)
+}
+
+// Aplica los estados preparados de una ronda, todos a la vez, y libera las escrituras
+func confirmarRonda(idRonda string) error {
+	return participante.Confirmar(idRonda, func(estados map[string]*registros.Estado) error {
+		if err := reg.AplicarTodos(estados); err != nil {
+			return err
+		}
+		log.Printf("Ronda %s confirmada - %d dominios\n", idRonda, len(estados))
+		return nil
+	})
+}
+
+// Resuelve una ronda cuyo coordinador no la terminó. Una ronda congelada se aborta, ya que el
+// coordinador no puede confirmarla sin que este nodo la prepare. Una ronda preparada solo se
+// resuelve al conocer su resultado: se consulta al coordinador, que registra en disco la decisión
+// de confirmarla, y luego a los demás participantes. Si el coordinador responde sin conocer la
+// ronda es porque la perdió sin decidirla, por ejemplo al reiniciarse, y se aborta. Si nadie conoce
+// el resultado la ronda sigue en curso y se vuelve a consultar más tarde. Abortar no pierde
+// cambios, ya que estos siguen en los logs de cada nodo y se fusionan en la siguiente ronda.
+func resolverRonda(r *ronda.Ronda) {
+	switch r.Estado {
+	case ronda.CONFIRMADA: // Decidida en este nodo, falta aplicarla
+		if err := confirmarRonda(r.Id); err != nil {
+			log.Printf("Error al confirmar la ronda %s: %s\n", r.Id, err)
+		}
+		return
+	case ronda.CONGELADA:
+		if err := participante.Abortar(r.Id); err == nil {
+			log.Printf("Ronda %s de %s abortada, su coordinador no la terminó\n", r.Id, r.Coordinador)
+		}
+		return
+	}
+
+	consulta := &pb.Ronda{Id: r.Id, Fase: ronda.CONSULTAR, Coordinador: r.Coordinador}
+	consultados := []string{r.Coordinador}
+	for _, id := range r.Participantes {
+		if id != r.Coordinador && id != ID_DNS {
+			consultados = append(consultados, id)
+		}
+	}
+	for _, id := range consultados {
+		muConexiones.RLock()
+		dns, ok := conexionesGRPC[id]
+		muConexiones.RUnlock()
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_VOTACION)
+		respuesta, err := dns.CoordinarRonda(ctx, consulta)
+		cancel()
+		if err != nil {
+			continue
+		}
+		switch {
+		case respuesta.Estado == ronda.CONFIRMADA:
+			if err := confirmarRonda(r.Id); err != nil {
+				log.Printf("Error al confirmar la ronda %s: %s\n", r.Id, err)
+			}
+			return
+		case respuesta.Estado == ronda.ABORTADA, respuesta.Estado == ronda.DESCONOCIDA && id == r.Coordinador:
+			if err := participante.Abortar(r.Id); err == nil {
+				log.Printf("Ronda %s de %s abortada según %s\n", r.Id, r.Coordinador, id)
+			}
+			return
+		}
+	}
+	log.Printf("Se desconoce el resultado de la ronda %s de %s, se consultará de nuevo\n", r.Id, r.Coordinador)
+}
+
+// Resuelve la ronda en curso si venció, de modo que las escrituras no esperen indefinidamente.
+// Incluye las rondas de este nodo, por ejemplo una confirmada que no se pudo aplicar.
+func vigilarRonda() {
+	for range time.Tick(INTERVALO_VIGILANCIA) {
+		if r := participante.EnCurso(); r != nil && r.Vencida(time.Now()) {
+			resolverRonda(r)
+		}
+	}
+}
+
+
+//// CONSISTENCIA FUERTE
+
+// Aplica un cambio realizado en este nodo. En una zona de consistencia fuerte el cambio se propone
+// como líder del grupo Raft y se aplica en todos sus miembros al confirmarse; en las demás zonas se
+// aplica de inmediato, esperando si hay una ronda de consistencia en curso. Retorna errores gRPC, los
+// de los registros según su motivo.
+func aplicarCambio(ctx context.Context, nombre string, dominio string, op *cambios.Operacion) ([]int32, error) {
+	op.Origen = ID_DNS
+	if !configuracion.Fuerte(dominio) {
+		terminar, err := participante.IniciarEscritura(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		defer terminar()
+		reloj, err := reg.Ejecutar(nombre, dominio, op)
+		if err != nil {
+			return nil, registros.ErrorGRPC(err)
+		}
+		return reloj, nil
+	}
+
+	comando, err := json.Marshal(&comandoZona{Nombre: nombre, Zona: dominio, Operacion: op})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	reloj, err := grupo.Proponer(ctx, comando)
+	var errorRaft *raft.Error
+	if errors.As(err, &errorRaft) {
+		// Una escritura que no llegó al log puede reintentarse, una de resultado incierto no
+		if errorRaft.Incierto {
+			return nil, status.Error(codes.DeadlineExceeded, err.Error())
+		}
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, registros.ErrorGRPC(err)
+	}
+	return reloj.([]int32), nil
+}
+
+// Elige el nodo al que se reenvía una solicitud de una zona de consistencia fuerte que este nodo
+// no atiende: el líder del grupo Raft o, si el nodo no es parte del grupo, uno de sus miembros.
+// El contexto retornado lleva la cuenta de reenvíos, que se limita a MAX_REENVIOS para que una
+// solicitud no circule entre nodos mientras se elige un nuevo líder.
+func destinoFuerte(ctx context.Context) (pb.ServicioNodoClient, context.Context, error) {
+	reenvios := 0
+	if valores := metadata.ValueFromIncomingContext(ctx, CLAVE_REENVIOS); len(valores) > 0 {
+		reenvios, _ = strconv.Atoi(valores[0])
+	}
+	if reenvios >= MAX_REENVIOS {
+		return nil, nil, status.Error(codes.Unavailable, "La solicitud no llegó al líder del grupo Raft")
+	}
+
+	ids := configuracion.IdsDNS()
+	if grupo != nil {
+		lider, _ := grupo.Lider()
+		if lider == "" || lider == ID_DNS {
+			return nil, nil, status.Error(codes.Unavailable, "Sin líder del grupo Raft, la escritura no se realizó")
+		}
+		ids = []string{lider}
+	}
+	muConexiones.RLock()
+	defer muConexiones.RUnlock()
+	for _, id := range ids {
+		if dns, ok := conexionesGRPC[id]; ok && detector.Estado(id) != deteccion.MUERTO {
+			return dns, metadata.AppendToOutgoingContext(ctx, CLAVE_REENVIOS, strconv.Itoa(reenvios + 1)), nil
+		}
+	}
+	return nil, nil, status.Error(codes.Unavailable, "Sin conexión con el grupo Raft de las zonas de consistencia fuerte")
+}
+
+func (maquinaZonas) Aplicar(comando []byte) (interface{}, error) {
+	c := new(comandoZona)
+	if err := json.Unmarshal(comando, c); err != nil {
+		return nil, err
+	}
+	reloj, err := reg.Ejecutar(c.Nombre, c.Zona, c.Operacion)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Cambio replicado: %s %s en %s - Reloj: %+v\n", c.Operacion.Tipo, c.Nombre, c.Zona, reloj)
+	return reloj, nil
+}
+
+// El snapshot incluye el registro ZF y el reloj de cada zona de consistencia fuerte, sin sus logs
+// de cambios, ya que el log replicado reemplaza a las rondas que los fusionan
+func (maquinaZonas) Snapshot() ([]byte, error) {
+	estados := make(map[string]*registros.Estado)
+	for _, zona := range reg.Dominios() {
+		if !configuracion.Fuerte(zona) {
+			continue
+		}
+		estado, err := reg.Estado(zona)
+		if err != nil {
+			return nil, err
+		}
+		estado.Log = []string{}
+		estados[zona] = estado
+	}
+	return json.Marshal(estados)
+}
+
+// Una vez persistido el snapshot se truncan los logs de cambios de las zonas que incluye
+func (maquinaZonas) Compactar() error {
+	for _, zona := range reg.Dominios() {
+		if !configuracion.Fuerte(zona) {
+			continue
+		}
+		if err := reg.Compactar(zona); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reemplaza las zonas de consistencia fuerte por las del snapshot. Las zonas que este no incluye
+// vuelven a su estado inicial, vacías: su contenido lo determina solo el log replicado.
+func (maquinaZonas) Restaurar(datos []byte) error {
+	estados := make(map[string]*registros.Estado)
+	if len(datos) > 0 {
+		if err := json.Unmarshal(datos, &estados); err != nil {
+			return err
+		}
+	}
+	for _, zona := range reg.Dominios() {
+		if _, ok := estados[zona]; ok || !configuracion.Fuerte(zona) {
+			continue
+		}
+		estados[zona] = &registros.Estado{IdNodo: ID_DNS, Registro: []string{}, Log: []string{}}
+	}
+	return reg.AplicarTodos(estados)
+}
+
+func conexionRaft(id string) (pb.ServicioNodoClient, error) {
+	muConexiones.RLock()
+	defer muConexiones.RUnlock()
+	dns, ok := conexionesGRPC[id]
+	if !ok {
+		return nil, status.Error(codes.Unavailable, "Sin conexión con el nodo " + id)
+	}
+	return dns, nil
+}
+
+func (transporteRaft) SolicitarVoto(ctx context.Context, id string, solicitud *pb.SolicitudVotoRaft) (*pb.Voto, error) {
+	dns, err := conexionRaft(id)
+	if err != nil {
+		return nil, err
+	}
+	return dns.SolicitarVotoRaft(ctx, solicitud)
+}
+
+func (transporteRaft) AgregarEntradas(ctx context.Context, id string, entradas *pb.EntradasRaft) (*pb.ResultadoRaft, error) {
+	dns, err := conexionRaft(id)
+	if err != nil {
+		return nil, err
+	}
+	return dns.AgregarEntradas(ctx, entradas)
+}
+
+func (transporteRaft) InstalarSnapshot(ctx context.Context, id string, snapshot *pb.SnapshotRaft) (*pb.ResultadoRaft, error) {
+	dns, err := conexionRaft(id)
+	if err != nil {
+		return nil, err
+	}
+	return dns.InstalarSnapshot(ctx, snapshot)
+}
+
+
+//// MEMBRESÍA DEL CLÚSTER
+
+func nodoLocal() config.NodeInfo {
+	return config.NodeInfo{Id: ID_DNS, Ip: IP_DNS, Port: PORT_DNS, DnsPort: PORT_CONSULTAS}
+}
+
+// Identifica el servidor DNS de la configuración que corresponde a esta máquina: el primero con
+// una IP de la máquina que aún no responde, ya que los demás corresponden a nodos en ejecución
+func identificarNodo() bool {
+	machineIPs := obtenerListaIPs() // Obtener lista de IPs asociadas a la máquina
+	for _, dns := range configuracion.DNS { // Iterar sobre las IP configuradas para servidores DNS
+		if _, found := Find(machineIPs, dns.Ip); !found {
+			continue
+		}
+		conn, err := nodo.ConectarNodo(dns.Ip, dns.Port)
+		if err != nil {
+			log.Printf("Error al intentar conectar al nodo %s | %s:%s | %s\n", dns.Id, dns.Ip, dns.Port, err)
+			continue
+		}
+		_, err = pb.NewServicioNodoClient(conn).ObtenerEstado(context.Background(), new(pb.Consulta))
+		conn.Close()
+		if err != nil { // Si el servidor no responde corresponde a este nodo
+			ID_DNS = dns.Id
+			IP_DNS = dns.Ip
+			PORT_DNS = dns.Port
+			PORT_CONSULTAS = dns.DnsPort
+			return true
+		}
+	}
+	return false
+}
+
+// Establece la vista de la membresía: actualiza las posiciones del reloj y las conexiones con los
+// demás miembros vigentes, cerrando las de los nodos retirados. Debe llamarse manteniendo muVista.
+func establecerVista(v *membresia.Vista) {
+	if _, ok := v.Buscar(ID_DNS); ok {
+		if err := reg.ActualizarNodos(v.Ids()); err != nil {
+			log.Printf("Error al actualizar las posiciones del reloj: %s\n", err)
+		}
+	}
+	detector.ActualizarMiembros(v.Vigentes())
+	vigentes := make(map[string]config.NodeInfo)
+	for _, n := range v.Vigentes() {
+		if n.Id != ID_DNS {
+			vigentes[n.Id] = n
+		}
+	}
+
+	muConexiones.Lock()
+	defer muConexiones.Unlock()
+	for id, conn := range conexionesNodos {
+		if n, ok := vigentes[id]; !ok || conn.Target() != n.Ip + ":" + n.Port {
+			conn.Close()
+			delete(conexionesNodos, id)
+			delete(conexionesGRPC, id)
+		}
+	}
+	for id, n := range vigentes {
+		if _, ok := conexionesNodos[id]; ok {
+			continue
+		}
+		conn, err := nodo.ConectarNodo(n.Ip, n.Port)
+		if err != nil {
+			log.Printf("Error al intentar conectar al nodo %s | %s:%s | %s\n", id, n.Ip, n.Port, err)
+			continue
+		}
+		conexionesNodos[id] = conn
+		conexionesGRPC[id] = pb.NewServicioNodoClient(conn)
+	}
+}
+
+// Adopta una vista de la membresía si es más reciente que la conocida
+func aplicarVista(v *membresia.Vista) {
+	muVista.Lock()
+	defer muVista.Unlock()
+	if v.Version <= vista.Version {
+		return
+	}
+	vista = v
+	establecerVista(v)
+	log.Printf("Vista de la membresía: %s\n", v)
+}
+
+// Se une al clúster a través del broker, que asigna la posición del nodo en los relojes y le
+// transfiere el estado de las zonas desde otro miembro. Si el broker no está disponible, un nodo
+// de la configuración utiliza la vista inicial de esta.
+func unirse() {
+	conn, err := nodo.ConectarNodo(configuracion.Broker.Ip, configuracion.Broker.Port)
+	if err == nil {
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_UNION)
+		defer cancel()
+		var respuesta *pb.Vista
+		respuesta, err = pb.NewServicioNodoClient(conn).Join(ctx, membresia.MiembroProto(nodoLocal(), membresia.UNIENDOSE))
+		if err == nil {
+			aplicarVista(membresia.DesdeProto(respuesta))
+			return
+		}
+	}
+
+	if _, found := Find(configuracion.IdsDNS(), ID_DNS); !found {
+		log.Fatalf("Error al unirse al clúster: %s", validacion.Describir(err))
+	}
+	log.Printf("Error al unirse al clúster, se utiliza la vista de la configuración: %s\n", validacion.Describir(err))
+	muVista.Lock()
+	establecerVista(vista)
+	muVista.Unlock()
+}
+
+// Al recibir SIGINT o SIGTERM se retira del clúster a través del broker, que deja de enviarle
+// consultas, espera las solicitudes en curso y le pide transferir su estado a otro miembro
+func retirarseAlTerminar() {
+	senales := make(chan os.Signal, 1)
+	signal.Notify(senales, syscall.SIGINT, syscall.SIGTERM)
+	<-senales
+
+	log.Println("Retirándose del clúster")
+	conn, err := nodo.ConectarNodo(configuracion.Broker.Ip, configuracion.Broker.Port)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_SALIDA)
+		_, err = pb.NewServicioNodoClient(conn).Leave(ctx, membresia.MiembroProto(nodoLocal(), membresia.SALIENDO))
+		cancel()
+		conn.Close()
+	}
+	if err != nil {
+		log.Printf("Error al retirarse del clúster: %s\n", validacion.Describir(err))
+	}
+	if err := reg.Guardar(); err != nil {
+		log.Printf("Error al escribir los registros ZF: %s\n", err)
+	}
+	os.Exit(0)
+}
+
+
+//// DETECCIÓN DE FALLAS
+
+// Cada INTERVALO_LATIDO incrementa el latido del nodo, lo intercambia con NODOS_LATIDO miembros
+// elegidos al azar y revisa qué nodos dejaron de latir
+func difundirLatidos() {
+	for range time.Tick(INTERVALO_LATIDO) {
+		detector.Latir()
+		for _, n := range detector.Elegir(NODOS_LATIDO) {
+			go intercambiarLatidos(n)
+		}
+		registrarCambios(detector.Revisar())
+	}
+}
+
+func intercambiarLatidos(n config.NodeInfo) {
+	muConexiones.RLock()
+	dns, ok := conexionesGRPC[n.Id]
+	muConexiones.RUnlock()
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), INTERVALO_LATIDO)
+	defer cancel()
+	respuesta, err := dns.IntercambiarLatidos(ctx, detector.Latidos())
+	if err != nil { // Un nodo que no responde deja de latir para este nodo
+		return
+	}
+	registrarCambios(detector.Fusionar(respuesta))
+}
+
+// Registra los cambios de estado de los nodos. Al recuperarse un nodo se reinicia la espera entre
+// reintentos de su conexión, de modo que se reconecta de inmediato.
+func registrarCambios(cambios []deteccion.Cambio) {
+	for _, c := range cambios {
+		log.Printf("Nodo %s (%s:%s) %s -> %s\n", c.Id, c.Ip, c.Port, c.Anterior, c.Estado)
+		if c.Estado != deteccion.VIVO {
+			continue
+		}
+		muConexiones.RLock()
+		if conn, ok := conexionesNodos[c.Id]; ok {
+			conn.ResetConnectBackoff()
+		}
+		muConexiones.RUnlock()
+	}
+}
+
+
+//// ELECCIÓN DEL COORDINADOR
+
+// Mantiene el coordinador de las rondas de consistencia: el líder renueva su liderazgo cada
+// INTERVALO_RENOVACION y, si no hay un líder vigente, el nodo se postula tras una espera aleatoria
+// de hasta ESPERA_ELECCION, de modo que los nodos no se postulen a la vez dividiendo los votos
+func mantenerLiderazgo() {
+	for {
+		if liderazgo.EsLider() {
+			renovarLiderazgo()
+			time.Sleep(INTERVALO_RENOVACION)
+			continue
+		}
+		time.Sleep(time.Duration(rand.Int63n(int64(ESPERA_ELECCION))))
+		if liderazgo.PuedePostularse() {
+			postularse()
+		}
+	}
+}
+
+func postularse() {
+	inicio := time.Now()
+	termino := liderazgo.Postular()
+	solicitud := &pb.SolicitudVoto{Termino: termino, Candidato: ID_DNS}
+	if !consultarMayoria(func(ctx context.Context, dns pb.ServicioNodoClient) (*pb.Voto, error) {
+		return dns.SolicitarVoto(ctx, solicitud)
+	}) {
+		return
+	}
+	asumido, pendiente := liderazgo.Asumir(termino, inicio)
+	if !asumido {
+		return
+	}
+	log.Printf("Coordinador de las rondas de consistencia: %s (término %d)\n", ID_DNS, termino)
+	if pendiente {
+		renovarLiderazgo() // Los demás nodos deben conocer al coordinador antes de la ronda
+		log.Println("El coordinador anterior no terminó su ronda, se retoma")
+		select {
+		case retomarRonda <- true:
+		default:
+		}
+	}
+}
+
+func renovarLiderazgo() {
+	inicio := time.Now()
+	_, termino, _ := liderazgo.Lider()
+	mensaje := &pb.Liderazgo{Termino: termino, Lider: ID_DNS, RondaEnCurso: liderazgo.RondaEnCurso()}
+	if !consultarMayoria(func(ctx context.Context, dns pb.ServicioNodoClient) (*pb.Voto, error) {
+		return dns.RenovarLiderazgo(ctx, mensaje)
+	}) || !liderazgo.Renovar(termino, inicio) {
+		log.Printf("No se pudo renovar el liderazgo del término %d\n", termino)
+	}
+}
+
+// Envía una solicitud de voto o de renovación a los demás miembros vigentes y retorna si la
+// mayoría de ellos, contando a este nodo, la concedió. Los nodos MUERTO no se consultan, pero
+// cuentan para la mayoría. Si algún nodo responde con un término mayor este nodo lo adopta.
+func consultarMayoria(consultar func(context.Context, pb.ServicioNodoClient) (*pb.Voto, error)) bool {
+	muVista.Lock()
+	miembros := vista.Vigentes()
+	_, esMiembro := vista.Buscar(ID_DNS)
+	muVista.Unlock()
+	if !esMiembro {
+		return false
+	}
+
+	var wg sync.WaitGroup
+	var concedidos int32 = 1
+	for _, n := range miembros {
+		muConexiones.RLock()
+		dns, ok := conexionesGRPC[n.Id]
+		muConexiones.RUnlock()
+		if !ok || detector.Estado(n.Id) == deteccion.MUERTO {
+			continue
+		}
+		wg.Add(1)
+		go func(dns pb.ServicioNodoClient) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_VOTACION)
+			defer cancel()
+			voto, err := consultar(ctx, dns)
+			if err != nil {
+				return
+			}
+			liderazgo.Actualizar(voto.Termino)
+			if voto.Concedido {
+				atomic.AddInt32(&concedidos, 1)
+			}
+		}(dns)
+	}
+	wg.Wait()
+	return int(concedidos) > len(miembros) / 2
+}
+
+
+// Inicia los registros del nodo y recupera los almacenados en disco. Los relojes tienen una
+// posición por cada servidor DNS de la configuración y otra para este nodo si no está en ella.
+func iniciarRegistros() error {
+	ids := configuracion.IdsDNS()
+	if _, found := Find(ids, ID_DNS); !found {
+		ids = append(ids, ID_DNS)
+	}
+	var err error
+	if reg, err = registros.NuevoRegistros(ID_DNS, ids, configuracion.Zonas); err != nil {
+		return errors.New("Error al iniciar los registros: " + err.Error())
+	}
+	if err := reg.Cargar(); err != nil {
+		return errors.New("Error al cargar los registros almacenados: " + err.Error())
+	}
+	return nil
+}
+
+
+func main() {
+	log.Printf("= INICIANDO DNS SERVER =")
+
+	// Cargar archivo de configuración
+	configuracion = config.GenConfig(CONFIG_FILENAME)
+
+	// Inicializar variables
+	log.Printf("Inicializando variables")
+	conexionesNodos = make(map[string]*grpc.ClientConn)
+	conexionesGRPC = make(map[string]pb.ServicioNodoClient)
+
+	// Identificar el servidor DNS: un nodo que no está en la configuración se indica por argumentos
+	// (<id> <ip> <puerto> [<puerto DNS>]), los demás según la IP de la máquina
+	if len(os.Args) >= 4 {
+		ID_DNS, IP_DNS, PORT_DNS = os.Args[1], os.Args[2], os.Args[3]
+		if len(os.Args) >= 5 {
+			PORT_CONSULTAS = os.Args[4]
+		}
+	} else if !identificarNodo() {
+		log.Fatalf("No se encontró un servidor DNS de la configuración sin iniciar en esta máquina")
+	}
+	log.Printf("Nodo %s | %s:%s\n", ID_DNS, IP_DNS, PORT_DNS)
+
+	// La vista de la configuración es provisional (versión 0) hasta unirse al clúster
+	vista = membresia.DesdeConfig(configuracion.DNS)
+	vista.Version = 0
+	detector = deteccion.NuevoDetector(nodoLocal(), TIEMPO_SOSPECHA, TIEMPO_MUERTE)
+	liderazgo = eleccion.NuevaEleccion(ID_DNS, DURACION_LIDERAZGO)
+	participante = ronda.NuevoParticipante(TIEMPO_RONDA)
+	if err := participante.Cargar(RUTA_RONDAS + ID_DNS); err != nil {
+		log.Fatalf("Error al cargar las rondas confirmadas: %s", err)
+	}
+
+	// Recuperar los registros almacenados antes de atender consultas
+	if err := iniciarRegistros(); err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	// Las zonas de consistencia fuerte se replican con Raft entre los servidores DNS de la
+	// configuración; los demás nodos les reenvían las solicitudes de esas zonas
+	if err := configuracion.ValidarConsistencia(); err != nil {
+		log.Fatalf("Error en la configuración: %s", err)
+	}
+	if _, found := Find(configuracion.IdsDNS(), ID_DNS); found && len(configuracion.ZonasFuertes()) > 0 {
+		var pares []string
+		for _, id := range configuracion.IdsDNS() {
+			if id != ID_DNS {
+				pares = append(pares, id)
+			}
+		}
+		var err error
+		if grupo, err = raft.NuevoNodo(ID_DNS, pares, RUTA_RAFT + ID_DNS + "/", transporteRaft{}, maquinaZonas{}); err != nil {
+			log.Fatalf("Error al iniciar el log replicado: %s", err)
+		}
+		log.Printf("Zonas de consistencia fuerte: %s\n", strings.Join(configuracion.ZonasFuertes(), ", "))
+	}
+
+	// Escribir periódicamente las instantáneas de los registros ZF modificados
+	go func() {
+		for range time.Tick(INTERVALO_INSTANTANEA) {
+			if err := reg.Guardar(); err != nil {
+				log.Printf("Error al escribir los registros ZF: %s\n", err)
+			}
+		}
+	}()
+
+	// Solo el coordinador elegido realiza las rondas de coordinación
+	ticker = time.NewTicker(INTERVALO_COORDINACION)
+
+	iniciarNodo(PORT_DNS)
+
+	// Atender consultas DNS estándar si se configuró su puerto
+	if PORT_CONSULTAS != "" {
+		go func() {
+			log.Printf("Atendiendo consultas DNS en el puerto %s (UDP y TCP)\n", PORT_CONSULTAS)
+			if err := resolucion.Iniciar(PORT_CONSULTAS, resolucion.Manejador(resolverLocal)); err != nil {
+				log.Printf("Error al atender consultas DNS: %s\n", err)
+			}
+		}()
+	}
+
+	// Unirse al clúster, recibiendo el estado de las zonas, y retirarse de este al terminar
+	unirse()
+	go retirarseAlTerminar()
+	go difundirLatidos()
+	go mantenerLiderazgo()
+	go vigilarRonda()
+	if grupo != nil {
+		grupo.Iniciar()
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-retomarRonda:
+		}
+		if liderazgo.EsLider() {
+			coordinarServidores()
+		}
+	}
+}
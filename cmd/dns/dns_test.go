@@ -1,22 +1,323 @@
 package main
 
 import (
+	"io"
+	"net"
+	"os"
+	"sync"
 	"testing"
+	"time"
 	"context"
 	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/cambios"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	"github.com/jfomu/DNSDistribuido/internal/deteccion"
+	"github.com/jfomu/DNSDistribuido/internal/eleccion"
+	"github.com/jfomu/DNSDistribuido/internal/membresia"
+	"github.com/jfomu/DNSDistribuido/internal/nodo"
+	"github.com/jfomu/DNSDistribuido/internal/registros"
+	"github.com/jfomu/DNSDistribuido/internal/ronda"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+var nodosPrueba = []config.NodeInfo{
+	{Id: "DNS1", Ip: "127.0.0.1", Port: "9101"},
+	{Id: "DNS2", Ip: "127.0.0.1", Port: "9102"},
+	{Id: "DNS3", Ip: "127.0.0.1", Port: "9103"},
+}
+
+// Inicia el estado de este nodo como DNS1, sin otros nodos conectados, dentro de un directorio temporal
+func iniciarPrueba(t *testing.T) {
+	dir, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(dir) })
+
+	configuracion = &config.Config{DNS: nodosPrueba}
+	ID_DNS, IP_DNS, PORT_DNS = "DNS1", "127.0.0.1", "9101"
+	conexionesNodos = make(map[string]*grpc.ClientConn)
+	conexionesGRPC = make(map[string]pb.ServicioNodoClient)
+	vista = membresia.DesdeConfig(configuracion.DNS)
+	detector = deteccion.NuevoDetector(nodoLocal(), TIEMPO_SOSPECHA, TIEMPO_MUERTE)
+	liderazgo = eleccion.NuevaEleccion(ID_DNS, DURACION_LIDERAZGO)
+	participante = ronda.NuevoParticipante(TIEMPO_RONDA)
+	assert.Nil(t, participante.Cargar(RUTA_RONDAS + ID_DNS))
+	grupo = nil
+	ticker = time.NewTicker(INTERVALO_COORDINACION)
+	t.Cleanup(ticker.Stop)
+	assert.Nil(t, iniciarRegistros())
+}
+
+// Atiende un servidor gRPC en un puerto libre y retorna un cliente conectado a él
+func servirPrueba(t *testing.T, servidor pb.ServicioNodoServer) pb.ServicioNodoClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	grpcServer := grpc.NewServer()
+	pb.RegisterServicioNodoServer(grpcServer, servidor)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewServicioNodoClient(conn)
+}
+
+// Consulta la dirección A de un nombre en este nodo
+func consultarIP(t *testing.T, nombreDominio string) (string, []int32) {
+	respuesta, err := new(Server).Get(context.Background(), &pb.Consulta{NombreDominio: nombreDominio})
+	if err != nil {
+		return "", nil
+	}
+	return respuesta.Respuesta, respuesta.Reloj
+}
+
+// Estado de un dominio recibido desde otro nodo, con su log de cambios generado a partir del registro
+func estadoPrueba(t *testing.T, id string, reloj []int32, registro ...string) *registros.Estado {
+	log, err := cambios.Compactar(registro, id, reloj)
+	assert.Nil(t, err)
+	return &registros.Estado{IdNodo: id, Reloj: reloj, Registro: registro, Log: log}
+}
+
 func TestCreate(t *testing.T) {
+	iniciarPrueba(t)
 	s := new(Server)
 
 	consulta := new(pb.Consulta)
 	consulta.NombreDominio = "google.com"
 	consulta.Ip = "8.8.8.8"
-	respuesta, _ := s.Create(context.Background(), consulta)
+	respuesta, err := s.Create(context.Background(), consulta)
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{1,0,0}, respuesta.Reloj)
+	assert.Equal(t, "9101", respuesta.Port)
+
+	ip, reloj := consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.8.8", ip)
+	assert.Equal(t, []int32{1,0,0}, reloj)
+}
+
+func TestErroresEscritura(t *testing.T) {
+	iniciarPrueba(t)
+	s := new(Server)
+
+	_, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+	assert.Nil(t, err)
+
+	// Los cambios rechazados por los registros se distinguen por su código gRPC
+	_, err = s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = s.Update(context.Background(), &pb.ConsultaUpdate{NombreDominio: "yahoo.com", Opcion: "ip", Param: "1.1.1.1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.Delete(context.Background(), &pb.ConsultaAdmin{NombreDominio: "google.cl"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "no es una ip"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestReinicio(t *testing.T) {
+	iniciarPrueba(t)
+	s := new(Server)
+
+	_, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+	assert.Nil(t, err)
+	_, err = s.Update(context.Background(), &pb.ConsultaUpdate{NombreDominio: "google.com", Opcion: "ip", Param: "8.8.4.4"})
+	assert.Nil(t, err)
+
+	// Al reiniciar el nodo se recuperan los registros y relojes desde los archivos
+	assert.Nil(t, iniciarRegistros())
+	ip, reloj := consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.4.4", ip)
+	assert.Equal(t, []int32{2,0,0}, reloj)
+
+	// La zona recuperada sigue aceptando escrituras, que continúan su reloj
+	respuesta, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "yahoo.com", Ip: "1.1.1.1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{3,0,0}, respuesta.Reloj)
+}
+
+func TestSetFile(t *testing.T) {
+	iniciarPrueba(t)
+	dns := servirPrueba(t, new(Server))
+
+	// El estado recibido fuera de una ronda se escribe en los archivos del nodo y se carga en memoria
+	assert.Nil(t, propagarEstado(dns, "com", estadoPrueba(t, "DNS2", []int32{0,1,0}, "google.com IN A 8.8.8.8"), ""))
+	ip, reloj := consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.8.8", ip)
+	assert.Equal(t, []int32{0,1,0}, reloj)
+	contenido, err := os.ReadFile(registros.RUTA_REGISTROS + "DNS1/com")
+	assert.Nil(t, err)
+	assert.Equal(t, "google.com IN A 8.8.8.8", string(contenido))
+	contenido, err = os.ReadFile(registros.RUTA_LOGS + "DNS1/com.log")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, string(contenido))
+
+	// En una ronda el estado solo se guarda, y se aplica al confirmarla una vez preparada
+	assert.Nil(t, participante.Congelar("R1", "DNS2"))
+	assert.Nil(t, propagarEstado(dns, "com", estadoPrueba(t, "DNS2", []int32{0,2,0}, "google.com IN A 8.8.4.4"), "R1"))
+	assert.Equal(t, ronda.CONGELADA, participante.Estado("R1"))
+	assert.Nil(t, participante.Preparar("R1", []string{"DNS2", "DNS1"}))
+	ip, _ = consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.8.8", ip)
+	assert.Nil(t, confirmarRonda("R1"))
+	ip, reloj = consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.4.4", ip)
+	assert.Equal(t, []int32{0,2,0}, reloj)
+
+	// El estado de una ronda que no está en curso se rechaza
+	err = propagarEstado(dns, "com", estadoPrueba(t, "DNS2", []int32{0,3,0}), "R2")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestInterpretarFileInfo(t *testing.T) {
+	validos := map[string]string{
+		"registros/com": "com",
+		"registros/DNS2/uchile.cl": "uchile.cl",
+		"logs/DNS2/com.log": "com",
+	}
+	for fileInfo, esperado := range validos {
+		dominio, esLog, err := interpretarFileInfo(fileInfo)
+		assert.Nil(t, err, fileInfo)
+		assert.Equal(t, esperado, dominio)
+		assert.Equal(t, fileInfo[:5] == "logs/", esLog)
+	}
+
+	// Las rutas fuera de los directorios de registros y logs se rechazan
+	for _, fileInfo := range []string{"", "com", "logs/com", "otros/com", "registros/", "registros/DNS2/..", "registros/../../etc/passwd", "logs/DNS2/x/com.log"} {
+		_, _, err := interpretarFileInfo(fileInfo)
+		assert.NotNil(t, err, fileInfo)
+	}
+}
+
+// Servidor DNS de prueba que participa en las rondas de coordinación con el estado indicado
+type parPrueba struct{
+	nodo.Server
+	estado *registros.Estado
+	errSetFile error // error con que responde SetFile, tras recibir el estado
+	estadoRonda string // estado con que responde las consultas de rondas
+	mu sync.Mutex
+	fases []string // fases de las rondas recibidas
+	preparadas []string // rondas de los estados recibidos mediante SetFile
+}
+
+func (p *parPrueba) GetDominios(ctx context.Context, message *pb.Vacio) (*pb.Dominios, error){
+	return &pb.Dominios{Dominios: []string{"com"}}, nil
+}
+
+func (p *parPrueba) GetFile(message *pb.Consulta, srv pb.ServicioNodo_GetFileServer) error{
+	return enviarEstado(srv.Send, message.NombreDominio, p.estado, "")
+}
+
+func (p *parPrueba) SetFile(stream pb.ServicioNodo_SetFileServer) error{
+	for {
+		pieza, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.preparadas = append(p.preparadas, pieza.Ronda)
+		p.mu.Unlock()
+	}
+	if p.errSetFile != nil {
+		return p.errSetFile
+	}
+	return stream.SendAndClose(&pb.Estado{Estado: ronda.PREPARADA})
+}
+
+func (p *parPrueba) CoordinarRonda(ctx context.Context, message *pb.Ronda) (*pb.EstadoRonda, error){
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fases = append(p.fases, message.Fase)
+	return &pb.EstadoRonda{Id: message.Id, Estado: p.estadoRonda}, nil
+}
+
+// Inicia una ronda de coordinación con DNS2 como único otro nodo y retorna las fases que recibió
+func coordinarConPar(t *testing.T, par *parPrueba) []string {
+	conexionesGRPC["DNS2"] = servirPrueba(t, par)
+	asumido, _ := liderazgo.Asumir(liderazgo.Postular(), time.Now())
+	assert.True(t, asumido)
+	coordinarServidores()
+
+	par.mu.Lock()
+	defer par.mu.Unlock()
+	return append([]string{}, par.fases...)
+}
+
+func TestCoordinarServidoresAborta(t *testing.T) {
+	iniciarPrueba(t)
+	s := new(Server)
+	_, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+	assert.Nil(t, err)
+
+	// Si un nodo no recibe el estado fusionado la ronda se aborta y cada nodo conserva su estado
+	par := &parPrueba{estado: estadoPrueba(t, "DNS2", []int32{0,1,0}, "yahoo.com IN A 1.1.1.1"), errSetFile: status.Error(codes.Unavailable, "Caído")}
+	assert.Equal(t, []string{ronda.CONGELAR, ronda.ABORTAR}, coordinarConPar(t, par))
+	assert.NotEmpty(t, par.preparadas)
+	assert.Equal(t, ronda.ABORTADA, participante.Estado(par.preparadas[0]))
+	ip, _ := consultarIP(t, "yahoo.com")
+	assert.Equal(t, "", ip)
+	ip, reloj := consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.8.8", ip)
+	assert.Equal(t, []int32{1,0,0}, reloj)
+
+	// Las escrituras quedan liberadas
+	respuesta, err := s.Create(context.Background(), &pb.Consulta{NombreDominio: "bing.com", Ip: "2.2.2.2"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{2,0,0}, respuesta.Reloj)
+}
+
+func TestCoordinarServidoresConfirma(t *testing.T) {
+	iniciarPrueba(t)
+	_, err := new(Server).Create(context.Background(), &pb.Consulta{NombreDominio: "google.com", Ip: "8.8.8.8"})
+	assert.Nil(t, err)
+
+	// Si todos los nodos prepararon el estado fusionado la ronda se confirma y este nodo lo aplica
+	par := &parPrueba{estado: estadoPrueba(t, "DNS2", []int32{0,1,0}, "yahoo.com IN A 1.1.1.1")}
+	assert.Equal(t, []string{ronda.CONGELAR, ronda.PREPARAR, ronda.CONFIRMAR}, coordinarConPar(t, par))
+	assert.NotEmpty(t, par.preparadas)
+	assert.Equal(t, ronda.CONFIRMADA, participante.Estado(par.preparadas[0]))
+	ip, reloj := consultarIP(t, "yahoo.com")
+	assert.Equal(t, "1.1.1.1", ip)
+	assert.Equal(t, []int32{1,1,0}, reloj)
+	ip, _ = consultarIP(t, "google.com")
+	assert.Equal(t, "8.8.8.8", ip)
+}
+
+// Prepara en este nodo una ronda de DNS2 en que también participa DNS3, y la resuelve como si venciera
+func resolverConPares(t *testing.T, coordinador string, otro string) string {
+	conexionesGRPC["DNS2"] = servirPrueba(t, &parPrueba{estadoRonda: coordinador})
+	conexionesGRPC["DNS3"] = servirPrueba(t, &parPrueba{estadoRonda: otro})
+	assert.Nil(t, participante.Congelar("R1", "DNS2"))
+	assert.Nil(t, participante.Preparar("R1", []string{"DNS2", "DNS1", "DNS3"}))
+	resolverRonda(participante.EnCurso())
+	return participante.Estado("R1")
+}
+
+func TestResolverRonda(t *testing.T) {
+	// Una ronda congelada se aborta, su coordinador no pudo confirmarla
+	iniciarPrueba(t)
+	assert.Nil(t, participante.Congelar("R1", "DNS2"))
+	resolverRonda(participante.EnCurso())
+	assert.Equal(t, ronda.ABORTADA, participante.Estado("R1"))
 
+	// Una ronda preparada sigue en curso mientras nadie conozca su resultado
+	iniciarPrueba(t)
+	assert.Equal(t, ronda.PREPARADA, resolverConPares(t, "", ""))
 
+	// Se confirma si otro participante la confirmó
+	iniciarPrueba(t)
+	assert.Equal(t, ronda.CONFIRMADA, resolverConPares(t, "", ronda.CONFIRMADA))
 
+	// Se aborta si el coordinador la abortó o no la conoce
+	iniciarPrueba(t)
+	assert.Equal(t, ronda.ABORTADA, resolverConPares(t, ronda.DESCONOCIDA, ""))
 
-	assert.Equal(t, respuesta.Reloj, []int32{0,0,0})
+	// Un participante que no la conoce no decide su resultado
+	iniciarPrueba(t)
+	assert.Equal(t, ronda.PREPARADA, resolverConPares(t, "", ronda.DESCONOCIDA))
 }
@@ -0,0 +1,42 @@
+package archivos
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Crea un directorio, junto a los que lo contienen, si aún no existe
+func CrearDirectorio(dir string)  error {
+    if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0777); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Escribe un archivo de forma atómica: el contenido se escribe en un archivo temporal
+// dentro del mismo directorio que luego se renombra sobre el archivo final.
+func EscribirArchivoAtomico(ruta string, contenido []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(ruta), "." + filepath.Base(ruta) + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No tiene efecto si el renombrado fue exitoso
+
+	if _, err := tmp.Write(contenido); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), ruta)
+}
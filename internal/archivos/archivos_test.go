@@ -0,0 +1,27 @@
+package archivos
+
+import (
+	"os"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscribirArchivoAtomico(t *testing.T) {
+	dir := t.TempDir() + "/a/b/"
+	assert.Nil(t, CrearDirectorio(dir))
+	assert.Nil(t, CrearDirectorio(dir)) // Crear un directorio existente no tiene efecto
+
+	ruta := dir + "estado"
+	assert.Nil(t, EscribirArchivoAtomico(ruta, []byte("uno")))
+	assert.Nil(t, EscribirArchivoAtomico(ruta, []byte("dos")))
+	contenido, err := os.ReadFile(ruta)
+	assert.Nil(t, err)
+	assert.Equal(t, "dos", string(contenido))
+
+	// No quedan archivos temporales en el directorio
+	entradas, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entradas))
+
+	assert.NotNil(t, EscribirArchivoAtomico(t.TempDir() + "/no/existe", []byte("uno")))
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/relojes"
+	"google.golang.org/protobuf/proto"
+)
+
+// Respuesta guardada en la caché, válida hasta su expiración mientras no se observe un reloj más reciente de su zona
+type entrada struct{
+	respuesta *pb.Respuesta
+	expira time.Time
+}
+
+type zona struct{
+	reloj relojes.Reloj // reloj más reciente observado desde cualquier réplica
+	entradas map[string]*entrada
+}
+
+// Caché de respuestas de Get en el broker. Cada respuesta se guarda con el reloj de vector de su
+// zona y se descarta al expirar el menor TTL de sus registros o al observar, desde cualquier
+// réplica, un reloj de la zona que no sea anterior al suyo. Así la caché nunca entrega una
+// versión más antigua que otra ya entregada por el broker, respetando las lecturas monotónicas.
+type Cache struct{
+	mu sync.Mutex
+	zonas map[string]*zona
+	ttlMaximo time.Duration
+	ahora func() time.Time
+	aciertos uint64
+	fallos uint64
+	invalidadas uint64
+}
+
+// Estado de la caché
+type Metricas struct{
+	Entradas int
+	Aciertos uint64
+	Fallos uint64
+	Invalidadas uint64 // entradas descartadas por observar un reloj más reciente
+}
+
+func (m Metricas) String() string {
+	return fmt.Sprintf("entradas=%d aciertos=%d fallos=%d invalidadas=%d", m.Entradas, m.Aciertos, m.Fallos, m.Invalidadas)
+}
+
+// Crea una caché que guarda cada respuesta a lo más por ttlMaximo
+func NuevaCache(ttlMaximo time.Duration) *Cache {
+	return &Cache{zonas: make(map[string]*zona), ttlMaximo: ttlMaximo, ahora: time.Now}
+}
+
+func clave(nombre string, tipo string) string {
+	return nombre + " " + strings.ToUpper(tipo)
+}
+
+func (c *Cache) obtenerZona(dominio string) *zona {
+	z, ok := c.zonas[dominio]
+	if !ok {
+		z = &zona{entradas: make(map[string]*entrada)}
+		c.zonas[dominio] = z
+	}
+	return z
+}
+
+// Obtiene la respuesta guardada para un nombre de la zona y un tipo, si no ha expirado
+func (c *Cache) Obtener(dominio string, nombre string, tipo string) (*pb.Respuesta, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if z, ok := c.zonas[dominio]; ok {
+		if e, ok := z.entradas[clave(nombre, tipo)]; ok {
+			if c.ahora().Before(e.expira) {
+				c.aciertos += 1
+				return proto.Clone(e.respuesta).(*pb.Respuesta), true
+			}
+			delete(z.entradas, clave(nombre, tipo))
+		}
+	}
+	c.fallos += 1
+	return nil, false
+}
+
+// Registra el reloj de una zona recibido desde cualquier réplica, por ejemplo en una respuesta
+// o tras una escritura, descartando las entradas que ya no son las más recientes
+func (c *Cache) Observar(dominio string, reloj []int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observar(c.obtenerZona(dominio), reloj)
+}
+
+func (c *Cache) observar(z *zona, reloj []int32) {
+	if z.reloj.Incluye(reloj) {
+		return
+	}
+	z.reloj = z.reloj.Fusionar(reloj)
+	for k, e := range z.entradas {
+		if !relojes.Reloj(e.respuesta.Reloj).Incluye(z.reloj) {
+			delete(z.entradas, k)
+			c.invalidadas += 1
+		}
+	}
+}
+
+// Guarda la respuesta a una consulta si es la más reciente observada para su zona. La respuesta
+// expira según el menor TTL de sus registros, sin superar el máximo de la caché.
+func (c *Cache) Guardar(dominio string, nombre string, tipo string, respuesta *pb.Respuesta) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	z := c.obtenerZona(dominio)
+	c.observar(z, respuesta.Reloj)
+	if !relojes.Reloj(respuesta.Reloj).Incluye(z.reloj) {
+		return
+	}
+
+	ttl := c.ttlMaximo
+	for _, rr := range respuesta.Recursos {
+		if d := time.Duration(rr.Ttl) * time.Second; d < ttl {
+			ttl = d
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+	for k, e := range z.entradas { // Se aprovecha de quitar las entradas expiradas de la zona
+		if !c.ahora().Before(e.expira) {
+			delete(z.entradas, k)
+		}
+	}
+	z.entradas[clave(nombre, tipo)] = &entrada{respuesta: proto.Clone(respuesta).(*pb.Respuesta), expira: c.ahora().Add(ttl)}
+}
+
+func (c *Cache) Metricas() Metricas {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := Metricas{Aciertos: c.aciertos, Fallos: c.fallos, Invalidadas: c.invalidadas}
+	for _, z := range c.zonas {
+		m.Entradas += len(z.entradas)
+	}
+	return m
+}
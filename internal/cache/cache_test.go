@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+)
+
+func respuesta(ip string, ttl uint32, reloj ...int32) *pb.Respuesta {
+	return &pb.Respuesta{Respuesta: ip, Reloj: reloj, Recursos: []*pb.Recurso{{Nombre: "www.example.com", Tipo: "A", Ttl: ttl, Valor: ip}}}
+}
+
+func TestExpiracion(t *testing.T) {
+	c := NuevaCache(time.Minute)
+	ahora := time.Now()
+	c.ahora = func() time.Time { return ahora }
+
+	c.Guardar("example.com", "www.example.com", "A", respuesta("1.1.1.1", 30, 1,0,0))
+	r, ok := c.Obtener("example.com", "www.example.com", "A")
+	assert.True(t, ok)
+	assert.Equal(t, "1.1.1.1", r.Respuesta)
+	_, ok = c.Obtener("example.com", "www.example.com", "MX")
+	assert.False(t, ok)
+
+	// La respuesta expira con el menor TTL de sus registros, sin superar el máximo de la caché
+	ahora = ahora.Add(31 * time.Second)
+	_, ok = c.Obtener("example.com", "www.example.com", "A")
+	assert.False(t, ok)
+
+	c.Guardar("example.com", "www.example.com", "A", respuesta("1.1.1.1", 3600, 1,0,0))
+	ahora = ahora.Add(59 * time.Second)
+	_, ok = c.Obtener("example.com", "www.example.com", "A")
+	assert.True(t, ok)
+	ahora = ahora.Add(time.Second)
+	_, ok = c.Obtener("example.com", "www.example.com", "A")
+	assert.False(t, ok)
+
+	// Con TTL 0 no se guarda
+	c.Guardar("example.com", "ftp.example.com", "A", respuesta("2.2.2.2", 0, 1,0,0))
+	_, ok = c.Obtener("example.com", "ftp.example.com", "A")
+	assert.False(t, ok)
+}
+
+func TestRelojes(t *testing.T) {
+	c := NuevaCache(time.Hour)
+	c.Guardar("example.com", "www.example.com", "A", respuesta("1.1.1.1", 300, 1,0,0))
+	c.Guardar("example.com", "mail.example.com", "A", respuesta("3.3.3.3", 300, 1,0,0))
+	c.Guardar("cl", "uchile.cl", "A", respuesta("4.4.4.4", 300, 0,0,1))
+
+	// Un reloj más reciente de la zona, visto desde otra réplica, invalida sus entradas anteriores
+	c.Observar("example.com", []int32{1,1,0})
+	_, ok := c.Obtener("example.com", "www.example.com", "A")
+	assert.False(t, ok)
+	_, ok = c.Obtener("example.com", "mail.example.com", "A")
+	assert.False(t, ok)
+	_, ok = c.Obtener("cl", "uchile.cl", "A")
+	assert.True(t, ok)
+
+	// Una respuesta más antigua o concurrente que la última observada no se guarda
+	c.Guardar("example.com", "www.example.com", "A", respuesta("1.1.1.1", 300, 1,0,0))
+	c.Guardar("example.com", "www.example.com", "A", respuesta("1.1.1.1", 300, 2,0,0))
+	_, ok = c.Obtener("example.com", "www.example.com", "A")
+	assert.False(t, ok)
+
+	// Guardar la respuesta concurrente avanzó el reloj de la zona a la fusión de ambos
+	c.Guardar("example.com", "www.example.com", "A", respuesta("5.5.5.5", 300, 2,1,0))
+	r, ok := c.Obtener("example.com", "www.example.com", "A")
+	assert.True(t, ok)
+	assert.Equal(t, "5.5.5.5", r.Respuesta)
+	assert.Equal(t, 2, c.Metricas().Entradas)
+	assert.Equal(t, uint64(2), c.Metricas().Invalidadas)
+}
@@ -0,0 +1,461 @@
+package cambios
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/jfomu/DNSDistribuido/internal/relojes"
+)
+
+// Las lineas del log de cambios tienen la forma
+//	<tipo> <nombre>.<dominio> [<opcion>] [<valor>] | <ID nodo> <reloj>
+// donde el reloj es el reloj de vector del dominio luego de aplicar el cambio. Un create incluye
+// la linea completa del registro agregado y un delete puede indicar el tipo y los datos de los
+// registros a eliminar, por ejemplo:
+//	create example.com 300 IN MX 10 mail.example.com | DNS1 1,0,0
+//	delete www.example.com A 1.1.1.1 | DNS2 1,1,0
+// Las lineas antiguas que no incluyen el nodo de origen ni el reloj también se aceptan, al igual
+// que los create antiguos de la forma "create google.com 8.8.8.8".
+//
+// Un log compactado comienza con una marca base, sin registro, seguida de una linea base por
+// cada registro del estado compactado, todas con el mismo reloj:
+//	base | DNS1 2,1,0
+//	base www.example.com IN A 1.1.1.1 | DNS1 2,1,0
+const (
+	CREATE = "create"
+	UPDATE = "update"
+	DELETE = "delete"
+	BASE = "base"
+	SEPARADOR = " | "
+)
+
+type Operacion struct{
+	Tipo string
+	NombreDominio string
+	Recurso *recursos.Recurso // create y base: registro agregado
+	Opcion string // update: ip, name o ttl; delete: tipo de los registros a eliminar (opcional)
+	Valor string // update: nuevo valor; delete: datos del registro a eliminar (opcional)
+	Origen string // ID del nodo donde se realizó el cambio
+	Reloj []int32 // reloj del dominio luego del cambio, nil si la linea no lo incluye
+	indice int // posición de la linea dentro del log de origen
+}
+
+// Genera la linea del log de cambios correspondiente a la operación
+func (o *Operacion) Linea() string {
+	var linea string
+	if (o.Tipo == CREATE || o.Tipo == BASE) && o.Recurso != nil {
+		linea = o.Tipo + " " + o.Recurso.Linea()
+	} else if o.Tipo == BASE {
+		linea = o.Tipo
+	} else {
+		campos := []string{o.Tipo, o.NombreDominio}
+		if o.Opcion != "" {
+			campos = append(campos, o.Opcion)
+		}
+		if o.Valor != "" {
+			campos = append(campos, o.Valor)
+		}
+		linea = strings.Join(campos, " ")
+	}
+
+	if o.Origen != "" && o.Reloj != nil {
+		valores := make([]string, len(o.Reloj))
+		for i, v := range o.Reloj {
+			valores[i] = strconv.Itoa(int(v))
+		}
+		linea += SEPARADOR + o.Origen + " " + strings.Join(valores, ",")
+	}
+	return linea
+}
+
+func ParsearLinea(linea string) (*Operacion, error) {
+	op := new(Operacion)
+
+	// Separar la operación de su nodo de origen y reloj. Se utiliza el último separador,
+	// ya que el texto de un registro TXT podría contenerlo.
+	cuerpo := linea
+	if i := strings.LastIndex(linea, SEPARADOR); i >= 0 {
+		cuerpo = linea[:i]
+		marca := strings.Fields(linea[i + len(SEPARADOR):])
+		if len(marca) != 2 {
+			return nil, errors.New("Marca de origen inválida en el log de cambios: " + linea)
+		}
+		op.Origen = marca[0]
+		for _, valor := range strings.Split(marca[1], ",") {
+			v, err := strconv.Atoi(valor)
+			if err != nil {
+				return nil, errors.New("Reloj inválido en el log de cambios: " + linea)
+			}
+			op.Reloj = append(op.Reloj, int32(v))
+		}
+	}
+
+	campos := strings.Fields(cuerpo)
+	if len(campos) == 1 && campos[0] == BASE { // Marca base sin registro
+		op.Tipo = BASE
+		return op, nil
+	}
+	if len(campos) < 2 {
+		return nil, errors.New("Linea inválida en el log de cambios: " + linea)
+	}
+	op.Tipo = campos[0]
+	op.NombreDominio = campos[1]
+
+	switch op.Tipo {
+	case CREATE:
+		var err error
+		if len(campos) == 3 { // Formato antiguo, registro A con el TTL por defecto
+			op.Recurso = &recursos.Recurso{Nombre: op.NombreDominio, TTL: recursos.TTL_POR_DEFECTO, Tipo: recursos.A, Valor: campos[2]}
+		} else if op.Recurso, err = recursos.ParsearLinea(recursos.QuitarCampos(cuerpo, 1)); err != nil {
+			return nil, errors.New("Linea create inválida en el log de cambios: " + linea)
+		}
+	case BASE:
+		var err error
+		if op.Recurso, err = recursos.ParsearLinea(recursos.QuitarCampos(cuerpo, 1)); err != nil {
+			return nil, errors.New("Linea base inválida en el log de cambios: " + linea)
+		}
+	case DELETE:
+		if len(campos) > 2 {
+			op.Opcion = strings.ToUpper(campos[2])
+			if !recursos.TipoValido(op.Opcion) {
+				return nil, errors.New("Linea delete inválida en el log de cambios: " + linea)
+			}
+			op.Valor = recursos.QuitarCampos(cuerpo, 3)
+		}
+	case UPDATE:
+		if len(campos) == 4 && (campos[2] == "ip" || campos[2] == "name" || campos[2] == "ttl") {
+			op.Opcion = campos[2]
+			op.Valor = campos[3]
+		} else if len(campos) == 3 { // Formato antiguo sin opción
+			op.Valor = campos[2]
+			if net.ParseIP(campos[2]) != nil {
+				op.Opcion = "ip"
+			} else {
+				op.Opcion = "name"
+			}
+		} else {
+			return nil, errors.New("Linea update inválida en el log de cambios: " + linea)
+		}
+	default:
+		return nil, errors.New("Operación desconocida en el log de cambios: " + linea)
+	}
+	return op, nil
+}
+
+func sumaReloj(reloj []int32) int32 {
+	var suma int32
+	for _, valor := range reloj {
+		suma += valor
+	}
+	return suma
+}
+
+// Reúne las operaciones de los logs de varios nodos (indexados por ID de nodo) y las ordena
+// para reproducirlas. Las lineas base de un log compactado forman un estado que incluye todas
+// las operaciones cuyo reloj es anterior o igual al suyo, por lo que esas operaciones se
+// descartan y las demás se aplican después de la base, en orden causal (ver ordenCausal).
+// Si hay bases concurrentes se aplican todas, la del nodo de menor ID al final.
+// Las lineas sin reloj se consideran anteriores a todas las demás.
+// Las operaciones repetidas en más de un log se consideran una sola.
+func Fusionar(logs map[string][]string) ([]*Operacion, error) {
+	nodos := make([]string, 0, len(logs))
+	for id := range logs {
+		nodos = append(nodos, id)
+	}
+	sort.Strings(nodos)
+
+	vistas := make(map[string]bool)
+	var bases, operaciones []*Operacion
+	for _, id := range nodos {
+		for i, linea := range logs[id] {
+			if strings.TrimSpace(linea) == "" {
+				continue
+			}
+			op, err := ParsearLinea(linea)
+			if err != nil {
+				return nil, err
+			}
+			if op.Origen == "" { // Linea antigua, se asume que se originó en el nodo que la almacena
+				op.Origen = id
+			}
+			op.indice = i
+
+			clave := op.Linea()
+			if op.Reloj == nil {
+				clave = id + SEPARADOR + clave
+			}
+			if _, ok := vistas[clave]; ok {
+				continue
+			}
+			vistas[clave] = true
+			if op.Tipo == BASE {
+				bases = append(bases, op)
+			} else {
+				operaciones = append(operaciones, op)
+			}
+		}
+	}
+
+	// Una base incluida en otra posterior no aporta cambios
+	var vigentes []*Operacion
+	for _, base := range bases {
+		if !incluida(base, bases) {
+			vigentes = append(vigentes, base)
+		}
+	}
+	sort.SliceStable(vigentes, func(i, j int) bool {
+		a, b := vigentes[i], vigentes[j]
+		if a.Origen != b.Origen {
+			return a.Origen > b.Origen
+		}
+		return relojes.Reloj(a.Reloj).String() < relojes.Reloj(b.Reloj).String()
+	})
+
+	var pendientes []*Operacion
+	for _, op := range operaciones {
+		if !incluida(op, vigentes) {
+			pendientes = append(pendientes, op)
+		}
+	}
+	return append(vigentes, ordenCausal(pendientes)...), nil
+}
+
+// Indica si una operación está incluida en alguna de las bases: si su reloj es anterior al de la
+// base o igual a él. Entre bases de distinto nodo con el mismo reloj se conserva la del nodo de
+// menor ID.
+func incluida(op *Operacion, bases []*Operacion) bool {
+	for _, base := range bases {
+		switch relojes.Reloj(op.Reloj).Comparar(base.Reloj) {
+		case relojes.ANTERIOR:
+			return true
+		case relojes.IGUAL:
+			if op.Tipo != BASE || op.Origen > base.Origen {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Ordena las operaciones de modo que cada una quede después de todas las que ocurrieron antes
+// según sus relojes. Las operaciones de un nodo ocurren en secuencia, por lo que en cada paso
+// se elige entre la primera operación pendiente de cada nodo, considerando solo las que no
+// tienen otra anterior pendiente. Entre esas, que son concurrentes, se elige la del nodo de
+// mayor ID, de modo que la del nodo de menor ID se aplique al final y prevalezca.
+func ordenCausal(operaciones []*Operacion) []*Operacion {
+	secuencias := make(map[string][]*Operacion)
+	var origenes []string
+	for _, op := range operaciones {
+		if _, ok := secuencias[op.Origen]; !ok {
+			origenes = append(origenes, op.Origen)
+		}
+		secuencias[op.Origen] = append(secuencias[op.Origen], op)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(origenes)))
+	for _, secuencia := range secuencias {
+		// La suma del reloj crece con cada operación de una secuencia
+		sort.SliceStable(secuencia, func(i, j int) bool {
+			a, b := secuencia[i], secuencia[j]
+			if sa, sb := sumaReloj(a.Reloj), sumaReloj(b.Reloj); sa != sb {
+				return sa < sb
+			}
+			return a.indice < b.indice
+		})
+	}
+
+	disponible := func(op *Operacion) bool {
+		for _, origen := range origenes {
+			if secuencia := secuencias[origen]; len(secuencia) > 0 && relojes.Reloj(secuencia[0].Reloj).OcurreAntes(op.Reloj) {
+				return false
+			}
+		}
+		return true
+	}
+	orden := make([]*Operacion, 0, len(operaciones))
+	for len(orden) < len(operaciones) {
+		// Siempre hay una disponible, ya que la relación ocurre antes no tiene ciclos
+		for _, origen := range origenes {
+			if secuencia := secuencias[origen]; len(secuencia) > 0 && disponible(secuencia[0]) {
+				orden = append(orden, secuencia[0])
+				secuencias[origen] = secuencia[1:]
+				break
+			}
+		}
+	}
+	return orden
+}
+
+// Aplica en orden las operaciones y retorna las lineas del registro ZF resultante.
+// Cada nombre conserva la posición en que fue creado, un cambio de nombre mantiene la posición.
+func Reproducir(operaciones []*Operacion) []string {
+	conjuntos := make(map[string][]*recursos.Recurso)
+	posiciones := make(map[string]int)
+	var orden []string
+
+	for _, op := range operaciones {
+		posicion, existe := posiciones[op.NombreDominio]
+		switch op.Tipo {
+		case CREATE, BASE:
+			if op.Recurso == nil { // Marca base
+				continue
+			}
+			if !existe {
+				posiciones[op.NombreDominio] = len(orden)
+				orden = append(orden, op.NombreDominio)
+			}
+			conjuntos[op.NombreDominio] = AgregarRecurso(conjuntos[op.NombreDominio], op.Recurso)
+		case DELETE:
+			if !existe {
+				continue
+			}
+			conjuntos[op.NombreDominio] = EliminarRecursos(conjuntos[op.NombreDominio], op.Opcion, op.Valor)
+			if len(conjuntos[op.NombreDominio]) == 0 {
+				orden[posicion] = ""
+				delete(posiciones, op.NombreDominio)
+				delete(conjuntos, op.NombreDominio)
+			}
+		case UPDATE:
+			if !existe {
+				continue
+			}
+			if op.Opcion == "name" {
+				if op.Valor == op.NombreDominio {
+					continue
+				}
+				// Si el nuevo nombre ya existe es reemplazado por el nombre actualizado
+				if otra, ok := posiciones[op.Valor]; ok {
+					orden[otra] = ""
+				}
+				conjuntos[op.Valor] = Renombrar(conjuntos[op.NombreDominio], op.Valor)
+				posiciones[op.Valor] = posicion
+				orden[posicion] = op.Valor
+				delete(conjuntos, op.NombreDominio)
+				delete(posiciones, op.NombreDominio)
+			} else {
+				conjunto, err := Actualizar(conjuntos[op.NombreDominio], op.Opcion, op.Valor)
+				if err == nil {
+					conjuntos[op.NombreDominio] = conjunto
+				}
+			}
+		}
+	}
+
+	registro := []string{}
+	for _, nombre := range orden {
+		for _, r := range conjuntos[nombre] {
+			registro = append(registro, r.Linea())
+		}
+	}
+	return registro
+}
+
+// Agrega un registro al conjunto de registros de un nombre, un registro idéntico a uno
+// existente lo reemplaza (actualizando su TTL)
+func AgregarRecurso(conjunto []*recursos.Recurso, r *recursos.Recurso) []*recursos.Recurso {
+	nuevo := make([]*recursos.Recurso, 0, len(conjunto) + 1)
+	reemplazado := false
+	for _, actual := range conjunto {
+		if actual.Igual(r) {
+			nuevo = append(nuevo, r.Copia())
+			reemplazado = true
+		} else {
+			nuevo = append(nuevo, actual)
+		}
+	}
+	if !reemplazado {
+		nuevo = append(nuevo, r.Copia())
+	}
+	return nuevo
+}
+
+// Quita del conjunto los registros del tipo indicado cuyos datos coinciden. Un tipo vacío
+// quita todos los registros y datos vacíos quitan todos los registros del tipo.
+func EliminarRecursos(conjunto []*recursos.Recurso, tipo string, datos string) []*recursos.Recurso {
+	nuevo := []*recursos.Recurso{}
+	if tipo == "" {
+		return nuevo
+	}
+	for _, r := range conjunto {
+		if r.Tipo == tipo && (datos == "" || r.Datos() == datos) {
+			continue
+		}
+		nuevo = append(nuevo, r)
+	}
+	return nuevo
+}
+
+// Copia el conjunto de registros de un nombre asignándoles un nuevo nombre
+func Renombrar(conjunto []*recursos.Recurso, nombre string) []*recursos.Recurso {
+	nuevo := make([]*recursos.Recurso, len(conjunto))
+	for i, r := range conjunto {
+		nuevo[i] = r.Copia()
+		nuevo[i].Nombre = nombre
+	}
+	return nuevo
+}
+
+// Aplica un update de ip o ttl sobre el conjunto de registros de un nombre. La opción ip reemplaza
+// los registros A (o AAAA, si la dirección es IPv6) por uno con la nueva dirección y la opción
+// ttl cambia el TTL de todos los registros.
+func Actualizar(conjunto []*recursos.Recurso, opcion string, valor string) ([]*recursos.Recurso, error) {
+	if len(conjunto) == 0 {
+		return nil, errors.New("No existen registros que actualizar")
+	}
+	nuevo := []*recursos.Recurso{}
+	switch opcion {
+	case "ip":
+		tipo := recursos.TipoDireccion(valor)
+		direccion := &recursos.Recurso{Nombre: conjunto[0].Nombre, TTL: recursos.TTL_POR_DEFECTO, Tipo: tipo, Valor: valor}
+		posicion := -1
+		for _, r := range conjunto {
+			if r.Tipo != tipo {
+				nuevo = append(nuevo, r)
+			} else if posicion < 0 { // La nueva dirección conserva la posición y el TTL de la primera
+				posicion = len(nuevo)
+				direccion.TTL = r.TTL
+				nuevo = append(nuevo, direccion)
+			}
+		}
+		if posicion < 0 {
+			nuevo = append(nuevo, direccion)
+		}
+	case "ttl":
+		ttl, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			return nil, errors.New("TTL inválido: " + valor)
+		}
+		for _, r := range conjunto {
+			copia := r.Copia()
+			copia.TTL = uint32(ttl)
+			nuevo = append(nuevo, copia)
+		}
+	default:
+		return nil, errors.New("Opción de update desconocida: " + opcion)
+	}
+	return nuevo, nil
+}
+
+// Genera el log de cambios compactado que reemplaza al log luego de confirmar una ronda de
+// coordinación: una marca base con el reloj de la ronda y una linea base por cada linea del
+// registro. Reproducir el log entrega el registro, y al fusionarlo con otros logs se descartan
+// las operaciones que el reloj de la ronda ya incluye.
+func Compactar(registro []string, origen string, reloj []int32) ([]string, error) {
+	marca := &Operacion{Tipo: BASE, Origen: origen, Reloj: reloj}
+	log := []string{marca.Linea()}
+	for _, linea := range registro {
+		if strings.TrimSpace(linea) == "" {
+			continue
+		}
+		r, err := recursos.ParsearLinea(linea)
+		if err != nil {
+			return nil, err
+		}
+		op := &Operacion{Tipo: BASE, NombreDominio: r.Nombre, Recurso: r, Origen: origen, Reloj: reloj}
+		log = append(log, op.Linea())
+	}
+	return log, nil
+}
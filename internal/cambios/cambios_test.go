@@ -0,0 +1,157 @@
+package cambios
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsearLinea(t *testing.T) {
+	op, err := ParsearLinea("update google.com ip 8.8.4.4 | DNS2 1,2,0")
+	assert.Nil(t, err)
+	assert.Equal(t, UPDATE, op.Tipo)
+	assert.Equal(t, "google.com", op.NombreDominio)
+	assert.Equal(t, "ip", op.Opcion)
+	assert.Equal(t, "8.8.4.4", op.Valor)
+	assert.Equal(t, "DNS2", op.Origen)
+	assert.Equal(t, []int32{1,2,0}, op.Reloj)
+	assert.Equal(t, "update google.com ip 8.8.4.4 | DNS2 1,2,0", op.Linea())
+
+	// Formato antiguo, sin marca de origen
+	op, err = ParsearLinea("update google.com mail.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "name", op.Opcion)
+	assert.Nil(t, op.Reloj)
+
+	_, err = ParsearLinea("rename google.com")
+	assert.NotNil(t, err)
+}
+
+func TestFusionarReproducir(t *testing.T) {
+	base, err := Compactar([]string{"google.com IN A 8.8.8.8", "uv.com IN A 1.1.1.1"}, "DNS1", []int32{1,0,0})
+	assert.Nil(t, err)
+	logs := map[string][]string{
+		"DNS1": append(append([]string{}, base...),
+			"update google.com ip 8.8.4.4 | DNS1 2,0,0",
+			"delete uv.com | DNS1 3,0,0",
+		),
+		"DNS2": append(append([]string{}, base...),
+			"update google.com ip 9.9.9.9 | DNS2 1,1,0",
+			"create yahoo.com 2.2.2.2 | DNS2 1,2,0",
+		),
+		"DNS3": {
+			"create bing.com 3.3.3.3 | DNS3 0,0,1",
+		},
+	}
+
+	operaciones, err := Fusionar(logs)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(operaciones)) // La base repetida en DNS1 y DNS2 se aplica una vez
+
+	// Los updates concurrentes de google.com se desempatan por nodo, prevalece el de menor ID
+	registro := Reproducir(operaciones)
+	assert.Equal(t, []string{"google.com IN A 8.8.4.4", "bing.com IN A 3.3.3.3", "yahoo.com IN A 2.2.2.2"}, registro)
+
+	// Reproducir el log compactado entrega el mismo registro
+	compactado, err := Compactar(registro, "DNS1", []int32{3,2,1})
+	assert.Nil(t, err)
+	operaciones, err = Fusionar(map[string][]string{"DNS1": compactado})
+	assert.Nil(t, err)
+	assert.Equal(t, registro, Reproducir(operaciones))
+
+	// Un registro vacío conserva la marca base con su reloj
+	compactado, err = Compactar([]string{}, "DNS1", []int32{3,2,1})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"base | DNS1 3,2,1"}, compactado)
+
+	_, err = Compactar([]string{"google.com IN A"}, "DNS1", []int32{1,0,0})
+	assert.NotNil(t, err)
+}
+
+func TestFusionarBase(t *testing.T) {
+	base, err := Compactar([]string{"www.example.com IN A 1.1.1.1", "example.com IN A 2.2.2.2"}, "DNS1", []int32{2,1,0})
+	assert.Nil(t, err)
+
+	// Un delete concurrente con la base, de un nodo que vio el create, se aplica sobre ella
+	operaciones, err := Fusionar(map[string][]string{
+		"DNS1": base,
+		"DNS3": {
+			"create www.example.com 1.1.1.1 | DNS1 1,0,0",
+			"delete www.example.com | DNS3 1,0,1",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com IN A 2.2.2.2"}, Reproducir(operaciones))
+
+	// Las operaciones que la base ya incluye se descartan, aunque ya no estén en ella
+	operaciones, err = Fusionar(map[string][]string{
+		"DNS1": base,
+		"DNS2": {
+			"create uv.com 3.3.3.3 | DNS2 0,1,0",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"www.example.com IN A 1.1.1.1", "example.com IN A 2.2.2.2"}, Reproducir(operaciones))
+
+	// Una base anterior a otra se descarta
+	anterior, err := Compactar([]string{"uv.com IN A 3.3.3.3"}, "DNS2", []int32{1,1,0})
+	assert.Nil(t, err)
+	operaciones, err = Fusionar(map[string][]string{"DNS1": base, "DNS2": anterior})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"www.example.com IN A 1.1.1.1", "example.com IN A 2.2.2.2"}, Reproducir(operaciones))
+}
+
+func TestFusionarOrdenCausal(t *testing.T) {
+	// El update de DNS1 es concurrente con ambos de DNS2 y prevalece, aunque la suma de su reloj sea menor
+	operaciones, err := Fusionar(map[string][]string{
+		"DNS1": {
+			"create google.com 8.8.8.8 | DNS1 1,0,0",
+			"update google.com ip 1.1.1.1 | DNS1 2,0,0",
+		},
+		"DNS2": {
+			"create google.com 8.8.8.8 | DNS1 1,0,0",
+			"update google.com ip 2.2.2.2 | DNS2 1,1,0",
+			"update google.com ip 3.3.3.3 | DNS2 1,2,0",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"google.com IN A 1.1.1.1"}, Reproducir(operaciones))
+
+	// Lo que ocurre después de un cambio se aplica después, sin importar el ID del nodo
+	operaciones, err = Fusionar(map[string][]string{
+		"DNS2": {
+			"create google.com 8.8.8.8 | DNS1 1,0,0",
+			"update google.com ip 1.1.1.1 | DNS1 2,0,0",
+			"update google.com ip 3.3.3.3 | DNS2 2,1,0",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"google.com IN A 3.3.3.3"}, Reproducir(operaciones))
+}
+
+func TestReproducirUpdateName(t *testing.T) {
+	operaciones, err := Fusionar(map[string][]string{"DNS1": {
+		"create google.com 8.8.8.8 | DNS1 1,0,0",
+		"update google.com name gmail.com | DNS1 2,0,0",
+		"create google.com 1.1.1.1 | DNS1 3,0,0",
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"gmail.com IN A 8.8.8.8", "google.com IN A 1.1.1.1"}, Reproducir(operaciones))
+}
+
+func TestReproducirTipos(t *testing.T) {
+	operaciones, err := Fusionar(map[string][]string{"DNS1": {
+		"create www.example.com 1.1.1.1 | DNS1 1,0,0",
+		"create www.example.com IN A 1.1.1.2 | DNS1 2,0,0",
+		"create example.com 300 IN TXT \"a | b\" | DNS1 3,0,0",
+		"create example.com IN MX 10 mail.example.com | DNS1 4,0,0",
+		"delete www.example.com A 1.1.1.1 | DNS1 5,0,0",
+		"update example.com ttl 60 | DNS1 6,0,0",
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, "a | b", operaciones[2].Recurso.Valor)
+	assert.Equal(t, []string{
+		"www.example.com IN A 1.1.1.2",
+		"example.com 60 IN TXT \"a | b\"",
+		"example.com 60 IN MX 10 mail.example.com",
+	}, Reproducir(operaciones))
+}
@@ -0,0 +1,143 @@
+package conexiones
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	TIEMPO_CONEXION = 2 * time.Second // tiempo mínimo de cada intento de conexión
+	ESPERA_INICIAL = 100 * time.Millisecond // espera antes del primer reintento de conexión
+	ESPERA_MAXIMA = 10 * time.Second // espera máxima entre reintentos de conexión
+)
+
+// Conexiones gRPC compartidas, una por dirección de nodo. Cada conexión se reutiliza entre
+// solicitudes y, si se pierde, gRPC la restablece en segundo plano con espera exponencial
+// entre intentos; mientras tanto las solicitudes fallan de inmediato con Unavailable.
+type Pool struct{
+	mu sync.Mutex
+	conexiones map[string]*grpc.ClientConn
+	creadas uint64
+	reutilizadas uint64
+	cerradas uint64
+}
+
+// Estado del pool
+type Metricas struct{
+	Conexiones int
+	PorEstado map[string]int // conexiones por estado: IDLE, CONNECTING, READY, TRANSIENT_FAILURE
+	Creadas uint64
+	Reutilizadas uint64
+	Cerradas uint64
+}
+
+func (m Metricas) String() string {
+	var estados []string
+	for estado, cantidad := range m.PorEstado {
+		estados = append(estados, fmt.Sprintf("%s=%d", estado, cantidad))
+	}
+	sort.Strings(estados)
+	return fmt.Sprintf("conexiones=%d [%s] creadas=%d reutilizadas=%d cerradas=%d",
+		m.Conexiones, strings.Join(estados, " "), m.Creadas, m.Reutilizadas, m.Cerradas)
+}
+
+func NuevoPool() *Pool {
+	return &Pool{conexiones: make(map[string]*grpc.ClientConn)}
+}
+
+func direccion(ip string, port string) string {
+	return ip + ":" + port
+}
+
+func conectar(host string) (*grpc.ClientConn, error) {
+	keepConf := keepalive.ClientParameters{
+		Time:					10 * time.Second,
+		Timeout:				2 * time.Second,
+		PermitWithoutStream:	true,
+	}
+	espera := backoff.DefaultConfig
+	espera.BaseDelay = ESPERA_INICIAL
+	espera.MaxDelay = ESPERA_MAXIMA
+	return grpc.Dial(host, grpc.WithInsecure(), grpc.WithKeepaliveParams(keepConf),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: espera, MinConnectTimeout: TIEMPO_CONEXION}))
+}
+
+// Obtiene la conexión con un nodo, creándola si no existe. La conexión pertenece al pool y no
+// debe cerrarse.
+func (p *Pool) Obtener(ip string, port string) (*grpc.ClientConn, error) {
+	host := direccion(ip, port)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if conn, ok := p.conexiones[host]; ok && conn.GetState() != connectivity.Shutdown {
+		p.reutilizadas += 1
+		return conn, nil
+	}
+
+	log.Printf("Iniciando conexión con %s", host)
+	conn, err := conectar(host)
+	if err != nil {
+		return nil, err
+	}
+	p.conexiones[host] = conn
+	p.creadas += 1
+	return conn, nil
+}
+
+// Cierra las conexiones con los nodos que ya no están en la lista indicada
+func (p *Pool) Sincronizar(nodos []config.NodeInfo) {
+	vigentes := make(map[string]bool)
+	for _, n := range nodos {
+		vigentes[direccion(n.Ip, n.Port)] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for host, conn := range p.conexiones {
+		if !vigentes[host] {
+			log.Printf("Cerrando conexión con %s, el nodo ya no forma parte del clúster\n", host)
+			p.cerrar(host, conn)
+		}
+	}
+}
+
+// Cierra todas las conexiones
+func (p *Pool) Cerrar() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for host, conn := range p.conexiones {
+		p.cerrar(host, conn)
+	}
+}
+
+func (p *Pool) cerrar(host string, conn *grpc.ClientConn) {
+	conn.Close()
+	delete(p.conexiones, host)
+	p.cerradas += 1
+}
+
+func (p *Pool) Metricas() Metricas {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := Metricas{
+		Conexiones: len(p.conexiones),
+		PorEstado: make(map[string]int),
+		Creadas: p.creadas,
+		Reutilizadas: p.reutilizadas,
+		Cerradas: p.cerradas,
+	}
+	for _, conn := range p.conexiones {
+		m.PorEstado[conn.GetState().String()] += 1
+	}
+	return m
+}
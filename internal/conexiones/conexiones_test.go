@@ -0,0 +1,41 @@
+package conexiones
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestPool(t *testing.T) {
+	p := NuevoPool()
+	t.Cleanup(p.Cerrar)
+
+	// Las conexiones se crean sin esperar al nodo y se reutilizan por dirección
+	a, err := p.Obtener("127.0.0.1", "9001")
+	assert.Nil(t, err)
+	b, err := p.Obtener("127.0.0.1", "9002")
+	assert.Nil(t, err)
+	otra, err := p.Obtener("127.0.0.1", "9001")
+	assert.Nil(t, err)
+	assert.Same(t, a, otra)
+
+	m := p.Metricas()
+	assert.Equal(t, 2, m.Conexiones)
+	assert.Equal(t, uint64(2), m.Creadas)
+	assert.Equal(t, uint64(1), m.Reutilizadas)
+
+	// Al quitar un nodo de la configuración se cierra su conexión
+	p.Sincronizar([]config.NodeInfo{{Id: "DNS2", Ip: "127.0.0.1", Port: "9002"}})
+	assert.Equal(t, connectivity.Shutdown, a.GetState())
+	assert.NotEqual(t, connectivity.Shutdown, b.GetState())
+	m = p.Metricas()
+	assert.Equal(t, 1, m.Conexiones)
+	assert.Equal(t, uint64(1), m.Cerradas)
+
+	// Si el nodo vuelve a la configuración se crea una nueva conexión
+	nueva, err := p.Obtener("127.0.0.1", "9001")
+	assert.Nil(t, err)
+	assert.NotSame(t, a, nueva)
+	assert.Equal(t, uint64(3), p.Metricas().Creadas)
+}
@@ -1,30 +1,99 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"encoding/json"
 	"io/ioutil"
+	"sort"
 )
 
 type NodeInfo struct {
 	Id   string `json:"id"`
 	Ip   string `json:"ip"`
 	Port string `json:"port"`
+	DnsPort string `json:"dnsPort"` // puerto UDP/TCP para consultas DNS estándar, opcional
 }
 
 type Config struct {
 	DNS []NodeInfo `json:"DNS"`
 	Broker NodeInfo   `json:"Broker"`
+	Zonas []string `json:"Zonas"` // zonas conocidas, opcional (por defecto la zona de un nombre es su dominio padre)
+	Estrategia string `json:"Estrategia"` // selección de servidores DNS en el broker: aleatoria (por defecto), round-robin, menos-pendientes o hash-consistente
+	CacheTTL int `json:"CacheTTL"` // segundos que el broker guarda como máximo una respuesta en caché, opcional (0 desactiva la caché)
+	Consistencia map[string]string `json:"Consistencia"` // modo de consistencia de cada zona: eventual (por defecto) o fuerte
+}
+
+// Modos de consistencia de una zona
+const (
+	EVENTUAL = "eventual" // escrituras locales, fusionadas en las rondas de consistencia
+	FUERTE = "fuerte" // escrituras replicadas con Raft entre los servidores DNS de la configuración
+	STRONG = "strong" // también se acepta para FUERTE, se reemplaza al leer la configuración
+)
+
+// Indica si una zona se replica con consistencia fuerte
+func (c *Config) Fuerte(zona string) bool {
+	return c.Consistencia[zona] == FUERTE
+}
+
+// Zonas de consistencia fuerte, ordenadas
+func (c *Config) ZonasFuertes() []string {
+	var zonas []string
+	for zona := range c.Consistencia {
+		if c.Fuerte(zona) {
+			zonas = append(zonas, zona)
+		}
+	}
+	sort.Strings(zonas)
+	return zonas
+}
+
+// Verifica que los modos de consistencia de las zonas sean conocidos
+func (c *Config) ValidarConsistencia() error {
+	for zona, modo := range c.Consistencia {
+		if modo != EVENTUAL && modo != FUERTE {
+			return errors.New("Modo de consistencia desconocido para la zona " + zona + ": " + modo + ", debe ser eventual o fuerte")
+		}
+	}
+	return nil
+}
+
+// IDs de los servidores DNS en el orden de la configuración, que da su posición en los relojes
+// de vector. Los nodos nuevos deben agregarse al final para no cambiar la posición de los demás.
+func (c *Config) IdsDNS() []string {
+	ids := make([]string, len(c.DNS))
+	for i, n := range c.DNS {
+		ids[i] = n.Id
+	}
+	return ids
 }
 
 func GenConfig(file string) *Config{
+	conf, err := LeerConfig(file)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return conf
+}
+
+// Lee el archivo de configuración retornando el error en lugar de terminar el programa. La
+// configuración se lee una sola vez al iniciar, los nodos que se agregan o retiran después lo
+// hacen mediante Join y Leave.
+func LeerConfig(file string) (*Config, error) {
     configFile, err := ioutil.ReadFile(file)
     if err != nil {
-		log.Fatalf(err.Error())
+		return nil, err
 	}
 
 	conf:= new(Config)
-	json.Unmarshal(configFile, &conf)
+	if err := json.Unmarshal(configFile, &conf); err != nil {
+		return nil, err
+	}
+	for zona, modo := range conf.Consistencia {
+		if modo == STRONG {
+			conf.Consistencia[zona] = FUERTE
+		}
+	}
 
-	return conf
-}
\ No newline at end of file
+	return conf, nil
+}
@@ -0,0 +1,181 @@
+package deteccion
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+)
+
+// Estados de un nodo según la detección de fallas
+const (
+	VIVO = "VIVO"
+	SOSPECHOSO = "SOSPECHOSO" // no se reciben sus latidos desde hace un tiempo, aún se le envían solicitudes
+	MUERTO = "MUERTO" // se omite en las rondas de coordinación hasta que vuelva a latir
+)
+
+// Estado de un nodo del clúster según los latidos recibidos
+type Par struct{
+	config.NodeInfo
+	Generacion int64 // inicio del nodo, un nodo reiniciado tiene una generación mayor
+	Contador uint64 // latidos del nodo en su generación
+	Estado string
+	Visto time.Time // última vez que aumentó su latido
+}
+
+// Cambio de estado de un nodo detectado al recibir latidos o al revisar los plazos
+type Cambio struct{
+	Par
+	Anterior string
+}
+
+// Detector de fallas por gossip de latidos: cada nodo incrementa periódicamente su propio latido
+// y lo intercambia, junto con los últimos latidos que conoce de los demás, con algunos nodos
+// elegidos al azar. Un nodo cuyo latido no aumenta en tiempoSospecha pasa a SOSPECHOSO y en
+// tiempoMuerte a MUERTO; basta que otro nodo haya recibido un latido más reciente para que vuelva
+// a estar VIVO, de modo que la falla de un enlace entre dos nodos no se confunde con la de un nodo.
+type Detector struct{
+	mu sync.Mutex // protege los campos siguientes
+	local *Par
+	pares map[string]*Par
+	tiempoSospecha time.Duration
+	tiempoMuerte time.Duration
+	ahora func() time.Time
+}
+
+func NuevoDetector(local config.NodeInfo, tiempoSospecha time.Duration, tiempoMuerte time.Duration) *Detector {
+	d := &Detector{pares: make(map[string]*Par), tiempoSospecha: tiempoSospecha, tiempoMuerte: tiempoMuerte, ahora: time.Now}
+	d.local = &Par{NodeInfo: local, Generacion: time.Now().UnixNano(), Estado: VIVO, Visto: d.ahora()}
+	return d
+}
+
+// Reemplaza los nodos vigilados por los miembros indicados, excluido el local. Los nodos que se
+// mantienen conservan sus latidos; los nuevos se consideran vivos desde este momento.
+func (d *Detector) ActualizarMiembros(nodos []config.NodeInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pares := make(map[string]*Par)
+	for _, n := range nodos {
+		if n.Id == d.local.Id {
+			continue
+		}
+		if p, ok := d.pares[n.Id]; ok && p.Ip == n.Ip && p.Port == n.Port {
+			p.NodeInfo = n
+			pares[n.Id] = p
+		} else {
+			pares[n.Id] = &Par{NodeInfo: n, Estado: VIVO, Visto: d.ahora()}
+		}
+	}
+	d.pares = pares
+}
+
+// Incrementa el latido del nodo local
+func (d *Detector) Latir() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.local.Contador += 1
+	d.local.Visto = d.ahora()
+}
+
+// Latidos conocidos, incluido el local, para enviarlos a otro nodo o informar el estado del clúster
+func (d *Detector) Latidos() *pb.Latidos {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	latidos := &pb.Latidos{IdNodo: d.local.Id, Latidos: []*pb.Latido{d.latido(d.local)}}
+	for _, id := range d.ids() {
+		latidos.Latidos = append(latidos.Latidos, d.latido(d.pares[id]))
+	}
+	return latidos
+}
+
+func (d *Detector) latido(p *Par) *pb.Latido {
+	return &pb.Latido{
+		Id: p.Id, Ip: p.Ip, Port: p.Port,
+		Generacion: p.Generacion, Contador: p.Contador, Estado: p.Estado,
+		Segundos: int64(d.ahora().Sub(p.Visto) / time.Second),
+	}
+}
+
+// Incorpora los latidos recibidos de otro nodo, considerando solo los nodos vigilados. Retorna
+// los cambios de estado, por ejemplo los nodos que se recuperan.
+func (d *Detector) Fusionar(latidos *pb.Latidos) []Cambio {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var cambios []Cambio
+	for _, l := range latidos.Latidos {
+		p, ok := d.pares[l.Id]
+		if !ok || !masReciente(l, p) {
+			continue
+		}
+		p.Generacion, p.Contador, p.Visto = l.Generacion, l.Contador, d.ahora()
+		if anterior := p.Estado; anterior != VIVO {
+			p.Estado = VIVO
+			cambios = append(cambios, Cambio{Par: *p, Anterior: anterior})
+		}
+	}
+	return cambios
+}
+
+func masReciente(l *pb.Latido, p *Par) bool {
+	return l.Generacion > p.Generacion || (l.Generacion == p.Generacion && l.Contador > p.Contador)
+}
+
+// Marca como sospechosos o muertos los nodos cuyo latido no aumenta en los plazos, y retorna los cambios
+func (d *Detector) Revisar() []Cambio {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var cambios []Cambio
+	for _, id := range d.ids() {
+		p := d.pares[id]
+		estado := VIVO
+		if espera := d.ahora().Sub(p.Visto); espera >= d.tiempoMuerte {
+			estado = MUERTO
+		} else if espera >= d.tiempoSospecha {
+			estado = SOSPECHOSO
+		}
+		if anterior := p.Estado; estado != anterior {
+			p.Estado = estado
+			cambios = append(cambios, Cambio{Par: *p, Anterior: anterior})
+		}
+	}
+	return cambios
+}
+
+// Elige al azar hasta k nodos vigilados para intercambiar latidos. Se incluyen los muertos, de
+// modo que un nodo recuperado se detecta aunque los demás no lo contacten.
+func (d *Detector) Elegir(k int) []config.NodeInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ids := d.ids()
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	if len(ids) > k {
+		ids = ids[:k]
+	}
+	nodos := make([]config.NodeInfo, len(ids))
+	for i, id := range ids {
+		nodos[i] = d.pares[id].NodeInfo
+	}
+	return nodos
+}
+
+// Estado de un nodo vigilado; el nodo local y los desconocidos se consideran vivos
+func (d *Detector) Estado(id string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p, ok := d.pares[id]; ok {
+		return p.Estado
+	}
+	return VIVO
+}
+
+func (d *Detector) ids() []string {
+	ids := make([]string, 0, len(d.pares))
+	for id := range d.pares {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
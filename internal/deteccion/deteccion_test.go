@@ -0,0 +1,59 @@
+package deteccion
+
+import (
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+)
+
+func TestDetector(t *testing.T) {
+	nodos := []config.NodeInfo{
+		{Id: "DNS1", Ip: "127.0.0.1", Port: "9001"},
+		{Id: "DNS2", Ip: "127.0.0.1", Port: "9002"},
+		{Id: "DNS3", Ip: "127.0.0.1", Port: "9003"},
+	}
+	ahora := time.Now()
+	d := NuevoDetector(nodos[0], 5 * time.Second, 15 * time.Second)
+	d.ahora = func() time.Time { return ahora }
+	d.ActualizarMiembros(nodos)
+	assert.Equal(t, 2, len(d.Elegir(5)))
+	assert.Equal(t, 1, len(d.Elegir(1)))
+
+	// DNS2 late a través de DNS3, mientras que DNS3 deja de latir
+	latido := func(id string, generacion int64, contador uint64) *pb.Latidos {
+		return &pb.Latidos{IdNodo: "DNS3", Latidos: []*pb.Latido{{Id: id, Generacion: generacion, Contador: contador}}}
+	}
+	ahora = ahora.Add(6 * time.Second)
+	assert.Empty(t, d.Fusionar(latido("DNS2", 1, 1)))
+	cambios := d.Revisar()
+	assert.Equal(t, 1, len(cambios))
+	assert.Equal(t, "DNS3", cambios[0].Id)
+	assert.Equal(t, SOSPECHOSO, d.Estado("DNS3"))
+	assert.Equal(t, VIVO, d.Estado("DNS2"))
+
+	ahora = ahora.Add(10 * time.Second)
+	d.Revisar()
+	assert.Equal(t, MUERTO, d.Estado("DNS3"))
+	assert.Equal(t, SOSPECHOSO, d.Estado("DNS2"))
+
+	// Un latido repetido no recupera al nodo, uno más reciente o de una nueva generación sí
+	assert.Empty(t, d.Fusionar(latido("DNS2", 1, 1)))
+	cambios = d.Fusionar(latido("DNS3", 2, 0))
+	assert.Equal(t, 1, len(cambios))
+	assert.Equal(t, MUERTO, cambios[0].Anterior)
+	assert.Equal(t, VIVO, d.Estado("DNS3"))
+	assert.Empty(t, d.Fusionar(latido("DNS3", 1, 50)))
+
+	// Los latidos informados incluyen el local; los nodos que dejan de ser miembros se olvidan
+	d.Latir()
+	latidos := d.Latidos()
+	assert.Equal(t, "DNS1", latidos.IdNodo)
+	assert.Equal(t, 3, len(latidos.Latidos))
+	assert.Equal(t, uint64(1), latidos.Latidos[0].Contador)
+	assert.Equal(t, int64(10), latidos.Latidos[1].Segundos)
+	d.ActualizarMiembros(nodos[:2])
+	assert.Equal(t, 2, len(d.Latidos().Latidos))
+	assert.Equal(t, SOSPECHOSO, d.Estado("DNS2"))
+}
@@ -0,0 +1,168 @@
+package eleccion
+
+import (
+	"sync"
+	"time"
+)
+
+// Elección del nodo coordinador de las rondas de consistencia, basada en términos como en Raft.
+// Un candidato incrementa el término y pide el voto de los demás miembros; cada nodo vota a lo más
+// por un candidato en cada término. Con la mayoría de los votos el candidato asume el liderazgo
+// por un tiempo (lease) que renueva mientras la mayoría lo acepte. Un nodo que acepta a un líder
+// no vota por otro candidato mientras su liderazgo esté vigente, y el líder mide su lease desde
+// antes de pedir los votos o la renovación, por lo que este vence antes que en los demás nodos:
+// así no hay dos líderes vigentes a la vez.
+type Eleccion struct{
+	mu sync.Mutex // protege los campos siguientes
+	id string
+	termino uint64
+	voto string // candidato votado en el término actual
+	lider string
+	expira time.Time // fin del liderazgo vigente
+	rondaEnCurso bool // el líder informó que está coordinando una ronda, o este nodo la coordina
+	espera time.Time // el nodo no se postula antes de este momento
+	duracion time.Duration
+	ahora func() time.Time
+}
+
+// Al iniciar, el nodo espera la mitad del lease antes de postularse, de modo que conozca al líder
+// vigente por sus renovaciones en lugar de interrumpirlo con un término mayor
+func NuevaEleccion(id string, duracion time.Duration) *Eleccion {
+	return &Eleccion{id: id, duracion: duracion, ahora: time.Now, espera: time.Now().Add(duracion / 2)}
+}
+
+func (e *Eleccion) vigente() bool {
+	return e.lider != "" && e.ahora().Before(e.expira)
+}
+
+// Líder vigente y su término
+func (e *Eleccion) Lider() (string, uint64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.vigente() {
+		return "", e.termino, false
+	}
+	return e.lider, e.termino, true
+}
+
+// Indica si el nodo puede postularse: no hay un líder vigente y ya terminó su espera
+func (e *Eleccion) PuedePostularse() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.vigente() && !e.ahora().Before(e.espera)
+}
+
+func (e *Eleccion) EsLider() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.vigente() && e.lider == e.id
+}
+
+// Inicia una candidatura en un nuevo término, votando por sí mismo, y retorna el término
+func (e *Eleccion) Postular() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.termino += 1
+	e.voto = e.id
+	e.lider = ""
+	return e.termino
+}
+
+// Responde una solicitud de voto, retornando si se concede y el término actual
+func (e *Eleccion) Votar(termino uint64, candidato string) (bool, uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.vigente() && e.lider != candidato {
+		return false, e.termino
+	}
+	if termino < e.termino {
+		return false, e.termino
+	}
+	if termino > e.termino {
+		e.termino = termino
+		e.voto = ""
+	}
+	if e.voto != "" && e.voto != candidato {
+		return false, e.termino
+	}
+	// Tras votar por otro candidato espera la mitad del lease, dándole tiempo de asumir y renovar
+	e.voto = candidato
+	if candidato != e.id {
+		e.espera = e.ahora().Add(e.duracion / 2)
+	}
+	return true, e.termino
+}
+
+// Asume el liderazgo tras obtener la mayoría de los votos, con un lease medido desde el inicio de
+// la candidatura. Retorna false si el término cambió entretanto, y si el líder anterior no terminó
+// su ronda de coordinación.
+func (e *Eleccion) Asumir(termino uint64, inicio time.Time) (bool, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.termino != termino || e.voto != e.id {
+		return false, false
+	}
+	e.lider = e.id
+	e.expira = inicio.Add(e.duracion)
+	pendiente := e.rondaEnCurso
+	e.rondaEnCurso = false
+	return true, pendiente
+}
+
+// Extiende el lease del líder tras ser aceptado por la mayoría, medido desde el inicio de la renovación
+func (e *Eleccion) Renovar(termino uint64, inicio time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.termino != termino || e.lider != e.id {
+		return false
+	}
+	if expira := inicio.Add(e.duracion); expira.After(e.expira) {
+		e.expira = expira
+	}
+	return true
+}
+
+// Responde la renovación del liderazgo de otro nodo, retornando si lo acepta y el término actual
+func (e *Eleccion) Aceptar(termino uint64, lider string, rondaEnCurso bool) (bool, uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if termino < e.termino || (termino == e.termino && e.vigente() && e.lider != lider) {
+		return false, e.termino
+	}
+	if termino > e.termino {
+		e.voto = ""
+	}
+	e.termino = termino
+	e.lider = lider
+	e.expira = e.ahora().Add(e.duracion)
+	e.rondaEnCurso = rondaEnCurso
+	return true, e.termino
+}
+
+// Adopta un término mayor informado por otro nodo, dejando el liderazgo si este nodo era el líder
+func (e *Eleccion) Actualizar(termino uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if termino <= e.termino {
+		return
+	}
+	e.termino = termino
+	e.voto = ""
+	if e.lider == e.id {
+		e.lider = ""
+		e.expira = time.Time{}
+	}
+}
+
+// Registra el inicio o término de una ronda de coordinación de este nodo, que informa al renovar
+func (e *Eleccion) MarcarRonda(enCurso bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rondaEnCurso = enCurso
+}
+
+func (e *Eleccion) RondaEnCurso() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.rondaEnCurso
+}
@@ -0,0 +1,69 @@
+package eleccion
+
+import (
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEleccion(t *testing.T) {
+	ahora := time.Now()
+	reloj := func() time.Time { return ahora }
+	a, b, c := NuevaEleccion("DNS1", 10 * time.Second), NuevaEleccion("DNS2", 10 * time.Second), NuevaEleccion("DNS3", 10 * time.Second)
+	a.ahora, b.ahora, c.ahora = reloj, reloj, reloj
+	assert.False(t, a.PuedePostularse()) // Al iniciar espera conocer al líder vigente
+	ahora = ahora.Add(5 * time.Second)
+
+	// DNS1 gana el término 1 con el voto de DNS2; DNS2 no vota por otro candidato en ese término
+	termino := a.Postular()
+	concedido, _ := b.Votar(termino, "DNS1")
+	assert.True(t, concedido)
+	concedido, _ = b.Votar(termino, "DNS3")
+	assert.False(t, concedido)
+	asumido, pendiente := a.Asumir(termino, ahora)
+	assert.True(t, asumido)
+	assert.False(t, pendiente)
+	assert.True(t, a.EsLider())
+
+	// Los seguidores aceptan al líder y no votan por otro candidato mientras su liderazgo esté vigente
+	a.MarcarRonda(true)
+	aceptado, _ := c.Aceptar(termino, "DNS1", a.RondaEnCurso())
+	assert.True(t, aceptado)
+	concedido, actual := c.Votar(termino + 1, "DNS2")
+	assert.False(t, concedido)
+	lider, _, ok := b.Lider()
+	assert.False(t, ok) // DNS2 aún no recibe una renovación
+	assert.Equal(t, "", lider)
+
+	// El líder cae a mitad de una ronda: al vencer su lease se elige otro, que retoma la ronda
+	ahora = ahora.Add(11 * time.Second)
+	assert.False(t, a.EsLider())
+	termino = c.Postular()
+	assert.True(t, termino > actual)
+	concedido, _ = b.Votar(termino, "DNS3")
+	assert.True(t, concedido)
+	assert.False(t, b.PuedePostularse())
+	asumido, pendiente = c.Asumir(termino, ahora)
+	assert.True(t, asumido)
+	assert.True(t, pendiente)
+
+	// El líder anterior deja el liderazgo al conocer el nuevo término
+	aceptado, actual = b.Aceptar(termino - 1, "DNS1", false)
+	assert.False(t, aceptado)
+	a.Actualizar(actual)
+	aceptado, _ = a.Aceptar(termino, "DNS3", false)
+	assert.True(t, aceptado)
+	lider, _, ok = a.Lider()
+	assert.True(t, ok)
+	assert.Equal(t, "DNS3", lider)
+	assert.False(t, a.Renovar(termino, ahora))
+	assert.True(t, c.Renovar(termino, ahora))
+	ahora = ahora.Add(11 * time.Second)
+	assert.True(t, b.PuedePostularse())
+
+	// Un líder vencido no impide aceptar al elegido en un término posterior
+	concedido, _ = a.Votar(termino + 1, "DNS2")
+	assert.True(t, concedido)
+	aceptado, _ = a.Aceptar(termino + 1, "DNS2", false)
+	assert.True(t, aceptado)
+}
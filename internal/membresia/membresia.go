@@ -0,0 +1,180 @@
+package membresia
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jfomu/DNSDistribuido/internal/archivos"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+)
+
+// Estados de un miembro del clúster
+const (
+	UNIENDOSE = "UNIENDOSE" // recibe el estado de las zonas, aún no atiende consultas del broker
+	ACTIVO = "ACTIVO"
+	SALIENDO = "SALIENDO" // termina las solicitudes en curso y entrega su estado antes de retirarse
+	RETIRADO = "RETIRADO" // se mantiene en la vista para conservar su posición en los relojes
+)
+
+type Miembro struct{
+	config.NodeInfo
+	Estado string `json:"estado"`
+}
+
+// Vista de la membresía del clúster de servidores DNS. Cada cambio genera una nueva vista con
+// una versión mayor, de modo que los nodos adoptan siempre la más reciente. El orden de los
+// miembros da su posición en los relojes de vector: los nuevos miembros se agregan al final y
+// los retirados se mantienen, por lo que la posición de un nodo nunca cambia.
+// Una vista no se modifica, los cambios retornan una copia.
+type Vista struct{
+	Version uint64 `json:"version"`
+	Miembros []Miembro `json:"miembros"`
+}
+
+// Vista inicial con los servidores DNS de la configuración, todos activos
+func DesdeConfig(nodos []config.NodeInfo) *Vista {
+	v := &Vista{Version: 1}
+	for _, n := range nodos {
+		v.Miembros = append(v.Miembros, Miembro{NodeInfo: n, Estado: ACTIVO})
+	}
+	return v
+}
+
+func (v *Vista) copia() *Vista {
+	return &Vista{Version: v.Version, Miembros: append([]Miembro{}, v.Miembros...)}
+}
+
+// Posición de un miembro en la vista, que es también su posición en los relojes de vector
+func (v *Vista) Buscar(id string) (int, bool) {
+	for i, m := range v.Miembros {
+		if m.Id == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// IDs de todos los miembros en orden, incluidos los retirados
+func (v *Vista) Ids() []string {
+	ids := make([]string, len(v.Miembros))
+	for i, m := range v.Miembros {
+		ids[i] = m.Id
+	}
+	return ids
+}
+
+// Nodos cuyo estado es alguno de los indicados
+func (v *Vista) Nodos(estados ...string) []config.NodeInfo {
+	var nodos []config.NodeInfo
+	for _, m := range v.Miembros {
+		for _, estado := range estados {
+			if m.Estado == estado {
+				nodos = append(nodos, m.NodeInfo)
+				break
+			}
+		}
+	}
+	return nodos
+}
+
+// Nodos que forman parte del clúster, es decir que no se han retirado
+func (v *Vista) Vigentes() []config.NodeInfo {
+	return v.Nodos(UNIENDOSE, ACTIVO, SALIENDO)
+}
+
+// Agrega un nodo a la vista en estado UNIENDOSE. Un nodo que ya fue miembro, por ejemplo tras
+// reiniciarse, conserva su posición y se actualiza su dirección.
+func (v *Vista) Unir(n config.NodeInfo) (*Vista, error) {
+	if n.Id == "" || n.Ip == "" || n.Port == "" {
+		return nil, errors.New("Se debe indicar el ID, la IP y el puerto del nodo")
+	}
+	for _, m := range v.Miembros {
+		if m.Id != n.Id && m.Estado != RETIRADO && m.Ip == n.Ip && m.Port == n.Port {
+			return nil, errors.New("La dirección " + n.Ip + ":" + n.Port + " ya corresponde al nodo " + m.Id)
+		}
+	}
+
+	nueva := v.copia()
+	nueva.Version += 1
+	if i, ok := nueva.Buscar(n.Id); ok {
+		nueva.Miembros[i] = Miembro{NodeInfo: n, Estado: UNIENDOSE}
+	} else {
+		nueva.Miembros = append(nueva.Miembros, Miembro{NodeInfo: n, Estado: UNIENDOSE})
+	}
+	return nueva, nil
+}
+
+// Cambia el estado de un miembro
+func (v *Vista) CambiarEstado(id string, estado string) (*Vista, error) {
+	i, ok := v.Buscar(id)
+	if !ok {
+		return nil, errors.New("El nodo " + id + " no es miembro del clúster")
+	}
+	nueva := v.copia()
+	nueva.Version += 1
+	nueva.Miembros[i].Estado = estado
+	return nueva, nil
+}
+
+func (v *Vista) String() string {
+	miembros := make([]string, len(v.Miembros))
+	for i, m := range v.Miembros {
+		miembros[i] = fmt.Sprintf("%s(%s:%s %s)", m.Id, m.Ip, m.Port, m.Estado)
+	}
+	return fmt.Sprintf("v%d [%s]", v.Version, strings.Join(miembros, " "))
+}
+
+
+//// CONVERSIÓN Y PERSISTENCIA
+
+func MiembroProto(n config.NodeInfo, estado string) *pb.Miembro {
+	return &pb.Miembro{Id: n.Id, Ip: n.Ip, Port: n.Port, DnsPort: n.DnsPort, Estado: estado}
+}
+
+func MiembroDesdeProto(m *pb.Miembro) config.NodeInfo {
+	return config.NodeInfo{Id: m.Id, Ip: m.Ip, Port: m.Port, DnsPort: m.DnsPort}
+}
+
+func (v *Vista) Proto() *pb.Vista {
+	vista := &pb.Vista{Version: v.Version}
+	for _, m := range v.Miembros {
+		vista.Miembros = append(vista.Miembros, MiembroProto(m.NodeInfo, m.Estado))
+	}
+	return vista
+}
+
+func DesdeProto(vista *pb.Vista) *Vista {
+	v := &Vista{Version: vista.Version}
+	for _, m := range vista.Miembros {
+		v.Miembros = append(v.Miembros, Miembro{NodeInfo: MiembroDesdeProto(m), Estado: m.Estado})
+	}
+	return v
+}
+
+// Lee una vista persistida, nil si el archivo no existe
+func Leer(ruta string) (*Vista, error) {
+	contenido, err := os.ReadFile(ruta)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v := new(Vista)
+	if err := json.Unmarshal(contenido, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *Vista) Escribir(ruta string) error {
+	contenido, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+	return archivos.EscribirArchivoAtomico(ruta, contenido)
+}
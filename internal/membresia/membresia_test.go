@@ -0,0 +1,54 @@
+package membresia
+
+import (
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+)
+
+func TestVista(t *testing.T) {
+	v := DesdeConfig([]config.NodeInfo{
+		{Id: "DNS1", Ip: "127.0.0.1", Port: "9001"},
+		{Id: "DNS2", Ip: "127.0.0.1", Port: "9002"},
+	})
+	assert.Equal(t, uint64(1), v.Version)
+
+	// Un nodo nuevo se agrega al final, sin cambiar la vista original
+	nueva, err := v.Unir(config.NodeInfo{Id: "DNS10", Ip: "127.0.0.1", Port: "9010"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), nueva.Version)
+	assert.Equal(t, []string{"DNS1", "DNS2", "DNS10"}, nueva.Ids())
+	assert.Equal(t, 2, len(v.Miembros))
+	assert.Equal(t, 2, len(nueva.Nodos(ACTIVO)))
+
+	_, err = nueva.Unir(config.NodeInfo{Id: "DNS11", Ip: "127.0.0.1", Port: "9010"})
+	assert.NotNil(t, err) // La dirección ya corresponde a otro miembro
+
+	// Un miembro retirado conserva su posición, también al volver a unirse
+	nueva, _ = nueva.CambiarEstado("DNS10", ACTIVO)
+	nueva, err = nueva.CambiarEstado("DNS2", RETIRADO)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DNS1", "DNS2", "DNS10"}, nueva.Ids())
+	assert.Equal(t, 2, len(nueva.Vigentes()))
+	nueva, _ = nueva.Unir(config.NodeInfo{Id: "DNS2", Ip: "10.0.0.2", Port: "9002"})
+	i, _ := nueva.Buscar("DNS2")
+	assert.Equal(t, 1, i)
+	assert.Equal(t, UNIENDOSE, nueva.Miembros[i].Estado)
+	assert.Equal(t, "10.0.0.2", nueva.Miembros[i].Ip)
+	assert.Equal(t, uint64(5), nueva.Version)
+
+	_, err = nueva.CambiarEstado("DNS3", ACTIVO)
+	assert.NotNil(t, err)
+
+	// Conversión y persistencia
+	assert.Equal(t, nueva, DesdeProto(nueva.Proto()))
+	ruta := filepath.Join(t.TempDir(), "membresia.json")
+	leida, err := Leer(ruta)
+	assert.Nil(t, err)
+	assert.Nil(t, leida)
+	assert.Nil(t, nueva.Escribir(ruta))
+	leida, err = Leer(ruta)
+	assert.Nil(t, err)
+	assert.Equal(t, nueva, leida)
+}
@@ -45,11 +45,63 @@ func (s *Server) GetDominios(ctx context.Context, message *pb.Vacio) (*pb.Domini
 	return nil, errors.New("Función GetDominios() no implementada para este nodo.")
 }
 
+func (s *Server) AsignarNodo(ctx context.Context, message *pb.SolicitudNodo) (*pb.NodoAsignado, error){
+	return nil, errors.New("Función AsignarNodo() no implementada para este nodo.")
+}
+
+func (s *Server) Join(ctx context.Context, message *pb.Miembro) (*pb.Vista, error){
+	return nil, errors.New("Función Join() no implementada para este nodo.")
+}
+
+func (s *Server) Leave(ctx context.Context, message *pb.Miembro) (*pb.Vista, error){
+	return nil, errors.New("Función Leave() no implementada para este nodo.")
+}
+
+func (s *Server) ActualizarVista(ctx context.Context, message *pb.Vista) (*pb.Vista, error){
+	return nil, errors.New("Función ActualizarVista() no implementada para este nodo.")
+}
+
+func (s *Server) TransferirEstado(ctx context.Context, message *pb.Miembro) (*pb.Estado, error){
+	return nil, errors.New("Función TransferirEstado() no implementada para este nodo.")
+}
+
+func (s *Server) IntercambiarLatidos(ctx context.Context, message *pb.Latidos) (*pb.Latidos, error){
+	return nil, errors.New("Función IntercambiarLatidos() no implementada para este nodo.")
+}
+
+func (s *Server) ObtenerSalud(ctx context.Context, message *pb.Vacio) (*pb.Latidos, error){
+	return nil, errors.New("Función ObtenerSalud() no implementada para este nodo.")
+}
+
+func (s *Server) SolicitarVoto(ctx context.Context, message *pb.SolicitudVoto) (*pb.Voto, error){
+	return nil, errors.New("Función SolicitarVoto() no implementada para este nodo.")
+}
+
+func (s *Server) RenovarLiderazgo(ctx context.Context, message *pb.Liderazgo) (*pb.Voto, error){
+	return nil, errors.New("Función RenovarLiderazgo() no implementada para este nodo.")
+}
+
+func (s *Server) CoordinarRonda(ctx context.Context, message *pb.Ronda) (*pb.EstadoRonda, error){
+	return nil, errors.New("Función CoordinarRonda() no implementada para este nodo.")
+}
+
+func (s *Server) SolicitarVotoRaft(ctx context.Context, message *pb.SolicitudVotoRaft) (*pb.Voto, error){
+	return nil, errors.New("Función SolicitarVotoRaft() no implementada para este nodo.")
+}
+
+func (s *Server) AgregarEntradas(ctx context.Context, message *pb.EntradasRaft) (*pb.ResultadoRaft, error){
+	return nil, errors.New("Función AgregarEntradas() no implementada para este nodo.")
+}
+
+func (s *Server) InstalarSnapshot(ctx context.Context, message *pb.SnapshotRaft) (*pb.ResultadoRaft, error){
+	return nil, errors.New("Función InstalarSnapshot() no implementada para este nodo.")
+}
+
 
 /*
 func IniciarNodo(port string) {
 	// Iniciar servidor gRPC
-	log.Printf("Iniciando servidor gRPC en el puerto " + port)
+	log.Printf("Iniciando servidor gRPC en el puerto %s", port)
 	lis, err := net.Listen("tcp", ":" + port)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
@@ -70,7 +122,7 @@ func IniciarNodo(port string) {
 
 func ConectarNodo(ip string, port string) (*grpc.ClientConn, error) {
 	var conn *grpc.ClientConn
-	log.Printf("Iniciando conexión con " + ip + ":" + port)
+	log.Printf("Iniciando conexión con %s:%s", ip, port)
 	host := ip + ":" + port
 	keepConf:= keepalive.ClientParameters{
 		Time:					10 * time.Second,
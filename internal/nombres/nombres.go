@@ -0,0 +1,81 @@
+package nombres
+
+import (
+	"strings"
+
+	"github.com/jfomu/DNSDistribuido/internal/validacion"
+)
+
+// Nombre que representa al propio dominio de una zona (su ápice)
+const APICE = "@"
+
+// Normaliza un nombre de dominio: lo pasa a minúsculas y elimina el punto final opcional.
+// Un nombre válido tiene al menos dos etiquetas y cumple las reglas de validacion.Nombre.
+func Normalizar(nombreDominio string) (string, error) {
+	return NormalizarCampo("nombreDominio", nombreDominio)
+}
+
+// Normaliza un nombre indicando el campo del que proviene en los errores de validación
+func NormalizarCampo(campo string, nombreDominio string) (string, error) {
+	nombre := strings.ToLower(strings.TrimSpace(nombreDominio))
+	nombre = strings.TrimSuffix(nombre, ".")
+	if nombre == "" {
+		return "", validacion.Nuevo(campo, "No se ha especificado un nombre de dominio")
+	}
+	if !strings.Contains(nombre, ".") {
+		return "", validacion.Nuevo(campo, nombreDominio + " no cumple el formato, debe tener la forma <nombre>.<dominio>")
+	}
+	if err := validacion.Nombre(campo, nombre); err != nil {
+		return "", err
+	}
+	return nombre, nil
+}
+
+// Indica si un nombre (ya normalizado) pertenece a una zona
+func EnZona(nombre string, zona string) bool {
+	return nombre == zona || strings.HasSuffix(nombre, "." + zona)
+}
+
+// Separa un nombre de dominio en su nombre relativo y su zona. La zona es el sufijo más largo
+// dentro de las zonas conocidas; si ninguna coincide se utiliza el dominio padre del nombre,
+// por ejemplo www.example.com pertenece a la zona example.com.
+func Separar(nombreDominio string, zonas []string) (string, string, error) {
+	nombre, err := Normalizar(nombreDominio)
+	if err != nil {
+		return "", "", err
+	}
+
+	zona, ok := BuscarZona(nombre, zonas)
+	if !ok {
+		zona = nombre[strings.Index(nombre, ".") + 1:]
+	}
+	return Relativo(nombre, zona), zona, nil
+}
+
+// Busca la zona más específica que contiene a un nombre (ya normalizado) entre las zonas indicadas
+func BuscarZona(nombre string, zonas []string) (string, bool) {
+	zona := ""
+	for _, z := range zonas {
+		z = strings.TrimSuffix(strings.ToLower(z), ".")
+		if z != "" && EnZona(nombre, z) && len(z) > len(zona) {
+			zona = z
+		}
+	}
+	return zona, zona != ""
+}
+
+// Obtiene el nombre relativo a la zona de un nombre (ya normalizado) que pertenece a ella
+func Relativo(nombre string, zona string) string {
+	if nombre == zona {
+		return APICE
+	}
+	return strings.TrimSuffix(nombre, "." + zona)
+}
+
+// Obtiene el nombre completo a partir de un nombre relativo y su zona
+func Unir(nombre string, zona string) string {
+	if nombre == APICE || nombre == "" {
+		return zona
+	}
+	return nombre + "." + zona
+}
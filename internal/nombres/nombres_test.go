@@ -0,0 +1,29 @@
+package nombres
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeparar(t *testing.T) {
+	nombre, zona, err := Separar("www.Example.com.", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "www", nombre)
+	assert.Equal(t, "example.com", zona)
+
+	// Se utiliza el sufijo más largo entre las zonas conocidas
+	nombre, zona, err = Separar("www.dev.example.com", []string{"com", "example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "www.dev", nombre)
+	assert.Equal(t, "example.com", zona)
+
+	nombre, zona, err = Separar("example.com", []string{"example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, APICE, nombre)
+	assert.Equal(t, "example.com", Unir(nombre, zona))
+
+	for _, invalido := range []string{"", "com", "www..com", "../etc.com", "a b.com"} {
+		_, _, err = Separar(invalido, nil)
+		assert.NotNil(t, err, invalido)
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
+// 	protoc-gen-go v1.33.0
 // 	protoc        v3.12.4
 // source: nodo.proto
 
@@ -109,20 +109,178 @@ func (x *Estado) GetEstado() string {
 	return ""
 }
 
+// Registro de recurso de una zona. El valor es la dirección (A, AAAA), el nombre
+// destino (CNAME, NS, PTR, MX, SRV) o el texto (TXT); un ttl 0 indica el TTL por defecto.
+type Recurso struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nombre    string `protobuf:"bytes,1,opt,name=nombre,proto3" json:"nombre,omitempty"`
+	Tipo      string `protobuf:"bytes,2,opt,name=tipo,proto3" json:"tipo,omitempty"`
+	Ttl       uint32 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Valor     string `protobuf:"bytes,4,opt,name=valor,proto3" json:"valor,omitempty"`
+	Prioridad uint32 `protobuf:"varint,5,opt,name=prioridad,proto3" json:"prioridad,omitempty"`
+	Peso      uint32 `protobuf:"varint,6,opt,name=peso,proto3" json:"peso,omitempty"`
+	Puerto    uint32 `protobuf:"varint,7,opt,name=puerto,proto3" json:"puerto,omitempty"`
+}
+
+func (x *Recurso) Reset() {
+	*x = Recurso{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Recurso) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recurso) ProtoMessage() {}
+
+func (x *Recurso) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recurso.ProtoReflect.Descriptor instead.
+func (*Recurso) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Recurso) GetNombre() string {
+	if x != nil {
+		return x.Nombre
+	}
+	return ""
+}
+
+func (x *Recurso) GetTipo() string {
+	if x != nil {
+		return x.Tipo
+	}
+	return ""
+}
+
+func (x *Recurso) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *Recurso) GetValor() string {
+	if x != nil {
+		return x.Valor
+	}
+	return ""
+}
+
+func (x *Recurso) GetPrioridad() uint32 {
+	if x != nil {
+		return x.Prioridad
+	}
+	return 0
+}
+
+func (x *Recurso) GetPeso() uint32 {
+	if x != nil {
+		return x.Peso
+	}
+	return 0
+}
+
+func (x *Recurso) GetPuerto() uint32 {
+	if x != nil {
+		return x.Puerto
+	}
+	return 0
+}
+
+// Servidor DNS al que el broker reenvía una consulta de Get, Create, Delete o Update, sin
+// reintentarla en otro si no está disponible
+type Fijacion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ip   string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port string `protobuf:"bytes,2,opt,name=port,proto3" json:"port,omitempty"`
+}
+
+func (x *Fijacion) Reset() {
+	*x = Fijacion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fijacion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fijacion) ProtoMessage() {}
+
+func (x *Fijacion) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fijacion.ProtoReflect.Descriptor instead.
+func (*Fijacion) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Fijacion) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Fijacion) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+// En un Create sin recurso, ip es la dirección del registro A a agregar
 type Consulta struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	NombreDominio string `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
-	Ip            string `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
-	Port          string `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	NombreDominio string    `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
+	Ip            string    `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port          string    `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	Tipo          string    `protobuf:"bytes,4,opt,name=tipo,proto3" json:"tipo,omitempty"`       // Get: tipo de registro consultado, por defecto A
+	Recurso       *Recurso  `protobuf:"bytes,5,opt,name=recurso,proto3" json:"recurso,omitempty"` // Create: registro a agregar, si no se indica se agrega un registro A con la ip
+	Fijacion      *Fijacion `protobuf:"bytes,6,opt,name=fijacion,proto3" json:"fijacion,omitempty"`
 }
 
 func (x *Consulta) Reset() {
 	*x = Consulta{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[2]
+		mi := &file_nodo_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -135,7 +293,7 @@ func (x *Consulta) String() string {
 func (*Consulta) ProtoMessage() {}
 
 func (x *Consulta) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[2]
+	mi := &file_nodo_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -148,7 +306,7 @@ func (x *Consulta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Consulta.ProtoReflect.Descriptor instead.
 func (*Consulta) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{2}
+	return file_nodo_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Consulta) GetNombreDominio() string {
@@ -172,18 +330,41 @@ func (x *Consulta) GetPort() string {
 	return ""
 }
 
+func (x *Consulta) GetTipo() string {
+	if x != nil {
+		return x.Tipo
+	}
+	return ""
+}
+
+func (x *Consulta) GetRecurso() *Recurso {
+	if x != nil {
+		return x.Recurso
+	}
+	return nil
+}
+
+func (x *Consulta) GetFijacion() *Fijacion {
+	if x != nil {
+		return x.Fijacion
+	}
+	return nil
+}
+
 type ConsultaAdmin struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	NombreDominio string `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
+	NombreDominio string    `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
+	Recurso       *Recurso  `protobuf:"bytes,2,opt,name=recurso,proto3" json:"recurso,omitempty"` // Delete: si se indica solo se eliminan los registros de su tipo (y valor, si lo tiene)
+	Fijacion      *Fijacion `protobuf:"bytes,3,opt,name=fijacion,proto3" json:"fijacion,omitempty"`
 }
 
 func (x *ConsultaAdmin) Reset() {
 	*x = ConsultaAdmin{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[3]
+		mi := &file_nodo_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -196,7 +377,7 @@ func (x *ConsultaAdmin) String() string {
 func (*ConsultaAdmin) ProtoMessage() {}
 
 func (x *ConsultaAdmin) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[3]
+	mi := &file_nodo_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -209,7 +390,7 @@ func (x *ConsultaAdmin) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsultaAdmin.ProtoReflect.Descriptor instead.
 func (*ConsultaAdmin) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{3}
+	return file_nodo_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ConsultaAdmin) GetNombreDominio() string {
@@ -219,20 +400,35 @@ func (x *ConsultaAdmin) GetNombreDominio() string {
 	return ""
 }
 
+func (x *ConsultaAdmin) GetRecurso() *Recurso {
+	if x != nil {
+		return x.Recurso
+	}
+	return nil
+}
+
+func (x *ConsultaAdmin) GetFijacion() *Fijacion {
+	if x != nil {
+		return x.Fijacion
+	}
+	return nil
+}
+
 type ConsultaUpdate struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	NombreDominio string `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
-	Opcion        string `protobuf:"bytes,2,opt,name=opcion,proto3" json:"opcion,omitempty"`
-	Param         string `protobuf:"bytes,3,opt,name=param,proto3" json:"param,omitempty"`
+	NombreDominio string    `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
+	Opcion        string    `protobuf:"bytes,2,opt,name=opcion,proto3" json:"opcion,omitempty"`
+	Param         string    `protobuf:"bytes,3,opt,name=param,proto3" json:"param,omitempty"`
+	Fijacion      *Fijacion `protobuf:"bytes,4,opt,name=fijacion,proto3" json:"fijacion,omitempty"`
 }
 
 func (x *ConsultaUpdate) Reset() {
 	*x = ConsultaUpdate{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[4]
+		mi := &file_nodo_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -245,7 +441,7 @@ func (x *ConsultaUpdate) String() string {
 func (*ConsultaUpdate) ProtoMessage() {}
 
 func (x *ConsultaUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[4]
+	mi := &file_nodo_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -258,7 +454,7 @@ func (x *ConsultaUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConsultaUpdate.ProtoReflect.Descriptor instead.
 func (*ConsultaUpdate) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{4}
+	return file_nodo_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ConsultaUpdate) GetNombreDominio() string {
@@ -282,21 +478,29 @@ func (x *ConsultaUpdate) GetParam() string {
 	return ""
 }
 
+func (x *ConsultaUpdate) GetFijacion() *Fijacion {
+	if x != nil {
+		return x.Fijacion
+	}
+	return nil
+}
+
 type Respuesta struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Ip        string  `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
-	Port      string  `protobuf:"bytes,2,opt,name=port,proto3" json:"port,omitempty"`
-	Respuesta string  `protobuf:"bytes,3,opt,name=respuesta,proto3" json:"respuesta,omitempty"`
-	Reloj     []int32 `protobuf:"varint,4,rep,packed,name=reloj,proto3" json:"reloj,omitempty"`
+	Ip        string     `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port      string     `protobuf:"bytes,2,opt,name=port,proto3" json:"port,omitempty"`
+	Respuesta string     `protobuf:"bytes,3,opt,name=respuesta,proto3" json:"respuesta,omitempty"`
+	Reloj     []int32    `protobuf:"varint,4,rep,packed,name=reloj,proto3" json:"reloj,omitempty"`
+	Recursos  []*Recurso `protobuf:"bytes,5,rep,name=recursos,proto3" json:"recursos,omitempty"`
 }
 
 func (x *Respuesta) Reset() {
 	*x = Respuesta{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[5]
+		mi := &file_nodo_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -309,7 +513,7 @@ func (x *Respuesta) String() string {
 func (*Respuesta) ProtoMessage() {}
 
 func (x *Respuesta) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[5]
+	mi := &file_nodo_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -322,7 +526,7 @@ func (x *Respuesta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Respuesta.ProtoReflect.Descriptor instead.
 func (*Respuesta) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{5}
+	return file_nodo_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Respuesta) GetIp() string {
@@ -353,18 +557,27 @@ func (x *Respuesta) GetReloj() []int32 {
 	return nil
 }
 
+func (x *Respuesta) GetRecursos() []*Recurso {
+	if x != nil {
+		return x.Recursos
+	}
+	return nil
+}
+
 type RespuestaAdmin struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Reloj []int32 `protobuf:"varint,1,rep,packed,name=reloj,proto3" json:"reloj,omitempty"`
+	Ip    string  `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"` // servidor DNS que aplicó el cambio
+	Port  string  `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
 }
 
 func (x *RespuestaAdmin) Reset() {
 	*x = RespuestaAdmin{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[6]
+		mi := &file_nodo_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -377,7 +590,7 @@ func (x *RespuestaAdmin) String() string {
 func (*RespuestaAdmin) ProtoMessage() {}
 
 func (x *RespuestaAdmin) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[6]
+	mi := &file_nodo_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -390,7 +603,7 @@ func (x *RespuestaAdmin) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RespuestaAdmin.ProtoReflect.Descriptor instead.
 func (*RespuestaAdmin) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{6}
+	return file_nodo_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *RespuestaAdmin) GetReloj() []int32 {
@@ -400,19 +613,36 @@ func (x *RespuestaAdmin) GetReloj() []int32 {
 	return nil
 }
 
+func (x *RespuestaAdmin) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *RespuestaAdmin) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
 type File struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FileInfo  string `protobuf:"bytes,1,opt,name=fileInfo,proto3" json:"fileInfo,omitempty"`
-	ChunkData []byte `protobuf:"bytes,3,opt,name=chunkData,proto3" json:"chunkData,omitempty"`
+	FileInfo  string  `protobuf:"bytes,1,opt,name=fileInfo,proto3" json:"fileInfo,omitempty"`
+	ChunkData []byte  `protobuf:"bytes,2,opt,name=chunkData,proto3" json:"chunkData,omitempty"`
+	Reloj     []int32 `protobuf:"varint,3,rep,packed,name=reloj,proto3" json:"reloj,omitempty"`
+	IdNodo    string  `protobuf:"bytes,4,opt,name=idNodo,proto3" json:"idNodo,omitempty"` // nodo que envía el estado
+	Ronda     string  `protobuf:"bytes,5,opt,name=ronda,proto3" json:"ronda,omitempty"`   // si se indica, el estado se prepara para esa ronda de consistencia en lugar de aplicarse
 }
 
 func (x *File) Reset() {
 	*x = File{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[7]
+		mi := &file_nodo_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -425,7 +655,7 @@ func (x *File) String() string {
 func (*File) ProtoMessage() {}
 
 func (x *File) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[7]
+	mi := &file_nodo_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -438,7 +668,7 @@ func (x *File) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use File.ProtoReflect.Descriptor instead.
 func (*File) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{7}
+	return file_nodo_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *File) GetFileInfo() string {
@@ -455,6 +685,27 @@ func (x *File) GetChunkData() []byte {
 	return nil
 }
 
+func (x *File) GetReloj() []int32 {
+	if x != nil {
+		return x.Reloj
+	}
+	return nil
+}
+
+func (x *File) GetIdNodo() string {
+	if x != nil {
+		return x.IdNodo
+	}
+	return ""
+}
+
+func (x *File) GetRonda() string {
+	if x != nil {
+		return x.Ronda
+	}
+	return ""
+}
+
 type Dominios struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -466,7 +717,7 @@ type Dominios struct {
 func (x *Dominios) Reset() {
 	*x = Dominios{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_nodo_proto_msgTypes[8]
+		mi := &file_nodo_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -479,7 +730,7 @@ func (x *Dominios) String() string {
 func (*Dominios) ProtoMessage() {}
 
 func (x *Dominios) ProtoReflect() protoreflect.Message {
-	mi := &file_nodo_proto_msgTypes[8]
+	mi := &file_nodo_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -492,7 +743,7 @@ func (x *Dominios) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Dominios.ProtoReflect.Descriptor instead.
 func (*Dominios) Descriptor() ([]byte, []int) {
-	return file_nodo_proto_rawDescGZIP(), []int{8}
+	return file_nodo_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Dominios) GetDominios() []string {
@@ -502,139 +753,1522 @@ func (x *Dominios) GetDominios() []string {
 	return nil
 }
 
-var File_nodo_proto protoreflect.FileDescriptor
+// AsignarNodo: con afinidad se prefiere el servidor DNS indicado si está activo, por ejemplo el
+// de la última escritura de la sesión del administrador
+type SolicitudNodo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_nodo_proto_rawDesc = []byte{
-	0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x22, 0x07, 0x0a, 0x05, 0x56, 0x61, 0x63, 0x69, 0x6f, 0x22, 0x20, 0x0a, 0x06,
-	0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x22, 0x54,
-	0x0a, 0x08, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x12, 0x24, 0x0a, 0x0d, 0x6e, 0x6f,
-	0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f,
-	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70,
-	0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x70, 0x6f, 0x72, 0x74, 0x22, 0x35, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61,
-	0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44,
-	0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f,
-	0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x22, 0x64, 0x0a, 0x0e, 0x43,
-	0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a,
-	0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69,
-	0x6e, 0x69, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x70, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x70, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x70,
-	0x61, 0x72, 0x61, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x72, 0x61,
-	0x6d, 0x22, 0x63, 0x0a, 0x09, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12,
-	0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f,
-	0x72, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61,
-	0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05, 0x52,
-	0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x22, 0x26, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65,
-	0x73, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x6c, 0x6f,
-	0x6a, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x22, 0x40,
-	0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x49, 0x6e,
-	0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x49, 0x6e,
-	0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61,
-	0x22, 0x26, 0x0a, 0x08, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x12, 0x1a, 0x0a, 0x08,
-	0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08,
-	0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x32, 0x87, 0x03, 0x0a, 0x0c, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x69, 0x6f, 0x4e, 0x6f, 0x64, 0x6f, 0x12, 0x2f, 0x0a, 0x0d, 0x4f, 0x62, 0x74,
-	0x65, 0x6e, 0x65, 0x72, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x0f, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a, 0x0d, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x28, 0x0a, 0x03, 0x47, 0x65,
-	0x74, 0x12, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c,
-	0x74, 0x61, 0x1a, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75,
-	0x65, 0x73, 0x74, 0x61, 0x12, 0x2b, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x0f,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a,
-	0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74,
-	0x61, 0x12, 0x35, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x14, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69,
-	0x6e, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65,
-	0x73, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x36, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x12, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75,
-	0x6c, 0x74, 0x61, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69, 0x6e,
-	0x12, 0x29, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x0f, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a, 0x0b, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x30, 0x01, 0x12, 0x27, 0x0a, 0x07, 0x53,
-	0x65, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x0b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46,
-	0x69, 0x6c, 0x65, 0x1a, 0x0d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x73, 0x74, 0x61,
-	0x64, 0x6f, 0x28, 0x01, 0x12, 0x2c, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x44, 0x6f, 0x6d, 0x69, 0x6e,
-	0x69, 0x6f, 0x73, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x61, 0x63, 0x69,
-	0x6f, 0x1a, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69,
-	0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	NombreDominio string    `protobuf:"bytes,1,opt,name=nombreDominio,proto3" json:"nombreDominio,omitempty"`
+	Afinidad      *Fijacion `protobuf:"bytes,2,opt,name=afinidad,proto3" json:"afinidad,omitempty"`
 }
 
-var (
-	file_nodo_proto_rawDescOnce sync.Once
-	file_nodo_proto_rawDescData = file_nodo_proto_rawDesc
-)
+func (x *SolicitudNodo) Reset() {
+	*x = SolicitudNodo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_nodo_proto_rawDescGZIP() []byte {
-	file_nodo_proto_rawDescOnce.Do(func() {
-		file_nodo_proto_rawDescData = protoimpl.X.CompressGZIP(file_nodo_proto_rawDescData)
-	})
-	return file_nodo_proto_rawDescData
+func (x *SolicitudNodo) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_nodo_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
-var file_nodo_proto_goTypes = []interface{}{
-	(*Vacio)(nil),          // 0: proto.Vacio
-	(*Estado)(nil),         // 1: proto.Estado
-	(*Consulta)(nil),       // 2: proto.Consulta
-	(*ConsultaAdmin)(nil),  // 3: proto.ConsultaAdmin
-	(*ConsultaUpdate)(nil), // 4: proto.ConsultaUpdate
-	(*Respuesta)(nil),      // 5: proto.Respuesta
-	(*RespuestaAdmin)(nil), // 6: proto.RespuestaAdmin
-	(*File)(nil),           // 7: proto.File
-	(*Dominios)(nil),       // 8: proto.Dominios
+func (*SolicitudNodo) ProtoMessage() {}
+
+func (x *SolicitudNodo) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_nodo_proto_depIdxs = []int32{
-	2, // 0: proto.ServicioNodo.ObtenerEstado:input_type -> proto.Consulta
-	2, // 1: proto.ServicioNodo.Get:input_type -> proto.Consulta
-	2, // 2: proto.ServicioNodo.Create:input_type -> proto.Consulta
-	3, // 3: proto.ServicioNodo.Delete:input_type -> proto.ConsultaAdmin
-	4, // 4: proto.ServicioNodo.Update:input_type -> proto.ConsultaUpdate
-	2, // 5: proto.ServicioNodo.GetFile:input_type -> proto.Consulta
-	7, // 6: proto.ServicioNodo.SetFile:input_type -> proto.File
-	0, // 7: proto.ServicioNodo.GetDominios:input_type -> proto.Vacio
-	1, // 8: proto.ServicioNodo.ObtenerEstado:output_type -> proto.Estado
-	5, // 9: proto.ServicioNodo.Get:output_type -> proto.Respuesta
-	5, // 10: proto.ServicioNodo.Create:output_type -> proto.Respuesta
-	6, // 11: proto.ServicioNodo.Delete:output_type -> proto.RespuestaAdmin
-	6, // 12: proto.ServicioNodo.Update:output_type -> proto.RespuestaAdmin
-	7, // 13: proto.ServicioNodo.GetFile:output_type -> proto.File
-	1, // 14: proto.ServicioNodo.SetFile:output_type -> proto.Estado
-	8, // 15: proto.ServicioNodo.GetDominios:output_type -> proto.Dominios
-	8, // [8:16] is the sub-list for method output_type
-	0, // [0:8] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+// Deprecated: Use SolicitudNodo.ProtoReflect.Descriptor instead.
+func (*SolicitudNodo) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{11}
 }
 
-func init() { file_nodo_proto_init() }
-func file_nodo_proto_init() {
-	if File_nodo_proto != nil {
-		return
+func (x *SolicitudNodo) GetNombreDominio() string {
+	if x != nil {
+		return x.NombreDominio
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_nodo_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Vacio); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
+	return ""
+}
+
+func (x *SolicitudNodo) GetAfinidad() *Fijacion {
+	if x != nil {
+		return x.Afinidad
+	}
+	return nil
+}
+
+type NodoAsignado struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ip    string  `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port  string  `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	Reloj []int32 `protobuf:"varint,4,rep,packed,name=reloj,proto3" json:"reloj,omitempty"` // reloj más reciente conocido por el broker para la zona del nombre indicado
+}
+
+func (x *NodoAsignado) Reset() {
+	*x = NodoAsignado{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodoAsignado) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodoAsignado) ProtoMessage() {}
+
+func (x *NodoAsignado) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodoAsignado.ProtoReflect.Descriptor instead.
+func (*NodoAsignado) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *NodoAsignado) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NodoAsignado) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *NodoAsignado) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *NodoAsignado) GetReloj() []int32 {
+	if x != nil {
+		return x.Reloj
+	}
+	return nil
+}
+
+// Miembro del clúster de servidores DNS. Su estado es UNIENDOSE, ACTIVO, SALIENDO o RETIRADO.
+type Miembro struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ip      string `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port    string `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	DnsPort string `protobuf:"bytes,4,opt,name=dnsPort,proto3" json:"dnsPort,omitempty"`
+	Estado  string `protobuf:"bytes,5,opt,name=estado,proto3" json:"estado,omitempty"`
+}
+
+func (x *Miembro) Reset() {
+	*x = Miembro{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Miembro) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Miembro) ProtoMessage() {}
+
+func (x *Miembro) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Miembro.ProtoReflect.Descriptor instead.
+func (*Miembro) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Miembro) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Miembro) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Miembro) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *Miembro) GetDnsPort() string {
+	if x != nil {
+		return x.DnsPort
+	}
+	return ""
+}
+
+func (x *Miembro) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+// Vista de la membresía del clúster. El orden de los miembros da su posición en los relojes de
+// vector, por lo que los miembros retirados se mantienen en la vista.
+type Vista struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version  uint64     `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Miembros []*Miembro `protobuf:"bytes,2,rep,name=miembros,proto3" json:"miembros,omitempty"`
+}
+
+func (x *Vista) Reset() {
+	*x = Vista{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Vista) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vista) ProtoMessage() {}
+
+func (x *Vista) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vista.ProtoReflect.Descriptor instead.
+func (*Vista) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Vista) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Vista) GetMiembros() []*Miembro {
+	if x != nil {
+		return x.Miembros
+	}
+	return nil
+}
+
+// Latido de un servidor DNS según la detección de fallas. La generación identifica cada inicio del
+// nodo, de modo que un nodo reiniciado no se confunda con latidos antiguos. Su estado es VIVO,
+// SOSPECHOSO o MUERTO según el nodo que informa.
+type Latido struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ip         string `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port       string `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	Generacion int64  `protobuf:"varint,4,opt,name=generacion,proto3" json:"generacion,omitempty"`
+	Contador   uint64 `protobuf:"varint,5,opt,name=contador,proto3" json:"contador,omitempty"`
+	Estado     string `protobuf:"bytes,6,opt,name=estado,proto3" json:"estado,omitempty"`
+	Segundos   int64  `protobuf:"varint,7,opt,name=segundos,proto3" json:"segundos,omitempty"` // segundos desde que el nodo que informa recibió el último latido
+}
+
+func (x *Latido) Reset() {
+	*x = Latido{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Latido) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Latido) ProtoMessage() {}
+
+func (x *Latido) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Latido.ProtoReflect.Descriptor instead.
+func (*Latido) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Latido) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Latido) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Latido) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *Latido) GetGeneracion() int64 {
+	if x != nil {
+		return x.Generacion
+	}
+	return 0
+}
+
+func (x *Latido) GetContador() uint64 {
+	if x != nil {
+		return x.Contador
+	}
+	return 0
+}
+
+func (x *Latido) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+func (x *Latido) GetSegundos() int64 {
+	if x != nil {
+		return x.Segundos
+	}
+	return 0
+}
+
+type Latidos struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IdNodo      string    `protobuf:"bytes,1,opt,name=idNodo,proto3" json:"idNodo,omitempty"` // nodo que informa
+	Latidos     []*Latido `protobuf:"bytes,2,rep,name=latidos,proto3" json:"latidos,omitempty"`
+	Lider       string    `protobuf:"bytes,3,opt,name=lider,proto3" json:"lider,omitempty"` // coordinador vigente de las rondas de consistencia según el nodo que informa
+	Termino     uint64    `protobuf:"varint,4,opt,name=termino,proto3" json:"termino,omitempty"`
+	LiderRaft   string    `protobuf:"bytes,5,opt,name=liderRaft,proto3" json:"liderRaft,omitempty"` // líder del log replicado de las zonas de consistencia fuerte
+	TerminoRaft uint64    `protobuf:"varint,6,opt,name=terminoRaft,proto3" json:"terminoRaft,omitempty"`
+}
+
+func (x *Latidos) Reset() {
+	*x = Latidos{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Latidos) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Latidos) ProtoMessage() {}
+
+func (x *Latidos) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Latidos.ProtoReflect.Descriptor instead.
+func (*Latidos) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Latidos) GetIdNodo() string {
+	if x != nil {
+		return x.IdNodo
+	}
+	return ""
+}
+
+func (x *Latidos) GetLatidos() []*Latido {
+	if x != nil {
+		return x.Latidos
+	}
+	return nil
+}
+
+func (x *Latidos) GetLider() string {
+	if x != nil {
+		return x.Lider
+	}
+	return ""
+}
+
+func (x *Latidos) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *Latidos) GetLiderRaft() string {
+	if x != nil {
+		return x.LiderRaft
+	}
+	return ""
+}
+
+func (x *Latidos) GetTerminoRaft() uint64 {
+	if x != nil {
+		return x.TerminoRaft
+	}
+	return 0
+}
+
+// Elección del coordinador de las rondas de consistencia
+type SolicitudVoto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino   uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Candidato string `protobuf:"bytes,2,opt,name=candidato,proto3" json:"candidato,omitempty"`
+}
+
+func (x *SolicitudVoto) Reset() {
+	*x = SolicitudVoto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolicitudVoto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolicitudVoto) ProtoMessage() {}
+
+func (x *SolicitudVoto) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolicitudVoto.ProtoReflect.Descriptor instead.
+func (*SolicitudVoto) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SolicitudVoto) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *SolicitudVoto) GetCandidato() string {
+	if x != nil {
+		return x.Candidato
+	}
+	return ""
+}
+
+type Liderazgo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino      uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Lider        string `protobuf:"bytes,2,opt,name=lider,proto3" json:"lider,omitempty"`
+	RondaEnCurso bool   `protobuf:"varint,3,opt,name=rondaEnCurso,proto3" json:"rondaEnCurso,omitempty"` // el líder está coordinando una ronda, que su sucesor debe retomar si este cae
+}
+
+func (x *Liderazgo) Reset() {
+	*x = Liderazgo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Liderazgo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Liderazgo) ProtoMessage() {}
+
+func (x *Liderazgo) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Liderazgo.ProtoReflect.Descriptor instead.
+func (*Liderazgo) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Liderazgo) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *Liderazgo) GetLider() string {
+	if x != nil {
+		return x.Lider
+	}
+	return ""
+}
+
+func (x *Liderazgo) GetRondaEnCurso() bool {
+	if x != nil {
+		return x.RondaEnCurso
+	}
+	return false
+}
+
+// Respuesta a SolicitarVoto y RenovarLiderazgo, con el término del nodo que responde
+type Voto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino   uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Concedido bool   `protobuf:"varint,2,opt,name=concedido,proto3" json:"concedido,omitempty"`
+}
+
+func (x *Voto) Reset() {
+	*x = Voto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Voto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Voto) ProtoMessage() {}
+
+func (x *Voto) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Voto.ProtoReflect.Descriptor instead.
+func (*Voto) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Voto) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *Voto) GetConcedido() bool {
+	if x != nil {
+		return x.Concedido
+	}
+	return false
+}
+
+// Mensaje del coordinador de una ronda de consistencia con commit en dos fases
+type Ronda struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id            string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Fase          string   `protobuf:"bytes,2,opt,name=fase,proto3" json:"fase,omitempty"` // CONGELAR, CONFIRMAR, ABORTAR o CONSULTAR
+	Coordinador   string   `protobuf:"bytes,3,opt,name=coordinador,proto3" json:"coordinador,omitempty"`
+	Participantes []string `protobuf:"bytes,4,rep,name=participantes,proto3" json:"participantes,omitempty"` // nodos de la ronda, para consultar su resultado si el coordinador cae
+}
+
+func (x *Ronda) Reset() {
+	*x = Ronda{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ronda) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ronda) ProtoMessage() {}
+
+func (x *Ronda) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ronda.ProtoReflect.Descriptor instead.
+func (*Ronda) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Ronda) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Ronda) GetFase() string {
+	if x != nil {
+		return x.Fase
+	}
+	return ""
+}
+
+func (x *Ronda) GetCoordinador() string {
+	if x != nil {
+		return x.Coordinador
+	}
+	return ""
+}
+
+func (x *Ronda) GetParticipantes() []string {
+	if x != nil {
+		return x.Participantes
+	}
+	return nil
+}
+
+type EstadoRonda struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Estado string `protobuf:"bytes,2,opt,name=estado,proto3" json:"estado,omitempty"` // CONGELADA, PREPARADA, CONFIRMADA, ABORTADA o DESCONOCIDA
+}
+
+func (x *EstadoRonda) Reset() {
+	*x = EstadoRonda{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EstadoRonda) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstadoRonda) ProtoMessage() {}
+
+func (x *EstadoRonda) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstadoRonda.ProtoReflect.Descriptor instead.
+func (*EstadoRonda) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *EstadoRonda) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EstadoRonda) GetEstado() string {
+	if x != nil {
+		return x.Estado
+	}
+	return ""
+}
+
+// Replicación con Raft del log de escrituras de las zonas de consistencia fuerte
+type SolicitudVotoRaft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino       uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Candidato     string `protobuf:"bytes,2,opt,name=candidato,proto3" json:"candidato,omitempty"`
+	UltimoIndice  uint64 `protobuf:"varint,3,opt,name=ultimoIndice,proto3" json:"ultimoIndice,omitempty"` // última entrada del log del candidato
+	UltimoTermino uint64 `protobuf:"varint,4,opt,name=ultimoTermino,proto3" json:"ultimoTermino,omitempty"`
+}
+
+func (x *SolicitudVotoRaft) Reset() {
+	*x = SolicitudVotoRaft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SolicitudVotoRaft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolicitudVotoRaft) ProtoMessage() {}
+
+func (x *SolicitudVotoRaft) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolicitudVotoRaft.ProtoReflect.Descriptor instead.
+func (*SolicitudVotoRaft) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SolicitudVotoRaft) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *SolicitudVotoRaft) GetCandidato() string {
+	if x != nil {
+		return x.Candidato
+	}
+	return ""
+}
+
+func (x *SolicitudVotoRaft) GetUltimoIndice() uint64 {
+	if x != nil {
+		return x.UltimoIndice
+	}
+	return 0
+}
+
+func (x *SolicitudVotoRaft) GetUltimoTermino() uint64 {
+	if x != nil {
+		return x.UltimoTermino
+	}
+	return 0
+}
+
+type EntradaRaft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Comando []byte `protobuf:"bytes,2,opt,name=comando,proto3" json:"comando,omitempty"` // vacío en la entrada con que un líder inicia su término
+}
+
+func (x *EntradaRaft) Reset() {
+	*x = EntradaRaft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntradaRaft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntradaRaft) ProtoMessage() {}
+
+func (x *EntradaRaft) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntradaRaft.ProtoReflect.Descriptor instead.
+func (*EntradaRaft) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *EntradaRaft) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *EntradaRaft) GetComando() []byte {
+	if x != nil {
+		return x.Comando
+	}
+	return nil
+}
+
+type EntradasRaft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino         uint64         `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Lider           string         `protobuf:"bytes,2,opt,name=lider,proto3" json:"lider,omitempty"`
+	IndiceAnterior  uint64         `protobuf:"varint,3,opt,name=indiceAnterior,proto3" json:"indiceAnterior,omitempty"` // entrada que precede a las enviadas
+	TerminoAnterior uint64         `protobuf:"varint,4,opt,name=terminoAnterior,proto3" json:"terminoAnterior,omitempty"`
+	Entradas        []*EntradaRaft `protobuf:"bytes,5,rep,name=entradas,proto3" json:"entradas,omitempty"`      // vacío en los latidos del líder
+	Confirmado      uint64         `protobuf:"varint,6,opt,name=confirmado,proto3" json:"confirmado,omitempty"` // última entrada confirmada por el líder
+}
+
+func (x *EntradasRaft) Reset() {
+	*x = EntradasRaft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntradasRaft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntradasRaft) ProtoMessage() {}
+
+func (x *EntradasRaft) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntradasRaft.ProtoReflect.Descriptor instead.
+func (*EntradasRaft) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *EntradasRaft) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *EntradasRaft) GetLider() string {
+	if x != nil {
+		return x.Lider
+	}
+	return ""
+}
+
+func (x *EntradasRaft) GetIndiceAnterior() uint64 {
+	if x != nil {
+		return x.IndiceAnterior
+	}
+	return 0
+}
+
+func (x *EntradasRaft) GetTerminoAnterior() uint64 {
+	if x != nil {
+		return x.TerminoAnterior
+	}
+	return 0
+}
+
+func (x *EntradasRaft) GetEntradas() []*EntradaRaft {
+	if x != nil {
+		return x.Entradas
+	}
+	return nil
+}
+
+func (x *EntradasRaft) GetConfirmado() uint64 {
+	if x != nil {
+		return x.Confirmado
+	}
+	return 0
+}
+
+type SnapshotRaft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino       uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Lider         string `protobuf:"bytes,2,opt,name=lider,proto3" json:"lider,omitempty"`
+	Indice        uint64 `protobuf:"varint,3,opt,name=indice,proto3" json:"indice,omitempty"` // última entrada incluida en el snapshot
+	TerminoIndice uint64 `protobuf:"varint,4,opt,name=terminoIndice,proto3" json:"terminoIndice,omitempty"`
+	Datos         []byte `protobuf:"bytes,5,opt,name=datos,proto3" json:"datos,omitempty"`
+}
+
+func (x *SnapshotRaft) Reset() {
+	*x = SnapshotRaft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotRaft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRaft) ProtoMessage() {}
+
+func (x *SnapshotRaft) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRaft.ProtoReflect.Descriptor instead.
+func (*SnapshotRaft) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SnapshotRaft) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *SnapshotRaft) GetLider() string {
+	if x != nil {
+		return x.Lider
+	}
+	return ""
+}
+
+func (x *SnapshotRaft) GetIndice() uint64 {
+	if x != nil {
+		return x.Indice
+	}
+	return 0
+}
+
+func (x *SnapshotRaft) GetTerminoIndice() uint64 {
+	if x != nil {
+		return x.TerminoIndice
+	}
+	return 0
+}
+
+func (x *SnapshotRaft) GetDatos() []byte {
+	if x != nil {
+		return x.Datos
+	}
+	return nil
+}
+
+// Respuesta a AgregarEntradas e InstalarSnapshot
+type ResultadoRaft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Termino      uint64 `protobuf:"varint,1,opt,name=termino,proto3" json:"termino,omitempty"`
+	Exito        bool   `protobuf:"varint,2,opt,name=exito,proto3" json:"exito,omitempty"`
+	UltimoIndice uint64 `protobuf:"varint,3,opt,name=ultimoIndice,proto3" json:"ultimoIndice,omitempty"` // última entrada del log del seguidor, para retroceder al reintentar
+}
+
+func (x *ResultadoRaft) Reset() {
+	*x = ResultadoRaft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nodo_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResultadoRaft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultadoRaft) ProtoMessage() {}
+
+func (x *ResultadoRaft) ProtoReflect() protoreflect.Message {
+	mi := &file_nodo_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultadoRaft.ProtoReflect.Descriptor instead.
+func (*ResultadoRaft) Descriptor() ([]byte, []int) {
+	return file_nodo_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ResultadoRaft) GetTermino() uint64 {
+	if x != nil {
+		return x.Termino
+	}
+	return 0
+}
+
+func (x *ResultadoRaft) GetExito() bool {
+	if x != nil {
+		return x.Exito
+	}
+	return false
+}
+
+func (x *ResultadoRaft) GetUltimoIndice() uint64 {
+	if x != nil {
+		return x.UltimoIndice
+	}
+	return 0
+}
+
+var File_nodo_proto protoreflect.FileDescriptor
+
+var file_nodo_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x07, 0x0a, 0x05, 0x56, 0x61, 0x63, 0x69, 0x6f, 0x22, 0x20, 0x0a, 0x06,
+	0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x22, 0xa7,
+	0x01, 0x0a, 0x07, 0x52, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x6f,
+	0x6d, 0x62, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x6d, 0x62,
+	0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x70, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x69, 0x70, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x6f,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x6f, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x64, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x09, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x64, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x65, 0x73, 0x6f, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x70, 0x65, 0x73, 0x6f,
+	0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x65, 0x72, 0x74, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x70, 0x75, 0x65, 0x72, 0x74, 0x6f, 0x22, 0x2e, 0x0a, 0x08, 0x46, 0x69, 0x6a, 0x61,
+	0x63, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x22, 0xbf, 0x01, 0x0a, 0x08, 0x43, 0x6f, 0x6e,
+	0x73, 0x75, 0x6c, 0x74, 0x61, 0x12, 0x24, 0x0a, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44,
+	0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f,
+	0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x69, 0x70, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x69, 0x70, 0x6f, 0x12, 0x28, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63,
+	0x75, 0x72, 0x73, 0x6f, 0x52, 0x07, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x2b, 0x0a,
+	0x08, 0x66, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e,
+	0x52, 0x08, 0x66, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x22, 0x8c, 0x01, 0x0a, 0x0d, 0x43,
+	0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x24, 0x0a, 0x0d,
+	0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e,
+	0x69, 0x6f, 0x12, 0x28, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x75,
+	0x72, 0x73, 0x6f, 0x52, 0x07, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x2b, 0x0a, 0x08,
+	0x66, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x52,
+	0x08, 0x66, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x22, 0x91, 0x01, 0x0a, 0x0e, 0x43, 0x6f,
+	0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d,
+	0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e,
+	0x69, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x70, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6f, 0x70, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x12, 0x2b, 0x0a, 0x08, 0x66, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x6a, 0x61, 0x63,
+	0x69, 0x6f, 0x6e, 0x52, 0x08, 0x66, 0x69, 0x6a, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x22, 0x8f, 0x01,
+	0x0a, 0x09, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x12, 0x14, 0x0a,
+	0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05, 0x52, 0x05, 0x72, 0x65,
+	0x6c, 0x6f, 0x6a, 0x12, 0x2a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65,
+	0x63, 0x75, 0x72, 0x73, 0x6f, 0x52, 0x08, 0x72, 0x65, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x22,
+	0x4a, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05,
+	0x52, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x22, 0x84, 0x01, 0x0a, 0x04,
+	0x46, 0x69, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12, 0x14,
+	0x0a, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05, 0x52, 0x05, 0x72,
+	0x65, 0x6c, 0x6f, 0x6a, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x64, 0x4e, 0x6f, 0x64, 0x6f, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x64, 0x4e, 0x6f, 0x64, 0x6f, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x6e, 0x64, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x6e,
+	0x64, 0x61, 0x22, 0x26, 0x0a, 0x08, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x08, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x22, 0x62, 0x0a, 0x0d, 0x53, 0x6f,
+	0x6c, 0x69, 0x63, 0x69, 0x74, 0x75, 0x64, 0x4e, 0x6f, 0x64, 0x6f, 0x12, 0x24, 0x0a, 0x0d, 0x6e,
+	0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x6e, 0x6f, 0x6d, 0x62, 0x72, 0x65, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69,
+	0x6f, 0x12, 0x2b, 0x0a, 0x08, 0x61, 0x66, 0x69, 0x6e, 0x69, 0x64, 0x61, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x6a, 0x61,
+	0x63, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x61, 0x66, 0x69, 0x6e, 0x69, 0x64, 0x61, 0x64, 0x22, 0x58,
+	0x0a, 0x0c, 0x4e, 0x6f, 0x64, 0x6f, 0x41, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x64, 0x6f, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6f,
+	0x72, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x05, 0x52, 0x05, 0x72, 0x65, 0x6c, 0x6f, 0x6a, 0x22, 0x6f, 0x0a, 0x07, 0x4d, 0x69, 0x65, 0x6d,
+	0x62, 0x72, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x6e, 0x73, 0x50, 0x6f,
+	0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x6e, 0x73, 0x50, 0x6f, 0x72,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x22, 0x4d, 0x0a, 0x05, 0x56, 0x69, 0x73,
+	0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x08,
+	0x6d, 0x69, 0x65, 0x6d, 0x62, 0x72, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d, 0x69, 0x65, 0x6d, 0x62, 0x72, 0x6f, 0x52, 0x08,
+	0x6d, 0x69, 0x65, 0x6d, 0x62, 0x72, 0x6f, 0x73, 0x22, 0xac, 0x01, 0x0a, 0x06, 0x4c, 0x61, 0x74,
+	0x69, 0x64, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72,
+	0x61, 0x63, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x67, 0x65, 0x6e,
+	0x65, 0x72, 0x61, 0x63, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x64, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x64, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x73,
+	0x65, 0x67, 0x75, 0x6e, 0x64, 0x6f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x73,
+	0x65, 0x67, 0x75, 0x6e, 0x64, 0x6f, 0x73, 0x22, 0xba, 0x01, 0x0a, 0x07, 0x4c, 0x61, 0x74, 0x69,
+	0x64, 0x6f, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x64, 0x4e, 0x6f, 0x64, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x64, 0x4e, 0x6f, 0x64, 0x6f, 0x12, 0x27, 0x0a, 0x07, 0x6c,
+	0x61, 0x74, 0x69, 0x64, 0x6f, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x61, 0x74, 0x69, 0x64, 0x6f, 0x52, 0x07, 0x6c, 0x61, 0x74,
+	0x69, 0x64, 0x6f, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x52, 0x61, 0x66,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x52, 0x61,
+	0x66, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x52, 0x61, 0x66,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f,
+	0x52, 0x61, 0x66, 0x74, 0x22, 0x47, 0x0a, 0x0d, 0x53, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x75,
+	0x64, 0x56, 0x6f, 0x74, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x12,
+	0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x22, 0x5f, 0x0a,
+	0x09, 0x4c, 0x69, 0x64, 0x65, 0x72, 0x61, 0x7a, 0x67, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x6f,
+	0x6e, 0x64, 0x61, 0x45, 0x6e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x72, 0x6f, 0x6e, 0x64, 0x61, 0x45, 0x6e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x22, 0x3e,
+	0x0a, 0x04, 0x56, 0x6f, 0x74, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x63, 0x65, 0x64, 0x69, 0x64, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x63, 0x65, 0x64, 0x69, 0x64, 0x6f, 0x22, 0x73,
+	0x0a, 0x05, 0x52, 0x6f, 0x6e, 0x64, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x61, 0x73, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x61, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63,
+	0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x64, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x64, 0x6f, 0x72, 0x12, 0x24, 0x0a,
+	0x0d, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e,
+	0x74, 0x65, 0x73, 0x22, 0x35, 0x0a, 0x0b, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x52, 0x6f, 0x6e,
+	0x64, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x65, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x22, 0x95, 0x01, 0x0a, 0x11, 0x53,
+	0x6f, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x75, 0x64, 0x56, 0x6f, 0x74, 0x6f, 0x52, 0x61, 0x66, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61,
+	0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63,
+	0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x12, 0x22, 0x0a, 0x0c, 0x75, 0x6c, 0x74, 0x69,
+	0x6d, 0x6f, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
+	0x75, 0x6c, 0x74, 0x69, 0x6d, 0x6f, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x12, 0x24, 0x0a, 0x0d,
+	0x75, 0x6c, 0x74, 0x69, 0x6d, 0x6f, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0d, 0x75, 0x6c, 0x74, 0x69, 0x6d, 0x6f, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x6f, 0x22, 0x41, 0x0a, 0x0b, 0x45, 0x6e, 0x74, 0x72, 0x61, 0x64, 0x61, 0x52, 0x61, 0x66,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6f, 0x6d, 0x61, 0x6e, 0x64, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f,
+	0x6d, 0x61, 0x6e, 0x64, 0x6f, 0x22, 0xe0, 0x01, 0x0a, 0x0c, 0x45, 0x6e, 0x74, 0x72, 0x61, 0x64,
+	0x61, 0x73, 0x52, 0x61, 0x66, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x0e, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65,
+	0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e,
+	0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x28,
+	0x0a, 0x0f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f,
+	0x41, 0x6e, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x2e, 0x0a, 0x08, 0x65, 0x6e, 0x74, 0x72,
+	0x61, 0x64, 0x61, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x61, 0x64, 0x61, 0x52, 0x61, 0x66, 0x74, 0x52, 0x08,
+	0x65, 0x6e, 0x74, 0x72, 0x61, 0x64, 0x61, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x72, 0x6d, 0x61, 0x64, 0x6f, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x64, 0x6f, 0x22, 0x92, 0x01, 0x0a, 0x0c, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x61, 0x66, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x6c, 0x69, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x6e, 0x64,
+	0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x69, 0x6e, 0x64, 0x69, 0x63,
+	0x65, 0x12, 0x24, 0x0a, 0x0d, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x49, 0x6e, 0x64, 0x69,
+	0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x6f, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x61, 0x74, 0x6f, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x64, 0x61, 0x74, 0x6f, 0x73, 0x22, 0x63, 0x0a,
+	0x0d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x52, 0x61, 0x66, 0x74, 0x12, 0x18,
+	0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x78, 0x69, 0x74,
+	0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x78, 0x69, 0x74, 0x6f, 0x12, 0x22,
+	0x0a, 0x0c, 0x75, 0x6c, 0x74, 0x69, 0x6d, 0x6f, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x75, 0x6c, 0x74, 0x69, 0x6d, 0x6f, 0x49, 0x6e, 0x64, 0x69,
+	0x63, 0x65, 0x32, 0xa9, 0x08, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x69, 0x6f, 0x4e,
+	0x6f, 0x64, 0x6f, 0x12, 0x2f, 0x0a, 0x0d, 0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x45, 0x73,
+	0x74, 0x61, 0x64, 0x6f, 0x12, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e,
+	0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a, 0x0d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x73,
+	0x74, 0x61, 0x64, 0x6f, 0x12, 0x28, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0f, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a, 0x10, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x12, 0x2b,
+	0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x12, 0x35, 0x0a, 0x06, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f,
+	0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x1a, 0x15, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x75, 0x65, 0x73, 0x74, 0x61, 0x41, 0x64, 0x6d,
+	0x69, 0x6e, 0x12, 0x36, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x15, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x70,
+	0x75, 0x65, 0x73, 0x74, 0x61, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x29, 0x0a, 0x07, 0x47, 0x65,
+	0x74, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x0f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f,
+	0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x1a, 0x0b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46,
+	0x69, 0x6c, 0x65, 0x30, 0x01, 0x12, 0x27, 0x0a, 0x07, 0x53, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x65,
+	0x12, 0x0b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x1a, 0x0d, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x28, 0x01, 0x12, 0x2c,
+	0x0a, 0x0b, 0x47, 0x65, 0x74, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x12, 0x0c, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x61, 0x63, 0x69, 0x6f, 0x1a, 0x0f, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x6f, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x73, 0x12, 0x38, 0x0a, 0x0b,
+	0x41, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x72, 0x4e, 0x6f, 0x64, 0x6f, 0x12, 0x14, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x75, 0x64, 0x4e, 0x6f, 0x64,
+	0x6f, 0x1a, 0x13, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4e, 0x6f, 0x64, 0x6f, 0x41, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x64, 0x6f, 0x12, 0x24, 0x0a, 0x04, 0x4a, 0x6f, 0x69, 0x6e, 0x12, 0x0e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d, 0x69, 0x65, 0x6d, 0x62, 0x72, 0x6f, 0x1a, 0x0c,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x69, 0x73, 0x74, 0x61, 0x12, 0x25, 0x0a, 0x05,
+	0x4c, 0x65, 0x61, 0x76, 0x65, 0x12, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d, 0x69,
+	0x65, 0x6d, 0x62, 0x72, 0x6f, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x69,
+	0x73, 0x74, 0x61, 0x12, 0x2d, 0x0a, 0x0f, 0x41, 0x63, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61,
+	0x72, 0x56, 0x69, 0x73, 0x74, 0x61, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56,
+	0x69, 0x73, 0x74, 0x61, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x69, 0x73,
+	0x74, 0x61, 0x12, 0x31, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x69, 0x72,
+	0x45, 0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d,
+	0x69, 0x65, 0x6d, 0x62, 0x72, 0x6f, 0x1a, 0x0d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45,
+	0x73, 0x74, 0x61, 0x64, 0x6f, 0x12, 0x35, 0x0a, 0x13, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x61,
+	0x6d, 0x62, 0x69, 0x61, 0x72, 0x4c, 0x61, 0x74, 0x69, 0x64, 0x6f, 0x73, 0x12, 0x0e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x61, 0x74, 0x69, 0x64, 0x6f, 0x73, 0x1a, 0x0e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x61, 0x74, 0x69, 0x64, 0x6f, 0x73, 0x12, 0x2c, 0x0a, 0x0c,
+	0x4f, 0x62, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x53, 0x61, 0x6c, 0x75, 0x64, 0x12, 0x0c, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x61, 0x63, 0x69, 0x6f, 0x1a, 0x0e, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x4c, 0x61, 0x74, 0x69, 0x64, 0x6f, 0x73, 0x12, 0x32, 0x0a, 0x0d, 0x53, 0x6f,
+	0x6c, 0x69, 0x63, 0x69, 0x74, 0x61, 0x72, 0x56, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x75, 0x64, 0x56, 0x6f, 0x74,
+	0x6f, 0x1a, 0x0b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x6f, 0x74, 0x6f, 0x12, 0x31,
+	0x0a, 0x10, 0x52, 0x65, 0x6e, 0x6f, 0x76, 0x61, 0x72, 0x4c, 0x69, 0x64, 0x65, 0x72, 0x61, 0x7a,
+	0x67, 0x6f, 0x12, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x69, 0x64, 0x65, 0x72,
+	0x61, 0x7a, 0x67, 0x6f, 0x1a, 0x0b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x6f, 0x74,
+	0x6f, 0x12, 0x32, 0x0a, 0x0e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x72, 0x52, 0x6f,
+	0x6e, 0x64, 0x61, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x6f, 0x6e, 0x64,
+	0x61, 0x1a, 0x12, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x73, 0x74, 0x61, 0x64, 0x6f,
+	0x52, 0x6f, 0x6e, 0x64, 0x61, 0x12, 0x3a, 0x0a, 0x11, 0x53, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x74,
+	0x61, 0x72, 0x56, 0x6f, 0x74, 0x6f, 0x52, 0x61, 0x66, 0x74, 0x12, 0x18, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x53, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x74, 0x75, 0x64, 0x56, 0x6f, 0x74, 0x6f,
+	0x52, 0x61, 0x66, 0x74, 0x1a, 0x0b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x6f, 0x74,
+	0x6f, 0x12, 0x3c, 0x0a, 0x0f, 0x41, 0x67, 0x72, 0x65, 0x67, 0x61, 0x72, 0x45, 0x6e, 0x74, 0x72,
+	0x61, 0x64, 0x61, 0x73, 0x12, 0x13, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6e, 0x74,
+	0x72, 0x61, 0x64, 0x61, 0x73, 0x52, 0x61, 0x66, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x52, 0x61, 0x66, 0x74, 0x12,
+	0x3d, 0x0a, 0x10, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6c, 0x61, 0x72, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x12, 0x13, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x52, 0x61, 0x66, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x52, 0x61, 0x66, 0x74, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_nodo_proto_rawDescOnce sync.Once
+	file_nodo_proto_rawDescData = file_nodo_proto_rawDesc
+)
+
+func file_nodo_proto_rawDescGZIP() []byte {
+	file_nodo_proto_rawDescOnce.Do(func() {
+		file_nodo_proto_rawDescData = protoimpl.X.CompressGZIP(file_nodo_proto_rawDescData)
+	})
+	return file_nodo_proto_rawDescData
+}
+
+var file_nodo_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_nodo_proto_goTypes = []interface{}{
+	(*Vacio)(nil),             // 0: proto.Vacio
+	(*Estado)(nil),            // 1: proto.Estado
+	(*Recurso)(nil),           // 2: proto.Recurso
+	(*Fijacion)(nil),          // 3: proto.Fijacion
+	(*Consulta)(nil),          // 4: proto.Consulta
+	(*ConsultaAdmin)(nil),     // 5: proto.ConsultaAdmin
+	(*ConsultaUpdate)(nil),    // 6: proto.ConsultaUpdate
+	(*Respuesta)(nil),         // 7: proto.Respuesta
+	(*RespuestaAdmin)(nil),    // 8: proto.RespuestaAdmin
+	(*File)(nil),              // 9: proto.File
+	(*Dominios)(nil),          // 10: proto.Dominios
+	(*SolicitudNodo)(nil),     // 11: proto.SolicitudNodo
+	(*NodoAsignado)(nil),      // 12: proto.NodoAsignado
+	(*Miembro)(nil),           // 13: proto.Miembro
+	(*Vista)(nil),             // 14: proto.Vista
+	(*Latido)(nil),            // 15: proto.Latido
+	(*Latidos)(nil),           // 16: proto.Latidos
+	(*SolicitudVoto)(nil),     // 17: proto.SolicitudVoto
+	(*Liderazgo)(nil),         // 18: proto.Liderazgo
+	(*Voto)(nil),              // 19: proto.Voto
+	(*Ronda)(nil),             // 20: proto.Ronda
+	(*EstadoRonda)(nil),       // 21: proto.EstadoRonda
+	(*SolicitudVotoRaft)(nil), // 22: proto.SolicitudVotoRaft
+	(*EntradaRaft)(nil),       // 23: proto.EntradaRaft
+	(*EntradasRaft)(nil),      // 24: proto.EntradasRaft
+	(*SnapshotRaft)(nil),      // 25: proto.SnapshotRaft
+	(*ResultadoRaft)(nil),     // 26: proto.ResultadoRaft
+}
+var file_nodo_proto_depIdxs = []int32{
+	2,  // 0: proto.Consulta.recurso:type_name -> proto.Recurso
+	3,  // 1: proto.Consulta.fijacion:type_name -> proto.Fijacion
+	2,  // 2: proto.ConsultaAdmin.recurso:type_name -> proto.Recurso
+	3,  // 3: proto.ConsultaAdmin.fijacion:type_name -> proto.Fijacion
+	3,  // 4: proto.ConsultaUpdate.fijacion:type_name -> proto.Fijacion
+	2,  // 5: proto.Respuesta.recursos:type_name -> proto.Recurso
+	3,  // 6: proto.SolicitudNodo.afinidad:type_name -> proto.Fijacion
+	13, // 7: proto.Vista.miembros:type_name -> proto.Miembro
+	15, // 8: proto.Latidos.latidos:type_name -> proto.Latido
+	23, // 9: proto.EntradasRaft.entradas:type_name -> proto.EntradaRaft
+	4,  // 10: proto.ServicioNodo.ObtenerEstado:input_type -> proto.Consulta
+	4,  // 11: proto.ServicioNodo.Get:input_type -> proto.Consulta
+	4,  // 12: proto.ServicioNodo.Create:input_type -> proto.Consulta
+	5,  // 13: proto.ServicioNodo.Delete:input_type -> proto.ConsultaAdmin
+	6,  // 14: proto.ServicioNodo.Update:input_type -> proto.ConsultaUpdate
+	4,  // 15: proto.ServicioNodo.GetFile:input_type -> proto.Consulta
+	9,  // 16: proto.ServicioNodo.SetFile:input_type -> proto.File
+	0,  // 17: proto.ServicioNodo.GetDominios:input_type -> proto.Vacio
+	11, // 18: proto.ServicioNodo.AsignarNodo:input_type -> proto.SolicitudNodo
+	13, // 19: proto.ServicioNodo.Join:input_type -> proto.Miembro
+	13, // 20: proto.ServicioNodo.Leave:input_type -> proto.Miembro
+	14, // 21: proto.ServicioNodo.ActualizarVista:input_type -> proto.Vista
+	13, // 22: proto.ServicioNodo.TransferirEstado:input_type -> proto.Miembro
+	16, // 23: proto.ServicioNodo.IntercambiarLatidos:input_type -> proto.Latidos
+	0,  // 24: proto.ServicioNodo.ObtenerSalud:input_type -> proto.Vacio
+	17, // 25: proto.ServicioNodo.SolicitarVoto:input_type -> proto.SolicitudVoto
+	18, // 26: proto.ServicioNodo.RenovarLiderazgo:input_type -> proto.Liderazgo
+	20, // 27: proto.ServicioNodo.CoordinarRonda:input_type -> proto.Ronda
+	22, // 28: proto.ServicioNodo.SolicitarVotoRaft:input_type -> proto.SolicitudVotoRaft
+	24, // 29: proto.ServicioNodo.AgregarEntradas:input_type -> proto.EntradasRaft
+	25, // 30: proto.ServicioNodo.InstalarSnapshot:input_type -> proto.SnapshotRaft
+	1,  // 31: proto.ServicioNodo.ObtenerEstado:output_type -> proto.Estado
+	7,  // 32: proto.ServicioNodo.Get:output_type -> proto.Respuesta
+	7,  // 33: proto.ServicioNodo.Create:output_type -> proto.Respuesta
+	8,  // 34: proto.ServicioNodo.Delete:output_type -> proto.RespuestaAdmin
+	8,  // 35: proto.ServicioNodo.Update:output_type -> proto.RespuestaAdmin
+	9,  // 36: proto.ServicioNodo.GetFile:output_type -> proto.File
+	1,  // 37: proto.ServicioNodo.SetFile:output_type -> proto.Estado
+	10, // 38: proto.ServicioNodo.GetDominios:output_type -> proto.Dominios
+	12, // 39: proto.ServicioNodo.AsignarNodo:output_type -> proto.NodoAsignado
+	14, // 40: proto.ServicioNodo.Join:output_type -> proto.Vista
+	14, // 41: proto.ServicioNodo.Leave:output_type -> proto.Vista
+	14, // 42: proto.ServicioNodo.ActualizarVista:output_type -> proto.Vista
+	1,  // 43: proto.ServicioNodo.TransferirEstado:output_type -> proto.Estado
+	16, // 44: proto.ServicioNodo.IntercambiarLatidos:output_type -> proto.Latidos
+	16, // 45: proto.ServicioNodo.ObtenerSalud:output_type -> proto.Latidos
+	19, // 46: proto.ServicioNodo.SolicitarVoto:output_type -> proto.Voto
+	19, // 47: proto.ServicioNodo.RenovarLiderazgo:output_type -> proto.Voto
+	21, // 48: proto.ServicioNodo.CoordinarRonda:output_type -> proto.EstadoRonda
+	19, // 49: proto.ServicioNodo.SolicitarVotoRaft:output_type -> proto.Voto
+	26, // 50: proto.ServicioNodo.AgregarEntradas:output_type -> proto.ResultadoRaft
+	26, // 51: proto.ServicioNodo.InstalarSnapshot:output_type -> proto.ResultadoRaft
+	31, // [31:52] is the sub-list for method output_type
+	10, // [10:31] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_nodo_proto_init() }
+func file_nodo_proto_init() {
+	if File_nodo_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_nodo_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vacio); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Estado); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Recurso); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Estado); i {
+		file_nodo_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fijacion); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -645,7 +2279,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Consulta); i {
 			case 0:
 				return &v.state
@@ -657,7 +2291,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ConsultaAdmin); i {
 			case 0:
 				return &v.state
@@ -669,7 +2303,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ConsultaUpdate); i {
 			case 0:
 				return &v.state
@@ -681,7 +2315,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Respuesta); i {
 			case 0:
 				return &v.state
@@ -693,7 +2327,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RespuestaAdmin); i {
 			case 0:
 				return &v.state
@@ -705,7 +2339,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*File); i {
 			case 0:
 				return &v.state
@@ -717,7 +2351,7 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
-		file_nodo_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_nodo_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Dominios); i {
 			case 0:
 				return &v.state
@@ -729,6 +2363,198 @@ func file_nodo_proto_init() {
 				return nil
 			}
 		}
+		file_nodo_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SolicitudNodo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodoAsignado); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Miembro); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vista); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Latido); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Latidos); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SolicitudVoto); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Liderazgo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Voto); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ronda); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EstadoRonda); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SolicitudVotoRaft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntradaRaft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntradasRaft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotRaft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nodo_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResultadoRaft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -736,7 +2562,7 @@ func file_nodo_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_nodo_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -770,6 +2596,19 @@ type ServicioNodoClient interface {
 	GetFile(ctx context.Context, in *Consulta, opts ...grpc.CallOption) (ServicioNodo_GetFileClient, error)
 	SetFile(ctx context.Context, opts ...grpc.CallOption) (ServicioNodo_SetFileClient, error)
 	GetDominios(ctx context.Context, in *Vacio, opts ...grpc.CallOption) (*Dominios, error)
+	AsignarNodo(ctx context.Context, in *SolicitudNodo, opts ...grpc.CallOption) (*NodoAsignado, error)
+	Join(ctx context.Context, in *Miembro, opts ...grpc.CallOption) (*Vista, error)
+	Leave(ctx context.Context, in *Miembro, opts ...grpc.CallOption) (*Vista, error)
+	ActualizarVista(ctx context.Context, in *Vista, opts ...grpc.CallOption) (*Vista, error)
+	TransferirEstado(ctx context.Context, in *Miembro, opts ...grpc.CallOption) (*Estado, error)
+	IntercambiarLatidos(ctx context.Context, in *Latidos, opts ...grpc.CallOption) (*Latidos, error)
+	ObtenerSalud(ctx context.Context, in *Vacio, opts ...grpc.CallOption) (*Latidos, error)
+	SolicitarVoto(ctx context.Context, in *SolicitudVoto, opts ...grpc.CallOption) (*Voto, error)
+	RenovarLiderazgo(ctx context.Context, in *Liderazgo, opts ...grpc.CallOption) (*Voto, error)
+	CoordinarRonda(ctx context.Context, in *Ronda, opts ...grpc.CallOption) (*EstadoRonda, error)
+	SolicitarVotoRaft(ctx context.Context, in *SolicitudVotoRaft, opts ...grpc.CallOption) (*Voto, error)
+	AgregarEntradas(ctx context.Context, in *EntradasRaft, opts ...grpc.CallOption) (*ResultadoRaft, error)
+	InstalarSnapshot(ctx context.Context, in *SnapshotRaft, opts ...grpc.CallOption) (*ResultadoRaft, error)
 }
 
 type servicioNodoClient struct {
@@ -849,51 +2688,168 @@ type servicioNodoGetFileClient struct {
 	grpc.ClientStream
 }
 
-func (x *servicioNodoGetFileClient) Recv() (*File, error) {
-	m := new(File)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (x *servicioNodoGetFileClient) Recv() (*File, error) {
+	m := new(File)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *servicioNodoClient) SetFile(ctx context.Context, opts ...grpc.CallOption) (ServicioNodo_SetFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ServicioNodo_serviceDesc.Streams[1], "/proto.ServicioNodo/SetFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &servicioNodoSetFileClient{stream}
+	return x, nil
+}
+
+type ServicioNodo_SetFileClient interface {
+	Send(*File) error
+	CloseAndRecv() (*Estado, error)
+	grpc.ClientStream
+}
+
+type servicioNodoSetFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *servicioNodoSetFileClient) Send(m *File) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *servicioNodoSetFileClient) CloseAndRecv() (*Estado, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Estado)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *servicioNodoClient) GetDominios(ctx context.Context, in *Vacio, opts ...grpc.CallOption) (*Dominios, error) {
+	out := new(Dominios)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/GetDominios", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *servicioNodoClient) AsignarNodo(ctx context.Context, in *SolicitudNodo, opts ...grpc.CallOption) (*NodoAsignado, error) {
+	out := new(NodoAsignado)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/AsignarNodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *servicioNodoClient) Join(ctx context.Context, in *Miembro, opts ...grpc.CallOption) (*Vista, error) {
+	out := new(Vista)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/Join", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *servicioNodoClient) Leave(ctx context.Context, in *Miembro, opts ...grpc.CallOption) (*Vista, error) {
+	out := new(Vista)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/Leave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *servicioNodoClient) ActualizarVista(ctx context.Context, in *Vista, opts ...grpc.CallOption) (*Vista, error) {
+	out := new(Vista)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/ActualizarVista", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *servicioNodoClient) TransferirEstado(ctx context.Context, in *Miembro, opts ...grpc.CallOption) (*Estado, error) {
+	out := new(Estado)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/TransferirEstado", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *servicioNodoClient) IntercambiarLatidos(ctx context.Context, in *Latidos, opts ...grpc.CallOption) (*Latidos, error) {
+	out := new(Latidos)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/IntercambiarLatidos", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *servicioNodoClient) SetFile(ctx context.Context, opts ...grpc.CallOption) (ServicioNodo_SetFileClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_ServicioNodo_serviceDesc.Streams[1], "/proto.ServicioNodo/SetFile", opts...)
+func (c *servicioNodoClient) ObtenerSalud(ctx context.Context, in *Vacio, opts ...grpc.CallOption) (*Latidos, error) {
+	out := new(Latidos)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/ObtenerSalud", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &servicioNodoSetFileClient{stream}
-	return x, nil
+	return out, nil
 }
 
-type ServicioNodo_SetFileClient interface {
-	Send(*File) error
-	CloseAndRecv() (*Estado, error)
-	grpc.ClientStream
+func (c *servicioNodoClient) SolicitarVoto(ctx context.Context, in *SolicitudVoto, opts ...grpc.CallOption) (*Voto, error) {
+	out := new(Voto)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/SolicitarVoto", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type servicioNodoSetFileClient struct {
-	grpc.ClientStream
+func (c *servicioNodoClient) RenovarLiderazgo(ctx context.Context, in *Liderazgo, opts ...grpc.CallOption) (*Voto, error) {
+	out := new(Voto)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/RenovarLiderazgo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *servicioNodoSetFileClient) Send(m *File) error {
-	return x.ClientStream.SendMsg(m)
+func (c *servicioNodoClient) CoordinarRonda(ctx context.Context, in *Ronda, opts ...grpc.CallOption) (*EstadoRonda, error) {
+	out := new(EstadoRonda)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/CoordinarRonda", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *servicioNodoSetFileClient) CloseAndRecv() (*Estado, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
+func (c *servicioNodoClient) SolicitarVotoRaft(ctx context.Context, in *SolicitudVotoRaft, opts ...grpc.CallOption) (*Voto, error) {
+	out := new(Voto)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/SolicitarVotoRaft", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	m := new(Estado)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+	return out, nil
+}
+
+func (c *servicioNodoClient) AgregarEntradas(ctx context.Context, in *EntradasRaft, opts ...grpc.CallOption) (*ResultadoRaft, error) {
+	out := new(ResultadoRaft)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/AgregarEntradas", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *servicioNodoClient) GetDominios(ctx context.Context, in *Vacio, opts ...grpc.CallOption) (*Dominios, error) {
-	out := new(Dominios)
-	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/GetDominios", in, out, opts...)
+func (c *servicioNodoClient) InstalarSnapshot(ctx context.Context, in *SnapshotRaft, opts ...grpc.CallOption) (*ResultadoRaft, error) {
+	out := new(ResultadoRaft)
+	err := c.cc.Invoke(ctx, "/proto.ServicioNodo/InstalarSnapshot", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -910,6 +2866,19 @@ type ServicioNodoServer interface {
 	GetFile(*Consulta, ServicioNodo_GetFileServer) error
 	SetFile(ServicioNodo_SetFileServer) error
 	GetDominios(context.Context, *Vacio) (*Dominios, error)
+	AsignarNodo(context.Context, *SolicitudNodo) (*NodoAsignado, error)
+	Join(context.Context, *Miembro) (*Vista, error)
+	Leave(context.Context, *Miembro) (*Vista, error)
+	ActualizarVista(context.Context, *Vista) (*Vista, error)
+	TransferirEstado(context.Context, *Miembro) (*Estado, error)
+	IntercambiarLatidos(context.Context, *Latidos) (*Latidos, error)
+	ObtenerSalud(context.Context, *Vacio) (*Latidos, error)
+	SolicitarVoto(context.Context, *SolicitudVoto) (*Voto, error)
+	RenovarLiderazgo(context.Context, *Liderazgo) (*Voto, error)
+	CoordinarRonda(context.Context, *Ronda) (*EstadoRonda, error)
+	SolicitarVotoRaft(context.Context, *SolicitudVotoRaft) (*Voto, error)
+	AgregarEntradas(context.Context, *EntradasRaft) (*ResultadoRaft, error)
+	InstalarSnapshot(context.Context, *SnapshotRaft) (*ResultadoRaft, error)
 }
 
 // UnimplementedServicioNodoServer can be embedded to have forward compatible implementations.
@@ -940,6 +2909,45 @@ func (*UnimplementedServicioNodoServer) SetFile(ServicioNodo_SetFileServer) erro
 func (*UnimplementedServicioNodoServer) GetDominios(context.Context, *Vacio) (*Dominios, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetDominios not implemented")
 }
+func (*UnimplementedServicioNodoServer) AsignarNodo(context.Context, *SolicitudNodo) (*NodoAsignado, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AsignarNodo not implemented")
+}
+func (*UnimplementedServicioNodoServer) Join(context.Context, *Miembro) (*Vista, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Join not implemented")
+}
+func (*UnimplementedServicioNodoServer) Leave(context.Context, *Miembro) (*Vista, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Leave not implemented")
+}
+func (*UnimplementedServicioNodoServer) ActualizarVista(context.Context, *Vista) (*Vista, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActualizarVista not implemented")
+}
+func (*UnimplementedServicioNodoServer) TransferirEstado(context.Context, *Miembro) (*Estado, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferirEstado not implemented")
+}
+func (*UnimplementedServicioNodoServer) IntercambiarLatidos(context.Context, *Latidos) (*Latidos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IntercambiarLatidos not implemented")
+}
+func (*UnimplementedServicioNodoServer) ObtenerSalud(context.Context, *Vacio) (*Latidos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ObtenerSalud not implemented")
+}
+func (*UnimplementedServicioNodoServer) SolicitarVoto(context.Context, *SolicitudVoto) (*Voto, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SolicitarVoto not implemented")
+}
+func (*UnimplementedServicioNodoServer) RenovarLiderazgo(context.Context, *Liderazgo) (*Voto, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenovarLiderazgo not implemented")
+}
+func (*UnimplementedServicioNodoServer) CoordinarRonda(context.Context, *Ronda) (*EstadoRonda, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CoordinarRonda not implemented")
+}
+func (*UnimplementedServicioNodoServer) SolicitarVotoRaft(context.Context, *SolicitudVotoRaft) (*Voto, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SolicitarVotoRaft not implemented")
+}
+func (*UnimplementedServicioNodoServer) AgregarEntradas(context.Context, *EntradasRaft) (*ResultadoRaft, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AgregarEntradas not implemented")
+}
+func (*UnimplementedServicioNodoServer) InstalarSnapshot(context.Context, *SnapshotRaft) (*ResultadoRaft, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstalarSnapshot not implemented")
+}
 
 func RegisterServicioNodoServer(s *grpc.Server, srv ServicioNodoServer) {
 	s.RegisterService(&_ServicioNodo_serviceDesc, srv)
@@ -1100,6 +3108,240 @@ func _ServicioNodo_GetDominios_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServicioNodo_AsignarNodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolicitudNodo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).AsignarNodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/AsignarNodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).AsignarNodo(ctx, req.(*SolicitudNodo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_Join_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Miembro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).Join(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/Join",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).Join(ctx, req.(*Miembro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_Leave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Miembro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).Leave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/Leave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).Leave(ctx, req.(*Miembro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_ActualizarVista_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vista)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).ActualizarVista(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/ActualizarVista",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).ActualizarVista(ctx, req.(*Vista))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_TransferirEstado_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Miembro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).TransferirEstado(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/TransferirEstado",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).TransferirEstado(ctx, req.(*Miembro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_IntercambiarLatidos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Latidos)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).IntercambiarLatidos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/IntercambiarLatidos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).IntercambiarLatidos(ctx, req.(*Latidos))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_ObtenerSalud_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Vacio)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).ObtenerSalud(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/ObtenerSalud",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).ObtenerSalud(ctx, req.(*Vacio))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_SolicitarVoto_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolicitudVoto)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).SolicitarVoto(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/SolicitarVoto",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).SolicitarVoto(ctx, req.(*SolicitudVoto))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_RenovarLiderazgo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Liderazgo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).RenovarLiderazgo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/RenovarLiderazgo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).RenovarLiderazgo(ctx, req.(*Liderazgo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_CoordinarRonda_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Ronda)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).CoordinarRonda(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/CoordinarRonda",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).CoordinarRonda(ctx, req.(*Ronda))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_SolicitarVotoRaft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolicitudVotoRaft)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).SolicitarVotoRaft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/SolicitarVotoRaft",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).SolicitarVotoRaft(ctx, req.(*SolicitudVotoRaft))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_AgregarEntradas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EntradasRaft)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).AgregarEntradas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/AgregarEntradas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).AgregarEntradas(ctx, req.(*EntradasRaft))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServicioNodo_InstalarSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRaft)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServicioNodoServer).InstalarSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ServicioNodo/InstalarSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServicioNodoServer).InstalarSnapshot(ctx, req.(*SnapshotRaft))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ServicioNodo_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.ServicioNodo",
 	HandlerType: (*ServicioNodoServer)(nil),
@@ -1128,6 +3370,58 @@ var _ServicioNodo_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetDominios",
 			Handler:    _ServicioNodo_GetDominios_Handler,
 		},
+		{
+			MethodName: "AsignarNodo",
+			Handler:    _ServicioNodo_AsignarNodo_Handler,
+		},
+		{
+			MethodName: "Join",
+			Handler:    _ServicioNodo_Join_Handler,
+		},
+		{
+			MethodName: "Leave",
+			Handler:    _ServicioNodo_Leave_Handler,
+		},
+		{
+			MethodName: "ActualizarVista",
+			Handler:    _ServicioNodo_ActualizarVista_Handler,
+		},
+		{
+			MethodName: "TransferirEstado",
+			Handler:    _ServicioNodo_TransferirEstado_Handler,
+		},
+		{
+			MethodName: "IntercambiarLatidos",
+			Handler:    _ServicioNodo_IntercambiarLatidos_Handler,
+		},
+		{
+			MethodName: "ObtenerSalud",
+			Handler:    _ServicioNodo_ObtenerSalud_Handler,
+		},
+		{
+			MethodName: "SolicitarVoto",
+			Handler:    _ServicioNodo_SolicitarVoto_Handler,
+		},
+		{
+			MethodName: "RenovarLiderazgo",
+			Handler:    _ServicioNodo_RenovarLiderazgo_Handler,
+		},
+		{
+			MethodName: "CoordinarRonda",
+			Handler:    _ServicioNodo_CoordinarRonda_Handler,
+		},
+		{
+			MethodName: "SolicitarVotoRaft",
+			Handler:    _ServicioNodo_SolicitarVotoRaft_Handler,
+		},
+		{
+			MethodName: "AgregarEntradas",
+			Handler:    _ServicioNodo_AgregarEntradas_Handler,
+		},
+		{
+			MethodName: "InstalarSnapshot",
+			Handler:    _ServicioNodo_InstalarSnapshot_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,839 @@
+package raft
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfomu/DNSDistribuido/internal/archivos"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+)
+
+// Roles de un nodo del grupo
+const (
+	SEGUIDOR = "SEGUIDOR"
+	CANDIDATO = "CANDIDATO"
+	LIDER = "LIDER"
+)
+
+const (
+	INTERVALO_LATIDO = 200 * time.Millisecond // cada cuanto el líder envía entradas o latidos a los seguidores
+	TIEMPO_ELECCION = 1 * time.Second // sin noticias del líder por este tiempo, más un tiempo aleatorio de hasta el mismo largo, el nodo se postula
+	TIEMPO_RPC = 1 * time.Second // tiempo máximo de respuesta a cada solicitud entre nodos
+	ENTRADAS_SNAPSHOT = 1000 // entradas aplicadas tras las cuales se toma un snapshot y se compacta el log
+	MAX_ENTRADAS = 256 // entradas enviadas como máximo en cada AgregarEntradas
+)
+
+// Entrada del log replicado
+type Entrada struct{
+	Indice uint64
+	Termino uint64
+	Comando []byte // vacío en la entrada con que un líder inicia su término, que no se aplica
+}
+
+// Máquina de estados replicada. Las entradas confirmadas se aplican en el mismo orden en todos
+// los nodos, por lo que Aplicar debe ser determinista: sus errores también son parte del resultado.
+// Snapshot solo lee el estado; una vez persistido el snapshot se llama a Compactar, sin aplicar
+// entradas entre ambos, para que la máquina descarte lo que guarda y el snapshot ya incluye.
+type Maquina interface{
+	Aplicar(comando []byte) (interface{}, error)
+	Snapshot() ([]byte, error)
+	Compactar() error
+	Restaurar(datos []byte) error // datos nil reinicia la máquina a su estado inicial
+}
+
+// Envío de las solicitudes de Raft a los demás nodos del grupo, identificados por su ID
+type Transporte interface{
+	SolicitarVoto(ctx context.Context, id string, solicitud *pb.SolicitudVotoRaft) (*pb.Voto, error)
+	AgregarEntradas(ctx context.Context, id string, entradas *pb.EntradasRaft) (*pb.ResultadoRaft, error)
+	InstalarSnapshot(ctx context.Context, id string, snapshot *pb.SnapshotRaft) (*pb.ResultadoRaft, error)
+}
+
+// Error al replicar una propuesta, a diferencia de los errores de la máquina de estados. Si la
+// propuesta no se confirmó a tiempo su resultado es incierto: la entrada aún puede confirmarse.
+type Error struct{
+	Descripcion string
+	Incierto bool
+}
+
+func (e *Error) Error() string {
+	return e.Descripcion
+}
+
+func reemplazada(indice uint64) *Error {
+	return &Error{Descripcion: "La entrada " + strconv.FormatUint(indice, 10) + " fue reemplazada por la de un nuevo líder"}
+}
+
+type resultado struct{
+	valor interface{}
+	err error
+}
+
+// Propuesta del líder que espera a que su entrada se aplique
+type espera struct{
+	termino uint64
+	listo chan resultado
+}
+
+// Nodo de un grupo Raft con miembros fijos. El líder agrega las propuestas a su log y las replica
+// en los seguidores; una entrada se confirma al estar en el log de la mayoría del grupo y entonces
+// se aplica a la máquina de estados en cada nodo. El término, el voto, el log y el snapshot se
+// persisten antes de responder, de modo que un nodo reiniciado recupera su estado desde disco.
+type Nodo struct{
+	muMaquina sync.Mutex // serializa la aplicación de entradas y los snapshots, se toma antes que mu
+	mu sync.Mutex // protege los campos siguientes
+	id string
+	pares []string // IDs de los demás nodos del grupo
+	transporte Transporte
+	maquina Maquina
+	rutaEstado string
+	rutaLog string
+	rutaSnapshot string
+
+	// Estado persistente
+	termino uint64
+	voto string // candidato votado en el término actual
+	entradas []Entrada // log posterior al snapshot
+	indiceSnapshot uint64 // última entrada incluida en el snapshot
+	terminoSnapshot uint64
+	datosSnapshot []byte
+
+	// Estado volátil
+	rol string
+	lider string
+	confirmado uint64 // última entrada confirmada, se persiste para aplicarla al reiniciar
+	aplicado uint64 // última entrada aplicada a la máquina de estados
+	siguiente map[string]uint64 // líder: siguiente entrada por enviar a cada seguidor
+	replicado map[string]uint64 // líder: última entrada en el log de cada seguidor
+	enviando map[string]bool // líder: hay una solicitud en curso hacia el seguidor
+	esperas map[uint64]espera // líder: propuestas por índice de su entrada
+	contacto time.Time // último contacto con el líder, o inicio de la última candidatura
+	plazo time.Duration // sin contacto por este tiempo el nodo se postula
+	confirmar chan struct{} // avisa al aplicador de nuevas entradas confirmadas
+	replicar chan struct{} // avisa al líder de nuevas entradas por enviar
+	fin chan struct{} // se cierra al detener el nodo
+
+	latido time.Duration
+	tiempoEleccion time.Duration
+	entradasSnapshot uint64
+	ahora func() time.Time
+}
+
+// Crea el nodo recuperando su estado desde los archivos del directorio indicado. La máquina de
+// estados se restaura desde el snapshot, o a su estado inicial si no hay uno, y al iniciar el nodo
+// se le aplican las entradas que ya estaban confirmadas.
+func NuevoNodo(id string, pares []string, ruta string, transporte Transporte, maquina Maquina) (*Nodo, error) {
+	if err := archivos.CrearDirectorio(ruta); err != nil {
+		return nil, err
+	}
+	n := &Nodo{
+		id: id, pares: pares, transporte: transporte, maquina: maquina,
+		rutaEstado: ruta + "raft.estado", rutaLog: ruta + "raft.log", rutaSnapshot: ruta + "raft.snapshot",
+		rol: SEGUIDOR, siguiente: make(map[string]uint64), replicado: make(map[string]uint64),
+		enviando: make(map[string]bool), esperas: make(map[uint64]espera),
+		confirmar: make(chan struct{}, 1), replicar: make(chan struct{}, 1), fin: make(chan struct{}),
+		latido: INTERVALO_LATIDO, tiempoEleccion: TIEMPO_ELECCION, entradasSnapshot: ENTRADAS_SNAPSHOT, ahora: time.Now,
+	}
+	if err := n.cargar(); err != nil {
+		return nil, err
+	}
+	if err := maquina.Restaurar(n.datosSnapshot); err != nil {
+		return nil, err
+	}
+	n.aplicado = n.indiceSnapshot
+	if n.confirmado < n.indiceSnapshot {
+		n.confirmado = n.indiceSnapshot
+	}
+	if ultimo := n.ultimoIndice(); n.confirmado > ultimo {
+		n.confirmado = ultimo
+	}
+	n.reiniciarPlazo()
+	return n, nil
+}
+
+// Inicia la aplicación de las entradas confirmadas y el ciclo de elección y replicación
+func (n *Nodo) Iniciar() {
+	go n.aplicar()
+	go n.ejecutar()
+	n.avisar(n.confirmar)
+}
+
+// Detiene la elección, la replicación y la aplicación de entradas
+func (n *Nodo) Detener() {
+	close(n.fin)
+}
+
+// Líder conocido y término actual
+func (n *Nodo) Lider() (string, uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lider, n.termino
+}
+
+func (n *Nodo) EsLider() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.rol == LIDER
+}
+
+// Propone un comando como líder y espera a que se confirme y aplique, retornando el resultado de
+// la máquina de estados. Si el contexto termina antes, la entrada aún puede confirmarse: el
+// resultado es incierto.
+func (n *Nodo) Proponer(ctx context.Context, comando []byte) (interface{}, error) {
+	n.mu.Lock()
+	if n.rol != LIDER {
+		n.mu.Unlock()
+		return nil, &Error{Descripcion: "El nodo " + n.id + " no es el líder del grupo Raft"}
+	}
+	entrada := Entrada{Indice: n.ultimoIndice() + 1, Termino: n.termino, Comando: comando}
+	if err := n.agregarEntradas([]Entrada{entrada}); err != nil {
+		n.mu.Unlock()
+		return nil, err
+	}
+	listo := make(chan resultado, 1)
+	n.esperas[entrada.Indice] = espera{termino: entrada.Termino, listo: listo}
+	n.avanzarConfirmado()
+	n.mu.Unlock()
+	n.avisar(n.replicar)
+
+	select {
+	case r := <-listo:
+		return r.valor, r.err
+	case <-ctx.Done():
+		n.mu.Lock()
+		delete(n.esperas, entrada.Indice)
+		n.mu.Unlock()
+		return nil, &Error{Descripcion: "La entrada " + strconv.FormatUint(entrada.Indice, 10) + " no se confirmó a tiempo, su resultado es incierto", Incierto: true}
+	}
+}
+
+func (n *Nodo) avisar(canal chan struct{}) {
+	select {
+	case canal <- struct{}{}:
+	default:
+	}
+}
+
+
+//// SOLICITUDES DE LOS DEMÁS NODOS
+
+// Responde una solicitud de voto. Mientras el nodo tenga noticias recientes del líder no vota por
+// otro candidato, de modo que un nodo que estuvo aislado no interrumpa al líder con un término mayor.
+func (n *Nodo) SolicitarVoto(solicitud *pb.SolicitudVotoRaft) (*pb.Voto, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.rol == SEGUIDOR && n.lider != "" && n.lider != solicitud.Candidato && n.ahora().Sub(n.contacto) < n.tiempoEleccion {
+		return &pb.Voto{Termino: n.termino, Concedido: false}, nil
+	}
+	if err := n.adoptarTermino(solicitud.Termino); err != nil {
+		return nil, err
+	}
+	if solicitud.Termino < n.termino || (n.voto != "" && n.voto != solicitud.Candidato) {
+		return &pb.Voto{Termino: n.termino, Concedido: false}, nil
+	}
+
+	// Solo se vota por un candidato cuyo log está al menos tan actualizado como el del nodo
+	ultimoTermino, _ := n.terminoEn(n.ultimoIndice())
+	if solicitud.UltimoTermino < ultimoTermino || (solicitud.UltimoTermino == ultimoTermino && solicitud.UltimoIndice < n.ultimoIndice()) {
+		return &pb.Voto{Termino: n.termino, Concedido: false}, nil
+	}
+	n.voto = solicitud.Candidato
+	if err := n.persistirEstado(); err != nil {
+		return nil, err
+	}
+	n.contacto = n.ahora()
+	return &pb.Voto{Termino: n.termino, Concedido: true}, nil
+}
+
+// Recibe entradas o un latido del líder. Las entradas se agregan si el log coincide con el del
+// líder en la entrada anterior; las que están en conflicto se descartan junto con las siguientes.
+func (n *Nodo) AgregarEntradas(mensaje *pb.EntradasRaft) (*pb.ResultadoRaft, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if mensaje.Termino < n.termino {
+		return &pb.ResultadoRaft{Termino: n.termino, Exito: false, UltimoIndice: n.ultimoIndice()}, nil
+	}
+	if err := n.seguir(mensaje.Termino, mensaje.Lider); err != nil {
+		return nil, err
+	}
+
+	ultimo := n.ultimoIndice()
+	if mensaje.IndiceAnterior > ultimo {
+		return &pb.ResultadoRaft{Termino: n.termino, Exito: false, UltimoIndice: ultimo}, nil
+	}
+	if termino, ok := n.terminoEn(mensaje.IndiceAnterior); ok && termino != mensaje.TerminoAnterior {
+		return &pb.ResultadoRaft{Termino: n.termino, Exito: false, UltimoIndice: mensaje.IndiceAnterior - 1}, nil
+	}
+
+	// Las entradas anteriores al snapshot ya están confirmadas y se omiten
+	var nuevas []Entrada
+	for i, e := range mensaje.Entradas {
+		indice := mensaje.IndiceAnterior + 1 + uint64(i)
+		if indice <= n.indiceSnapshot {
+			continue
+		}
+		if len(nuevas) == 0 {
+			if termino, ok := n.terminoEn(indice); ok {
+				if termino == e.Termino {
+					continue
+				}
+				if err := n.truncar(indice); err != nil {
+					return nil, err
+				}
+			}
+		}
+		nuevas = append(nuevas, Entrada{Indice: indice, Termino: e.Termino, Comando: e.Comando})
+	}
+	if err := n.agregarEntradas(nuevas); err != nil {
+		return nil, err
+	}
+
+	if recibido := mensaje.IndiceAnterior + uint64(len(mensaje.Entradas)); mensaje.Confirmado > n.confirmado && recibido > n.confirmado {
+		n.confirmado = mensaje.Confirmado
+		if recibido < n.confirmado {
+			n.confirmado = recibido
+		}
+		if err := n.persistirEstado(); err != nil {
+			return nil, err
+		}
+		n.avisar(n.confirmar)
+	}
+	return &pb.ResultadoRaft{Termino: n.termino, Exito: true, UltimoIndice: n.ultimoIndice()}, nil
+}
+
+// Recibe el snapshot del líder, enviado cuando este ya compactó las entradas que el nodo necesita
+func (n *Nodo) InstalarSnapshot(snapshot *pb.SnapshotRaft) (*pb.ResultadoRaft, error) {
+	n.muMaquina.Lock()
+	defer n.muMaquina.Unlock()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if snapshot.Termino < n.termino {
+		return &pb.ResultadoRaft{Termino: n.termino, Exito: false, UltimoIndice: n.ultimoIndice()}, nil
+	}
+	if err := n.seguir(snapshot.Termino, snapshot.Lider); err != nil {
+		return nil, err
+	}
+	if snapshot.Indice <= n.aplicado { // El nodo ya aplicó las entradas del snapshot
+		return &pb.ResultadoRaft{Termino: n.termino, Exito: true, UltimoIndice: n.ultimoIndice()}, nil
+	}
+
+	if err := n.maquina.Restaurar(snapshot.Datos); err != nil {
+		return nil, err
+	}
+	// Se conservan las entradas posteriores al snapshot si el log coincide con el del líder
+	if termino, ok := n.terminoEn(snapshot.Indice); ok && termino == snapshot.TerminoIndice {
+		n.entradas = append([]Entrada{}, n.entradas[snapshot.Indice - n.indiceSnapshot:]...)
+	} else {
+		n.entradas = nil
+	}
+	n.indiceSnapshot, n.terminoSnapshot, n.datosSnapshot = snapshot.Indice, snapshot.TerminoIndice, snapshot.Datos
+	n.aplicado = snapshot.Indice
+	if n.confirmado < snapshot.Indice {
+		n.confirmado = snapshot.Indice
+	}
+	for indice, esp := range n.esperas { // Propuestas de un liderazgo anterior
+		if indice <= snapshot.Indice {
+			delete(n.esperas, indice)
+			esp.listo <- resultado{err: reemplazada(indice)}
+		}
+	}
+	if err := n.persistirSnapshot(); err != nil {
+		return nil, err
+	}
+	log.Printf("Raft: snapshot hasta la entrada %d instalado desde %s\n", snapshot.Indice, snapshot.Lider)
+	return &pb.ResultadoRaft{Termino: n.termino, Exito: true, UltimoIndice: n.ultimoIndice()}, nil
+}
+
+// Adopta un término mayor, volviendo a ser seguidor. Debe llamarse manteniendo el mutex.
+func (n *Nodo) adoptarTermino(termino uint64) error {
+	if termino <= n.termino {
+		return nil
+	}
+	if n.rol == LIDER {
+		log.Printf("Raft: %s deja el liderazgo, término %d\n", n.id, termino)
+	}
+	n.termino = termino
+	n.voto = ""
+	n.rol = SEGUIDOR
+	n.lider = ""
+	return n.persistirEstado()
+}
+
+// Reconoce al líder de un término tras recibir una solicitud suya. Debe llamarse manteniendo el mutex.
+func (n *Nodo) seguir(termino uint64, lider string) error {
+	if err := n.adoptarTermino(termino); err != nil {
+		return err
+	}
+	n.rol = SEGUIDOR
+	if n.lider != lider {
+		log.Printf("Raft: líder %s, término %d\n", lider, termino)
+	}
+	n.lider = lider
+	n.contacto = n.ahora()
+	return nil
+}
+
+
+//// ELECCIÓN Y REPLICACIÓN
+
+// Cada latido, el líder envía las entradas pendientes (o un latido) a los seguidores; los demás
+// nodos se postulan si no tienen noticias del líder dentro de su plazo
+func (n *Nodo) ejecutar() {
+	ticker := time.NewTicker(n.latido)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-n.replicar:
+		case <-n.fin:
+			return
+		}
+		n.mu.Lock()
+		rol := n.rol
+		vencido := n.ahora().Sub(n.contacto) >= n.plazo
+		n.mu.Unlock()
+		if rol == LIDER {
+			n.difundir()
+		} else if vencido {
+			n.postularse()
+		}
+	}
+}
+
+// Debe llamarse manteniendo el mutex
+func (n *Nodo) reiniciarPlazo() {
+	n.contacto = n.ahora()
+	n.plazo = n.tiempoEleccion + time.Duration(rand.Int63n(int64(n.tiempoEleccion)))
+}
+
+func (n *Nodo) mayoria() int {
+	return (len(n.pares) + 1) / 2 + 1
+}
+
+func (n *Nodo) postularse() {
+	n.mu.Lock()
+	n.termino += 1
+	n.voto = n.id
+	n.rol = CANDIDATO
+	n.lider = ""
+	n.reiniciarPlazo()
+	if err := n.persistirEstado(); err != nil {
+		n.mu.Unlock()
+		log.Printf("Raft: error al persistir el estado: %s\n", err)
+		return
+	}
+	ultimoTermino, _ := n.terminoEn(n.ultimoIndice())
+	solicitud := &pb.SolicitudVotoRaft{Termino: n.termino, Candidato: n.id, UltimoIndice: n.ultimoIndice(), UltimoTermino: ultimoTermino}
+	votos := 1
+	if votos >= n.mayoria() { // Grupo de un solo nodo
+		n.asumir()
+	}
+	n.mu.Unlock()
+
+	for _, par := range n.pares {
+		go func(par string) {
+			ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_RPC)
+			defer cancel()
+			voto, err := n.transporte.SolicitarVoto(ctx, par, solicitud)
+			if err != nil {
+				return
+			}
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if err := n.adoptarTermino(voto.Termino); err != nil {
+				log.Printf("Raft: error al persistir el estado: %s\n", err)
+				return
+			}
+			if !voto.Concedido || n.rol != CANDIDATO || n.termino != solicitud.Termino {
+				return
+			}
+			votos += 1
+			if votos == n.mayoria() {
+				n.asumir()
+			}
+		}(par)
+	}
+}
+
+// Asume el liderazgo e inicia su término con una entrada vacía, que al confirmarse confirma también
+// las entradas de términos anteriores. Debe llamarse manteniendo el mutex.
+func (n *Nodo) asumir() {
+	n.rol = LIDER
+	n.lider = n.id
+	ultimo := n.ultimoIndice()
+	for _, par := range n.pares {
+		n.siguiente[par] = ultimo + 1
+		n.replicado[par] = 0
+	}
+	log.Printf("Raft: %s es el líder, término %d\n", n.id, n.termino)
+	if err := n.agregarEntradas([]Entrada{{Indice: ultimo + 1, Termino: n.termino}}); err != nil {
+		log.Printf("Raft: error al persistir el log: %s\n", err)
+	}
+	n.avanzarConfirmado()
+	n.avisar(n.replicar)
+}
+
+func (n *Nodo) difundir() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, par := range n.pares {
+		if !n.enviando[par] {
+			n.enviando[par] = true
+			go n.enviar(par)
+		}
+	}
+}
+
+// Envía a un seguidor las entradas desde la siguiente que le falta, o el snapshot si esta ya se compactó
+func (n *Nodo) enviar(par string) {
+	defer func() {
+		n.mu.Lock()
+		n.enviando[par] = false
+		n.mu.Unlock()
+	}()
+
+	n.mu.Lock()
+	if n.rol != LIDER {
+		n.mu.Unlock()
+		return
+	}
+	termino := n.termino
+	siguiente := n.siguiente[par]
+	var mensaje *pb.EntradasRaft
+	var snapshot *pb.SnapshotRaft
+	if siguiente <= n.indiceSnapshot {
+		snapshot = &pb.SnapshotRaft{Termino: termino, Lider: n.id, Indice: n.indiceSnapshot, TerminoIndice: n.terminoSnapshot, Datos: n.datosSnapshot}
+	} else {
+		terminoAnterior, _ := n.terminoEn(siguiente - 1)
+		mensaje = &pb.EntradasRaft{Termino: termino, Lider: n.id, IndiceAnterior: siguiente - 1, TerminoAnterior: terminoAnterior, Confirmado: n.confirmado}
+		for i := siguiente; i <= n.ultimoIndice() && len(mensaje.Entradas) < MAX_ENTRADAS; i++ {
+			e := n.entrada(i)
+			mensaje.Entradas = append(mensaje.Entradas, &pb.EntradaRaft{Termino: e.Termino, Comando: e.Comando})
+		}
+	}
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), TIEMPO_RPC)
+	defer cancel()
+	var respuesta *pb.ResultadoRaft
+	var err error
+	if snapshot != nil {
+		respuesta, err = n.transporte.InstalarSnapshot(ctx, par, snapshot)
+	} else {
+		respuesta, err = n.transporte.AgregarEntradas(ctx, par, mensaje)
+	}
+	if err != nil {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err := n.adoptarTermino(respuesta.Termino); err != nil {
+		log.Printf("Raft: error al persistir el estado: %s\n", err)
+		return
+	}
+	if n.rol != LIDER || n.termino != termino {
+		return
+	}
+	if !respuesta.Exito {
+		// Retroceder hasta la última entrada del seguidor, o al menos una entrada
+		if siguiente > 1 {
+			n.siguiente[par] = siguiente - 1
+		}
+		if respuesta.UltimoIndice + 1 < n.siguiente[par] {
+			n.siguiente[par] = respuesta.UltimoIndice + 1
+		}
+		n.avisar(n.replicar)
+		return
+	}
+
+	var replicado uint64
+	if snapshot != nil {
+		replicado = snapshot.Indice
+	} else {
+		replicado = mensaje.IndiceAnterior + uint64(len(mensaje.Entradas))
+	}
+	if replicado > n.replicado[par] {
+		n.replicado[par] = replicado
+	}
+	n.siguiente[par] = n.replicado[par] + 1
+	n.avanzarConfirmado()
+	if n.siguiente[par] <= n.ultimoIndice() {
+		n.avisar(n.replicar)
+	}
+}
+
+// Confirma la última entrada del término actual que está en el log de la mayoría del grupo. Las
+// entradas de términos anteriores se confirman con ella. Debe llamarse manteniendo el mutex.
+func (n *Nodo) avanzarConfirmado() {
+	for indice := n.ultimoIndice(); indice > n.confirmado; indice-- {
+		if termino, _ := n.terminoEn(indice); termino != n.termino {
+			return
+		}
+		copias := 1
+		for _, par := range n.pares {
+			if n.replicado[par] >= indice {
+				copias += 1
+			}
+		}
+		if copias >= n.mayoria() {
+			n.confirmado = indice
+			if err := n.persistirEstado(); err != nil {
+				log.Printf("Raft: error al persistir el estado: %s\n", err)
+			}
+			n.avisar(n.confirmar)
+			return
+		}
+	}
+}
+
+
+//// APLICACIÓN DE LAS ENTRADAS
+
+func (n *Nodo) aplicar() {
+	for {
+		select {
+		case <-n.confirmar:
+			n.aplicarConfirmadas()
+		case <-n.fin:
+			return
+		}
+	}
+}
+
+// Aplica las entradas confirmadas en orden, entrega su resultado a las propuestas que las esperan
+// y toma un snapshot cada ENTRADAS_SNAPSHOT entradas aplicadas
+func (n *Nodo) aplicarConfirmadas() {
+	n.muMaquina.Lock()
+	defer n.muMaquina.Unlock()
+
+	n.mu.Lock()
+	var pendientes []Entrada
+	for i := n.aplicado + 1; i <= n.confirmado && i <= n.ultimoIndice(); i++ {
+		pendientes = append(pendientes, n.entrada(i))
+	}
+	n.mu.Unlock()
+
+	for _, e := range pendientes {
+		var r resultado
+		if len(e.Comando) > 0 {
+			r.valor, r.err = n.maquina.Aplicar(e.Comando)
+		}
+		n.mu.Lock()
+		n.aplicado = e.Indice
+		if esp, ok := n.esperas[e.Indice]; ok {
+			delete(n.esperas, e.Indice)
+			if esp.termino != e.Termino { // La propuesta fue reemplazada por la de otro líder
+				r = resultado{err: reemplazada(e.Indice)}
+			}
+			esp.listo <- r
+		}
+		n.mu.Unlock()
+	}
+
+	n.mu.Lock()
+	tomar := n.aplicado - n.indiceSnapshot >= n.entradasSnapshot
+	n.mu.Unlock()
+	if tomar {
+		if err := n.tomarSnapshot(); err != nil {
+			log.Printf("Raft: error al tomar el snapshot: %s\n", err)
+		}
+	}
+}
+
+// Guarda el estado de la máquina y descarta las entradas aplicadas. La máquina se compacta solo
+// después de persistir el snapshot, de modo que si el proceso termina antes se recupera con el
+// snapshot y el log anteriores. Debe llamarse manteniendo muMaquina.
+func (n *Nodo) tomarSnapshot() error {
+	datos, err := n.maquina.Snapshot()
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	termino, _ := n.terminoEn(n.aplicado)
+	n.entradas = append([]Entrada{}, n.entradas[n.aplicado - n.indiceSnapshot:]...)
+	n.indiceSnapshot, n.terminoSnapshot, n.datosSnapshot = n.aplicado, termino, datos
+	err = n.persistirSnapshot()
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return n.maquina.Compactar()
+}
+
+
+//// LOG (el llamador debe mantener el mutex)
+
+func (n *Nodo) ultimoIndice() uint64 {
+	if len(n.entradas) > 0 {
+		return n.entradas[len(n.entradas) - 1].Indice
+	}
+	return n.indiceSnapshot
+}
+
+// Término de una entrada, false si ya se compactó o no existe
+func (n *Nodo) terminoEn(indice uint64) (uint64, bool) {
+	if indice == n.indiceSnapshot {
+		return n.terminoSnapshot, true
+	}
+	if indice < n.indiceSnapshot || indice > n.ultimoIndice() {
+		return 0, false
+	}
+	return n.entrada(indice).Termino, true
+}
+
+func (n *Nodo) entrada(indice uint64) Entrada {
+	return n.entradas[indice - n.indiceSnapshot - 1]
+}
+
+// Agrega entradas al final del log, primero en disco
+func (n *Nodo) agregarEntradas(nuevas []Entrada) error {
+	if len(nuevas) == 0 {
+		return nil
+	}
+	lineas := make([]string, len(nuevas))
+	for i, e := range nuevas {
+		lineas[i] = lineaEntrada(e)
+	}
+	file, err := os.OpenFile(n.rutaLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.WriteString(strings.Join(lineas, "\n") + "\n"); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	n.entradas = append(n.entradas, nuevas...)
+	return nil
+}
+
+// Descarta las entradas desde el índice indicado, que no pueden estar confirmadas
+func (n *Nodo) truncar(indice uint64) error {
+	n.entradas = n.entradas[:indice - n.indiceSnapshot - 1]
+	return n.reescribirLog()
+}
+
+
+//// PERSISTENCIA
+// El estado es un archivo con el término, el voto y la última entrada confirmada, una por linea.
+// Cada linea del log es una entrada de la forma "<indice> <termino> <comando en base64>" y el
+// snapshot comienza con una linea "<indice> <termino>" seguida de los datos de la máquina.
+
+func lineaEntrada(e Entrada) string {
+	return strconv.FormatUint(e.Indice, 10) + " " + strconv.FormatUint(e.Termino, 10) + " " + base64.StdEncoding.EncodeToString(e.Comando)
+}
+
+func (n *Nodo) persistirEstado() error {
+	contenido := strconv.FormatUint(n.termino, 10) + "\n" + n.voto + "\n" + strconv.FormatUint(n.confirmado, 10)
+	return archivos.EscribirArchivoAtomico(n.rutaEstado, []byte(contenido))
+}
+
+func (n *Nodo) reescribirLog() error {
+	var contenido strings.Builder
+	for _, e := range n.entradas {
+		contenido.WriteString(lineaEntrada(e) + "\n")
+	}
+	return archivos.EscribirArchivoAtomico(n.rutaLog, []byte(contenido.String()))
+}
+
+// Escribe el snapshot y luego el log compactado. Si el proceso termina entre ambos, las entradas
+// que el snapshot ya incluye se descartan al cargar.
+func (n *Nodo) persistirSnapshot() error {
+	cabecera := strconv.FormatUint(n.indiceSnapshot, 10) + " " + strconv.FormatUint(n.terminoSnapshot, 10) + "\n"
+	if err := archivos.EscribirArchivoAtomico(n.rutaSnapshot, append([]byte(cabecera), n.datosSnapshot...)); err != nil {
+		return err
+	}
+	if err := n.reescribirLog(); err != nil {
+		return err
+	}
+	return n.persistirEstado()
+}
+
+func (n *Nodo) cargar() error {
+	// Estado
+	contenido, err := os.ReadFile(n.rutaEstado)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		campos := strings.Split(string(contenido), "\n")
+		if len(campos) != 3 {
+			return errors.New("Estado de Raft inválido: " + n.rutaEstado)
+		}
+		if n.termino, err = strconv.ParseUint(campos[0], 10, 64); err != nil {
+			return errors.New("Término inválido en el estado de Raft: " + campos[0])
+		}
+		n.voto = campos[1]
+		if n.confirmado, err = strconv.ParseUint(campos[2], 10, 64); err != nil {
+			return errors.New("Entrada confirmada inválida en el estado de Raft: " + campos[2])
+		}
+	}
+
+	// Snapshot
+	contenido, err = os.ReadFile(n.rutaSnapshot)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		fin := strings.Index(string(contenido), "\n")
+		if fin < 0 {
+			return errors.New("Snapshot de Raft inválido: " + n.rutaSnapshot)
+		}
+		campos := strings.Fields(string(contenido[:fin]))
+		if len(campos) != 2 {
+			return errors.New("Snapshot de Raft inválido: " + n.rutaSnapshot)
+		}
+		if n.indiceSnapshot, err = strconv.ParseUint(campos[0], 10, 64); err != nil {
+			return errors.New("Snapshot de Raft inválido: " + n.rutaSnapshot)
+		}
+		if n.terminoSnapshot, err = strconv.ParseUint(campos[1], 10, 64); err != nil {
+			return errors.New("Snapshot de Raft inválido: " + n.rutaSnapshot)
+		}
+		n.datosSnapshot = contenido[fin + 1:]
+	}
+
+	// Log: cada entrada termina en un salto de linea, un resto al final corresponde a una escritura
+	// interrumpida y se descarta reescribiendo el log
+	contenido, err = os.ReadFile(n.rutaLog)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lineas := strings.Split(string(contenido), "\n")
+	for _, linea := range lineas[:len(lineas) - 1] {
+		campos := strings.Fields(linea)
+		if len(campos) < 2 || len(campos) > 3 {
+			return errors.New("Entrada inválida en el log de Raft: " + linea)
+		}
+		var e Entrada
+		var errIndice, errTermino, errComando error
+		e.Indice, errIndice = strconv.ParseUint(campos[0], 10, 64)
+		e.Termino, errTermino = strconv.ParseUint(campos[1], 10, 64)
+		if len(campos) == 3 {
+			e.Comando, errComando = base64.StdEncoding.DecodeString(campos[2])
+		}
+		if errIndice != nil || errTermino != nil || errComando != nil {
+			return errors.New("Entrada inválida en el log de Raft: " + linea)
+		}
+		if e.Indice <= n.indiceSnapshot {
+			continue
+		}
+		if e.Indice != n.ultimoIndice() + 1 {
+			return errors.New("Entrada fuera de orden en el log de Raft: " + linea)
+		}
+		n.entradas = append(n.entradas, e)
+	}
+	if lineas[len(lineas) - 1] != "" {
+		return n.reescribirLog()
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+)
+
+// Máquina de prueba: la lista de comandos aplicados
+type maquinaPrueba struct{
+	mu sync.Mutex
+	comandos []string
+	compactados int // comandos incluidos en el último snapshot persistido
+}
+
+func (m *maquinaPrueba) Aplicar(comando []byte) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if string(comando) == "error" {
+		return nil, errors.New("Comando inválido")
+	}
+	m.comandos = append(m.comandos, string(comando))
+	return len(m.comandos), nil
+}
+
+func (m *maquinaPrueba) Snapshot() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return []byte(strings.Join(m.comandos, ",")), nil
+}
+
+func (m *maquinaPrueba) Compactar() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.compactados = len(m.comandos)
+	return nil
+}
+
+func (m *maquinaPrueba) Restaurar(datos []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.comandos = nil
+	if len(datos) > 0 {
+		m.comandos = strings.Split(string(datos), ",")
+	}
+	return nil
+}
+
+func (m *maquinaPrueba) aplicados() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return strings.Join(m.comandos, ",")
+}
+
+// Transporte en memoria entre los nodos de un grupo, que permite aislar nodos
+type redPrueba struct{
+	mu sync.Mutex
+	nodos map[string]*Nodo
+	aislados map[string]bool
+}
+
+func (r *redPrueba) destino(id string) (*Nodo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.aislados[id] {
+		return nil, errors.New("Nodo aislado: " + id)
+	}
+	return r.nodos[id], nil
+}
+
+func (r *redPrueba) aislar(id string, aislado bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aislados[id] = aislado
+}
+
+// Transporte de un nodo de la red: un nodo aislado no envía ni recibe solicitudes
+type transportePrueba struct{
+	red *redPrueba
+	origen string
+}
+
+func (t transportePrueba) conectar(id string) (*Nodo, error) {
+	if _, err := t.red.destino(t.origen); err != nil {
+		return nil, err
+	}
+	return t.red.destino(id)
+}
+
+func (t transportePrueba) SolicitarVoto(ctx context.Context, id string, solicitud *pb.SolicitudVotoRaft) (*pb.Voto, error) {
+	n, err := t.conectar(id)
+	if err != nil {
+		return nil, err
+	}
+	return n.SolicitarVoto(solicitud)
+}
+
+func (t transportePrueba) AgregarEntradas(ctx context.Context, id string, entradas *pb.EntradasRaft) (*pb.ResultadoRaft, error) {
+	n, err := t.conectar(id)
+	if err != nil {
+		return nil, err
+	}
+	return n.AgregarEntradas(entradas)
+}
+
+func (t transportePrueba) InstalarSnapshot(ctx context.Context, id string, snapshot *pb.SnapshotRaft) (*pb.ResultadoRaft, error) {
+	n, err := t.conectar(id)
+	if err != nil {
+		return nil, err
+	}
+	return n.InstalarSnapshot(snapshot)
+}
+
+var idsPrueba = []string{"DNS1", "DNS2", "DNS3"}
+
+func nuevoNodoPrueba(t *testing.T, red *redPrueba, id string, ruta string) (*Nodo, *maquinaPrueba) {
+	var pares []string
+	for _, par := range idsPrueba {
+		if par != id {
+			pares = append(pares, par)
+		}
+	}
+	maquina := new(maquinaPrueba)
+	n, err := NuevoNodo(id, pares, ruta, transportePrueba{red: red, origen: id}, maquina)
+	assert.Nil(t, err)
+	n.latido = 10 * time.Millisecond
+	n.tiempoEleccion = 100 * time.Millisecond
+	n.entradasSnapshot = 5
+	n.reiniciarPlazo()
+	red.mu.Lock()
+	red.nodos[id] = n
+	red.mu.Unlock()
+	return n, maquina
+}
+
+// Espera a que algún nodo no aislado sea el líder
+func esperarLider(t *testing.T, red *redPrueba) *Nodo {
+	for limite := time.Now().Add(5 * time.Second); time.Now().Before(limite); time.Sleep(10 * time.Millisecond) {
+		for _, id := range idsPrueba {
+			if n, err := red.destino(id); err == nil && n.EsLider() {
+				return n
+			}
+		}
+	}
+	t.Fatal("No se eligió un líder")
+	return nil
+}
+
+func esperarAplicados(t *testing.T, maquina *maquinaPrueba, esperado string) {
+	for limite := time.Now().Add(5 * time.Second); time.Now().Before(limite) && maquina.aplicados() != esperado; time.Sleep(10 * time.Millisecond) {
+	}
+	assert.Equal(t, esperado, maquina.aplicados())
+}
+
+func proponer(n *Nodo, comando string) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2 * time.Second)
+	defer cancel()
+	return n.Proponer(ctx, []byte(comando))
+}
+
+func TestReplicacion(t *testing.T) {
+	red := &redPrueba{nodos: make(map[string]*Nodo), aislados: make(map[string]bool)}
+	rutas := make(map[string]string)
+	maquinas := make(map[string]*maquinaPrueba)
+	for _, id := range idsPrueba {
+		rutas[id] = t.TempDir() + "/"
+		var n *Nodo
+		n, maquinas[id] = nuevoNodoPrueba(t, red, id, rutas[id])
+		n.Iniciar()
+	}
+
+	// Las propuestas se aplican en orden en todos los nodos; los errores de la máquina son su resultado
+	lider := esperarLider(t, red)
+	for _, comando := range []string{"a", "b", "c"} {
+		_, err := proponer(lider, comando)
+		assert.Nil(t, err)
+	}
+	_, err := proponer(lider, "error")
+	assert.NotNil(t, err)
+	for _, id := range idsPrueba {
+		esperarAplicados(t, maquinas[id], "a,b,c")
+		if id != lider.id {
+			_, err := proponer(red.nodos[id], "x")
+			assert.NotNil(t, err) // Solo el líder acepta propuestas
+		}
+	}
+
+	// Al aislar al líder la mayoría elige otro, que sigue aceptando propuestas
+	red.aislar(lider.id, true)
+	anterior := lider
+	lider = esperarLider(t, red)
+	assert.NotEqual(t, anterior.id, lider.id)
+	for _, comando := range []string{"d", "e", "f", "g"} {
+		resultado, err := proponer(lider, comando)
+		assert.Nil(t, err)
+		assert.Equal(t, len(maquinas[lider.id].comandos), resultado)
+	}
+
+	// El líder anterior no confirma propuestas sin la mayoría, y al volver las descarta y
+	// recibe el snapshot del nuevo líder, que ya compactó su log
+	ctx, cancel := context.WithTimeout(context.Background(), 100 * time.Millisecond)
+	_, err = anterior.Proponer(ctx, []byte("perdida"))
+	cancel()
+	assert.NotNil(t, err)
+	red.aislar(anterior.id, false)
+	for _, id := range idsPrueba {
+		esperarAplicados(t, maquinas[id], "a,b,c,d,e,f,g")
+	}
+	lider.mu.Lock()
+	assert.True(t, lider.indiceSnapshot > 0)
+	lider.mu.Unlock()
+	maquinas[lider.id].mu.Lock()
+	assert.True(t, maquinas[lider.id].compactados > 0) // La máquina se compacta tras persistir el snapshot
+	maquinas[lider.id].mu.Unlock()
+
+	// Un nodo reiniciado recupera desde disco el snapshot y las entradas confirmadas
+	red.aislar(anterior.id, true)
+	anterior.Detener()
+	time.Sleep(50 * time.Millisecond)
+	_, reiniciada := nuevoNodoPrueba(t, red, anterior.id, rutas[anterior.id])
+	assert.Equal(t, "a,b,c", reiniciada.aplicados()[:5])
+	red.nodos[anterior.id].Iniciar()
+	red.aislar(anterior.id, false)
+	esperarAplicados(t, reiniciada, "a,b,c,d,e,f,g")
+	for _, id := range idsPrueba {
+		red.nodos[id].Detener()
+	}
+}
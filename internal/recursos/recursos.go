@@ -0,0 +1,258 @@
+package recursos
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/validacion"
+)
+
+// Las lineas del registro ZF tienen la forma
+//	<nombre> [<ttl>] IN <tipo> <datos>
+// por ejemplo "example.com 300 IN MX 10 mail.example.com". El TTL se omite cuando es el
+// TTL por defecto, de modo que las lineas antiguas "google.com IN A 8.8.8.8" siguen siendo válidas.
+const (
+	A = "A"
+	AAAA = "AAAA"
+	CNAME = "CNAME"
+	MX = "MX"
+	TXT = "TXT"
+	NS = "NS"
+	SRV = "SRV"
+	PTR = "PTR"
+
+	CLASE = "IN"
+	TTL_POR_DEFECTO = 3600
+)
+
+// Tipos de registro soportados
+var Tipos = []string{A, AAAA, CNAME, MX, TXT, NS, SRV, PTR}
+
+// Registro de recurso (RR) de una zona
+type Recurso struct{
+	Nombre string // nombre completo al que pertenece el registro
+	TTL uint32
+	Tipo string
+	Valor string // dirección (A, AAAA), nombre destino (CNAME, NS, PTR, MX, SRV) o texto (TXT)
+	Prioridad uint16 // MX y SRV
+	Peso uint16 // SRV
+	Puerto uint16 // SRV
+}
+
+func TipoValido(tipo string) bool {
+	for _, t := range Tipos {
+		if t == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// Tipo del registro de dirección correspondiente a una IP: AAAA para IPv6 y A en otro caso
+func TipoDireccion(ip string) string {
+	if strings.Contains(ip, ":") {
+		return AAAA
+	}
+	return A
+}
+
+// Genera el texto de los datos del registro, tal como aparecen en el registro ZF
+func (r *Recurso) Datos() string {
+	switch r.Tipo {
+	case MX:
+		return strconv.Itoa(int(r.Prioridad)) + " " + r.Valor
+	case SRV:
+		return strconv.Itoa(int(r.Prioridad)) + " " + strconv.Itoa(int(r.Peso)) + " " + strconv.Itoa(int(r.Puerto)) + " " + r.Valor
+	case TXT:
+		return strconv.Quote(r.Valor)
+	}
+	return r.Valor
+}
+
+// Genera la linea del registro ZF correspondiente al registro
+func (r *Recurso) Linea() string {
+	campos := []string{r.Nombre}
+	if r.TTL != TTL_POR_DEFECTO {
+		campos = append(campos, strconv.Itoa(int(r.TTL)))
+	}
+	campos = append(campos, CLASE, r.Tipo, r.Datos())
+	return strings.Join(campos, " ")
+}
+
+// Indica si dos registros tienen el mismo tipo y datos, sin considerar el nombre ni el TTL
+func (r *Recurso) Igual(otro *Recurso) bool {
+	return r.Tipo == otro.Tipo && r.Datos() == otro.Datos()
+}
+
+func (r *Recurso) Copia() *Recurso {
+	copia := *r
+	return &copia
+}
+
+func parsearNumero(texto string, campo string) (uint16, error) {
+	valor, err := strconv.ParseUint(texto, 10, 16)
+	if err != nil {
+		return 0, errors.New("Valor inválido para " + campo + ": " + texto)
+	}
+	return uint16(valor), nil
+}
+
+// Interpreta los datos de un registro del tipo indicado, el nombre y el TTL no se asignan
+func ParsearDatos(tipo string, datos string) (*Recurso, error) {
+	r := &Recurso{Tipo: strings.ToUpper(tipo), TTL: TTL_POR_DEFECTO}
+	if !TipoValido(r.Tipo) {
+		return nil, errors.New("Tipo de registro no soportado: " + tipo)
+	}
+
+	datos = strings.TrimSpace(datos)
+	campos := strings.Fields(datos)
+	var err error
+	switch r.Tipo {
+	case TXT:
+		// El texto puede ir entre comillas, en cuyo caso admite espacios
+		if strings.HasPrefix(datos, "\"") {
+			if r.Valor, err = strconv.Unquote(datos); err != nil {
+				return nil, errors.New("Texto inválido en registro TXT: " + datos)
+			}
+		} else if len(campos) == 1 {
+			r.Valor = datos
+		} else {
+			return nil, errors.New("El texto de un registro TXT con espacios debe ir entre comillas")
+		}
+		return r, nil
+	case MX:
+		if len(campos) != 2 {
+			return nil, errors.New("Un registro MX debe tener la forma <prioridad> <servidor>")
+		}
+		if r.Prioridad, err = parsearNumero(campos[0], "la prioridad"); err != nil {
+			return nil, err
+		}
+		r.Valor = campos[1]
+	case SRV:
+		if len(campos) != 4 {
+			return nil, errors.New("Un registro SRV debe tener la forma <prioridad> <peso> <puerto> <destino>")
+		}
+		if r.Prioridad, err = parsearNumero(campos[0], "la prioridad"); err != nil {
+			return nil, err
+		}
+		if r.Peso, err = parsearNumero(campos[1], "el peso"); err != nil {
+			return nil, err
+		}
+		if r.Puerto, err = parsearNumero(campos[2], "el puerto"); err != nil {
+			return nil, err
+		}
+		r.Valor = campos[3]
+	default:
+		if len(campos) != 1 {
+			return nil, errors.New("Un registro " + r.Tipo + " debe tener un único valor")
+		}
+		r.Valor = campos[0]
+	}
+
+	r.normalizarValor()
+	return r, nil
+}
+
+// Los nombres destino se almacenan en minúsculas y sin el punto final
+func (r *Recurso) normalizarValor() {
+	if r.Tipo != A && r.Tipo != AAAA && r.Tipo != TXT {
+		r.Valor = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(r.Valor)), ".")
+	}
+}
+
+// Verifica que el TTL y los datos del registro sean válidos para su tipo
+func (r *Recurso) Validar() error {
+	if !TipoValido(r.Tipo) {
+		return validacion.Nuevo("tipo", "Tipo de registro no soportado: " + r.Tipo)
+	}
+	if err := validacion.TTL("ttl", uint64(r.TTL)); err != nil {
+		return err
+	}
+	switch r.Tipo {
+	case A:
+		return validacion.IPv4("valor", r.Valor)
+	case AAAA:
+		return validacion.IPv6("valor", r.Valor)
+	case TXT:
+		return validacion.Texto("valor", r.Valor)
+	}
+	if r.Valor == "" {
+		return validacion.Nuevo("valor", "No se ha especificado el nombre destino del registro " + r.Tipo)
+	}
+	return validacion.Nombre("valor", r.Valor)
+}
+
+// Quita los primeros n campos de un texto conservando el resto tal como está
+func QuitarCampos(texto string, n int) string {
+	texto = strings.TrimSpace(texto)
+	for i := 0; i < n; i++ {
+		texto = strings.TrimSpace(texto[strings.IndexAny(texto + " ", " \t"):])
+	}
+	return texto
+}
+
+// Interpreta una linea del registro ZF de la forma <nombre> [<ttl>] [IN] <tipo> <datos>
+func ParsearLinea(linea string) (*Recurso, error) {
+	campos := strings.Fields(linea)
+	if len(campos) < 3 {
+		return nil, errors.New("Linea inválida en el registro ZF: " + linea)
+	}
+	nombre := campos[0]
+	resto := campos[1:]
+
+	ttl := uint64(TTL_POR_DEFECTO)
+	if valor, err := strconv.ParseUint(resto[0], 10, 32); err == nil {
+		ttl = valor
+		resto = resto[1:]
+	}
+	if len(resto) > 0 && strings.ToUpper(resto[0]) == CLASE {
+		resto = resto[1:]
+	}
+	if len(resto) < 2 {
+		return nil, errors.New("Linea inválida en el registro ZF: " + linea)
+	}
+
+	// Los datos se toman desde la linea original para conservar los espacios del texto de un TXT
+	r, err := ParsearDatos(resto[0], QuitarCampos(linea, len(campos) - len(resto) + 1))
+	if err != nil {
+		return nil, errors.New(err.Error() + " (" + linea + ")")
+	}
+	r.Nombre = nombre
+	r.TTL = uint32(ttl)
+	return r, nil
+}
+
+// Obtiene el registro correspondiente al mensaje recibido por gRPC
+func DesdeProto(p *pb.Recurso) (*Recurso, error) {
+	for campo, valor := range map[string]uint32{"prioridad": p.Prioridad, "peso": p.Peso, "puerto": p.Puerto} {
+		if valor > 65535 {
+			return nil, validacion.Nuevo(campo, "El valor de " + campo + " no puede superar 65535")
+		}
+	}
+	r := &Recurso{
+		Nombre: p.Nombre,
+		TTL: p.Ttl,
+		Tipo: strings.ToUpper(p.Tipo),
+		Valor: p.Valor,
+		Prioridad: uint16(p.Prioridad),
+		Peso: uint16(p.Peso),
+		Puerto: uint16(p.Puerto),
+	}
+	r.normalizarValor()
+	return r, nil
+}
+
+// Genera el mensaje gRPC correspondiente al registro
+func (r *Recurso) Proto() *pb.Recurso {
+	return &pb.Recurso{
+		Nombre: r.Nombre,
+		Ttl: r.TTL,
+		Tipo: r.Tipo,
+		Valor: r.Valor,
+		Prioridad: uint32(r.Prioridad),
+		Peso: uint32(r.Peso),
+		Puerto: uint32(r.Puerto),
+	}
+}
@@ -0,0 +1,34 @@
+package recursos
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsearLinea(t *testing.T) {
+	lineas := []string{
+		"google.com IN A 8.8.8.8",
+		"www.example.com 300 IN AAAA 2001:db8::1",
+		"example.com IN MX 10 mail.example.com",
+		"_sip._tcp.example.com 60 IN SRV 10 5 5060 sip.example.com",
+		"example.com IN TXT \"v=spf1 | mx -all\"",
+		"1.1.1.1.in-addr.arpa IN PTR one.example.com",
+	}
+	for _, linea := range lineas {
+		r, err := ParsearLinea(linea)
+		assert.Nil(t, err)
+		assert.Equal(t, linea, r.Linea())
+	}
+
+	r, err := ParsearLinea("example.com. 60 in mx 20 Mail.Example.com.")
+	assert.Nil(t, err)
+	assert.Equal(t, MX, r.Tipo)
+	assert.Equal(t, uint32(60), r.TTL)
+	assert.Equal(t, uint16(20), r.Prioridad)
+	assert.Equal(t, "mail.example.com", r.Valor)
+
+	for _, invalida := range []string{"google.com IN A", "google.com IN HINFO x", "example.com IN MX mail.example.com", "example.com IN TXT a b"} {
+		_, err = ParsearLinea(invalida)
+		assert.NotNil(t, err, invalida)
+	}
+}
@@ -3,242 +3,823 @@ package registros
 import (
 	"errors"
 	"os"
-	"bufio"
+	"sort"
 	"strings"
-	"log"
 	"strconv"
+	"sync"
+
+	"github.com/jfomu/DNSDistribuido/internal/archivos"
+	"github.com/jfomu/DNSDistribuido/internal/cambios"
+	"github.com/jfomu/DNSDistribuido/internal/nombres"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/jfomu/DNSDistribuido/internal/relojes"
+	"github.com/jfomu/DNSDistribuido/internal/validacion"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-const ( 
+const (
 	RUTA_REGISTROS = "registros/"
 	RUTA_LOGS = "logs/"
 )
 
-var (
-	rutaRegistros string
-	rutaLogs string
-	ID_DNS string
+// Motivos por los que un comando no puede aplicarse sobre los registros actuales de la zona
+const (
+	YA_EXISTE = "YA_EXISTE" // el registro o el nombre ya existe
+	NO_EXISTE = "NO_EXISTE" // el nombre o los registros a modificar no existen
+	DOMINIO_DESCONOCIDO = "DOMINIO_DESCONOCIDO" // el nodo no tiene registrado el dominio
+	CONFLICTO = "CONFLICTO" // el cambio no es compatible con los registros del nombre, por ejemplo un CNAME
 )
 
+// Registro ZF de un dominio. La fuente de verdad es el mapa en memoria: cada cambio se
+// agrega primero al log de cambios (write-ahead) y luego se aplica en memoria, mientras que
+// el archivo de registro ZF es una instantánea que se reescribe periódicamente.
+// Su mutex protege tanto los campos en memoria como los archivos asociados: las lecturas
+// pueden ocurrir en paralelo y las escrituras sobre un mismo dominio se serializan.
 type RegistroZF struct{
-	idDNS string
+	mu sync.RWMutex
+	dominio string
 	rutaReg string  // ruta dentro del sistema donde se almacena el archivo de Registro ZF
 	rutaLog string // ruta dentro del sistema donde se almacena el archivo de Logs de cambios.
+	rutaReloj string // ruta dentro del sistema donde se persiste el reloj de vector del dominio
 	reloj []int32
-	lineas map[string]int // relaciona el nombre de dominio a la linea que ocupa dentro del archivo de registro
-	cantLineas int
+	conjuntos map[string][]*recursos.Recurso // relaciona cada nombre (relativo a la zona) con sus registros
+	pendiente bool // existen cambios que aún no se escriben en el archivo de registro ZF
 }
 
-type Registros map[string]*RegistroZF
+// Conjunto de registros ZF de un nodo DNS. El mutex protege únicamente el mapa de
+// dominios, el acceso a cada dominio se sincroniza con el mutex de su RegistroZF.
+type Registros struct{
+	mu sync.RWMutex
+	idDNS string
+	nodos []string // IDs de los nodos DNS en el orden de la configuración, dan las posiciones del reloj
+	indice int // posición del nodo dentro del reloj de vector
+	zonas []string // zonas declaradas en la configuración
+	rutaRegistros string
+	rutaLogs string
+	dominios map[string]*RegistroZF
+}
 
+// Error de un comando que no puede aplicarse sobre los registros actuales. A diferencia de los
+// errores de validación no depende solo de los datos recibidos, sino del estado de la zona.
+type Error struct{
+	Motivo string
+	Descripcion string
+}
 
-func CrearDirectorio(dir string)  error {
-    if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err = os.Mkdir(dir, 0777); err != nil {
-			return err
-		}
+func (e *Error) Error() string {
+	return e.Descripcion
+}
+
+func nuevoError(motivo string, descripcion string) *Error {
+	return &Error{Motivo: motivo, Descripcion: descripcion}
+}
+
+// Estado completo de un dominio, utilizado para transferirlo entre nodos
+type Estado struct{
+	IdNodo string // nodo del cual se obtuvo el estado
+	Reloj []int32
+	Registro []string // lineas del archivo de registro ZF
+	Log []string // lineas del log de cambios
+}
+
+
+// Divide el contenido de un archivo en lineas, un contenido vacío no tiene lineas
+func DividirLineas(contenido string) []string {
+	if contenido == "" {
+		return []string{}
 	}
-	return nil
+	return strings.Split(contenido, "\n")
 }
 
-func SepararNombreDominio(nombreDominio string) (string, string, error) {
-	split := strings.Split(nombreDominio, ".")
-	if len(split) == 2{
-		return split[0], split[1], nil
-	} 
-	return "", "", errors.New(nombreDominio + " no cumple el formato, debe contener solo un punto") 
+func FusionarRelojes(a []int32, b []int32) []int32 {
+	return relojes.Reloj(a).Fusionar(b)
 }
 
-func (r *RegistroZF) GetReloj() []int32 {
-	return r.reloj
+func RelojATexto(reloj []int32) string {
+	return relojes.Reloj(reloj).String()
 }
 
-func (r *RegistroZF) ExisteNombre(nombre string) bool {
-	if _, ok := r.lineas[nombre]; ok {
-		return true
+func TextoAReloj(texto string) ([]int32, error) {
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return []int32{}, nil
+	}
+	var reloj []int32
+	for _, valor := range strings.Split(texto, ",") {
+		v, err := strconv.Atoi(valor)
+		if err != nil {
+			return nil, errors.New("Reloj inválido: " + texto)
+		}
+		reloj = append(reloj, int32(v))
 	}
-	return false
+	return reloj, nil
 }
 
-func (r *RegistroZF) CargarArchivoRegistro() error {
-	// Abrir el archivo de registro ZF para cargarlo en memoria
-	var file, err = os.OpenFile(r.rutaReg, os.O_RDONLY, 0644)
+func leerLineas(ruta string) ([]string, error) {
+	contenido, err := os.ReadFile(ruta)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return DividirLineas(string(contenido)), nil
+}
+
+func agregarLinea(ruta string, linea string) error {
+	file, err := os.OpenFile(ruta, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
-	fileScanner := bufio.NewScanner(file)
-	fileScanner.Split(bufio.ScanLines)
-	var lineasArchivo []string
-	for fileScanner.Scan() {
-		lineasArchivo = append(lineasArchivo, fileScanner.Text())
+	defer file.Close()
+	_, err = file.WriteString(linea)
+	return err
+}
+
+// Posición de un nodo dentro del reloj de vector: su orden en la lista de nodos de la configuración
+func IndiceNodo(nodos []string, id string) (int, error) {
+	for i, nodo := range nodos {
+		if nodo == id {
+			return i, nil
+		}
+	}
+	return 0, errors.New("El nodo " + id + " no está en la configuración")
+}
+
+
+//// FUNCIONES DEL REGISTRO ZF (el llamador debe mantener el mutex del registro)
+
+func (r *RegistroZF) ExisteNombre(nombre string) bool {
+	_, ok := r.conjuntos[nombre]
+	return ok
+}
+
+// Copia los registros de un nombre, de modo que puedan entregarse fuera del mutex
+func (r *RegistroZF) conjuntoCopia(nombre string) []*recursos.Recurso {
+	copia := make([]*recursos.Recurso, len(r.conjuntos[nombre]))
+	for i, rr := range r.conjuntos[nombre] {
+		copia[i] = rr.Copia()
 	}
-	file.Close() // Cerramos el archivo
+	return copia
+}
 
-	if len(lineasArchivo) != 0 { // Si el archivo no está vacio
-		for i, linea := range lineasArchivo {
-			splitLinea := strings.Split(linea, " IN A ")
-			nombre, _, err := SepararNombreDominio(splitLinea[0])
-			if err != nil {
-				return err
-			}
-			r.lineas[nombre] = i // Asociamos el nombre de dominio a la linea del archivo
-			r.cantLineas += 1 // Aumentamos el contador de lineas
+func (r *RegistroZF) relojCopia() []int32 {
+	return append([]int32{}, r.reloj...)
+}
+
+// Genera las lineas del registro ZF a partir de la memoria, ordenadas por nombre
+func (r *RegistroZF) lineasRegistro() []string {
+	relativos := make([]string, 0, len(r.conjuntos))
+	for nombre := range r.conjuntos {
+		relativos = append(relativos, nombre)
+	}
+	sort.Strings(relativos)
+
+	lineas := []string{}
+	for _, nombre := range relativos {
+		for _, rr := range r.conjuntos[nombre] {
+			lineas = append(lineas, rr.Linea())
 		}
-	} else{
-		log.Println("EL ARCHIVO ESTA VACIO")
+	}
+	return lineas
+}
+
+// Reemplaza el contenido en memoria por el de las lineas de un registro ZF
+func (r *RegistroZF) cargarLineas(lineas []string) error {
+	r.conjuntos = make(map[string][]*recursos.Recurso)
+	for _, linea := range lineas {
+		if strings.TrimSpace(linea) == "" { // Lineas de nombres eliminados en archivos antiguos
+			continue
+		}
+		rr, err := recursos.ParsearLinea(linea)
+		if err != nil {
+			return errors.New("Datos corruptos en el registro ZF: " + err.Error())
+		}
+		if rr.Nombre, err = nombres.Normalizar(rr.Nombre); err != nil {
+			return err
+		}
+		if !nombres.EnZona(rr.Nombre, r.dominio) {
+			return errors.New("El nombre " + rr.Nombre + " no pertenece a la zona " + r.dominio)
+		}
+		relativo := nombres.Relativo(rr.Nombre, r.dominio)
+		r.conjuntos[relativo] = cambios.AgregarRecurso(r.conjuntos[relativo], rr)
 	}
 	return nil
 }
 
-func (r *RegistroZF) EscribirLineaLog(linea string) error {
-	// Abrir archivo de logs
-	file, err := os.OpenFile(r.rutaLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// Carga en memoria el registro desde sus archivos. Como el log de cambios siempre reproduce
+// el registro se utiliza como fuente, el archivo de registro ZF solo se usa si no hay log.
+// El reloj se recupera desde su archivo y desde las marcas del log.
+func (r *RegistroZF) cargar(idDNS string, largo int) error {
+	r.reloj = make([]int32, largo)
+
+	lineasLog, err := leerLineas(r.rutaLog)
 	if err != nil {
 		return err
 	}
-	// Escribir linea en el archivo de logs
-	if _, err = file.WriteString(linea); err != nil {
+	operaciones, err := cambios.Fusionar(map[string][]string{idDNS: lineasLog})
+	if err != nil {
 		return err
 	}
-	file.Close()
+
+	if len(operaciones) > 0 {
+		if err := r.cargarLineas(cambios.Reproducir(operaciones)); err != nil {
+			return err
+		}
+		r.pendiente = true // La instantánea puede no incluir los últimos cambios del log
+	} else {
+		lineas, err := leerLineas(r.rutaReg)
+		if err != nil {
+			return err
+		}
+		if err := r.cargarLineas(lineas); err != nil {
+			return err
+		}
+	}
+
+	// El reloj es el máximo entre el persistido y los registrados en el log de cambios,
+	// ya que el log se escribe antes que el archivo del reloj
+	contenidoReloj, err := os.ReadFile(r.rutaReloj)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	reloj, err := TextoAReloj(string(contenidoReloj))
+	if err != nil {
+		return err
+	}
+	r.reloj = FusionarRelojes(r.reloj, reloj)
+	for _, op := range operaciones {
+		r.reloj = FusionarRelojes(r.reloj, op.Reloj)
+	}
 	return nil
 }
 
-func (r *RegistroZF) EscribirLineaRegistro(linea string) error {
-	// Abrir archivo de logs
-	file, err := os.OpenFile(r.rutaReg, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// Avanza la posición del nodo en el reloj y agrega el cambio al log junto al reloj resultante.
+// Debe llamarse antes de aplicar el cambio en memoria. Si falla la escritura del log o del reloj,
+// el log se trunca a su largo anterior y el reloj vuelve a su valor anterior, de modo que el
+// cambio no quede registrado a medias.
+func (r *RegistroZF) registrarCambio(idDNS string, indice int, op *cambios.Operacion) error {
+	relojAnterior := r.relojCopia()
+	r.reloj = relojes.Reloj(r.reloj).Ajustar(indice + 1)
+	r.reloj[indice] += 1
+
+	op.Origen = idDNS
+	op.Reloj = r.relojCopia()
+	// Si es la primera linea del log no se agrega el salto de linea al comienzo
+	var largoLog int64
+	info, err := os.Stat(r.rutaLog)
+	if err != nil && !os.IsNotExist(err) {
+		r.reloj = relojAnterior
+		return err
+	}
+	if err == nil {
+		largoLog = info.Size()
+	}
+	salto := "\n"
+	if largoLog == 0 {
+		salto = ""
+	}
+	err = agregarLinea(r.rutaLog, salto + op.Linea())
+	if err == nil {
+		err = archivos.EscribirArchivoAtomico(r.rutaReloj, []byte(RelojATexto(r.reloj)))
+	}
 	if err != nil {
+		r.reloj = relojAnterior
+		if errTruncar := os.Truncate(r.rutaLog, largoLog); errTruncar != nil && !os.IsNotExist(errTruncar) {
+			return errors.New(err.Error() + "; no se pudo descartar el cambio del log: " + errTruncar.Error())
+		}
 		return err
 	}
-	// Escribir linea en el archivo de logs
-	if _, err = file.WriteString(linea); err != nil {
+	r.pendiente = true
+	return nil
+}
+
+// Escribe la instantánea del registro ZF si tiene cambios pendientes
+func (r *RegistroZF) guardar() error {
+	if !r.pendiente {
+		return nil
+	}
+	if err := archivos.EscribirArchivoAtomico(r.rutaReg, []byte(strings.Join(r.lineasRegistro(), "\n"))); err != nil {
 		return err
 	}
-	file.Close()
+	r.pendiente = false
 	return nil
 }
 
-func (r *RegistroZF) AvanzarReloj() error {
-	idIndex, err := strconv.Atoi(string(ID_DNS))
+// Reemplaza el contenido del registro, su log de cambios y su reloj por un estado recibido
+func (r *RegistroZF) aplicar(estado *Estado, largo int) error {
+	if err := r.cargarLineas(estado.Registro); err != nil {
+		return err
+	}
+	r.reloj = relojes.Reloj(estado.Reloj).Ajustar(largo)
+	if err := archivos.EscribirArchivoAtomico(r.rutaLog, []byte(strings.Join(estado.Log, "\n"))); err != nil {
+		return err
+	}
+	if err := archivos.EscribirArchivoAtomico(r.rutaReloj, []byte(RelojATexto(r.reloj))); err != nil {
+		return err
+	}
+	r.pendiente = true
+	return r.guardar()
+}
+
+
+//// FUNCIONES DEL CONJUNTO DE REGISTROS
+
+// Inicia el conjunto de registros del nodo, creando los directorios asociados. Los relojes de
+// vector tienen una posición por cada nodo indicado, en el mismo orden, que debe incluir al nodo.
+// Las zonas declaradas se consideran al separar un nombre aunque aún no tengan registro.
+func NuevoRegistros(id string, nodos []string, zonas []string) (*Registros, error) {
+	indice, err := IndiceNodo(nodos, id)
+	if err != nil {
+		return nil, err
+	}
+	r := new(Registros)
+	r.idDNS = id
+	r.nodos = nodos
+	r.indice = indice
+	r.zonas = zonas
+	r.rutaRegistros = RUTA_REGISTROS + id + "/"
+	r.rutaLogs = RUTA_LOGS + id + "/"
+	r.dominios = make(map[string]*RegistroZF)
+
+	// Verificar que existan los directorios asociados al registro
+	if err := archivos.CrearDirectorio(r.rutaRegistros); err != nil {
+		return nil, err
+	}
+	if err := archivos.CrearDirectorio(r.rutaLogs); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Actualiza la lista de nodos que da las posiciones del reloj, por ejemplo al cambiar la
+// membresía del clúster. La lista debe incluir al nodo, cuya posición puede cambiar solo
+// mientras aún no registra cambios (al unirse al clúster).
+func (r *Registros) ActualizarNodos(nodos []string) error {
+	indice, err := IndiceNodo(nodos, r.idDNS)
 	if err != nil {
 		return err
 	}
-	r.reloj[idIndex - 1] += 1
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodos = nodos
+	r.indice = indice
 	return nil
 }
 
-func (r Registros) AgregarRegistro(nombre string, dominio string, ip string) error {
-	if r.ExisteRegistroMemoria(dominio) {
-		if r.ExisteArchivoRegistro(dominio) {
-			if !r[dominio].ExisteNombre(nombre) {
-				// Si es la primera linea del registro no se agrega el salto de linea al comienzo
-				var salto string
-				if r[dominio].cantLineas != 0 {
-					salto = ""
-				} else {
-					salto = "\n"
-				}
-
-				// Escribir linea en el archivo de registro
-				linea := salto + nombre + "." + dominio + " IN A " + ip
-				if err := r[dominio].EscribirLineaRegistro(linea); err != nil {
-					return err
-				}
-				
-				r[dominio].lineas[nombre] = r[dominio].cantLineas // Se almacena en memoria el indice de la linea donde se encuentra el nombre
-				r[dominio].cantLineas += 1 // Se incrementa la cantidad de lineas
-				
-				// Escribir linea en el archivo de logs
-				linea = salto + "create " + nombre + "." + dominio + " " + ip
-				if err := r[dominio].EscribirLineaLog(linea); err != nil {
-					return err
-				}
-
-				// Actualizar reloj de vector
-				r[dominio].AvanzarReloj()
-
-				return nil
-				
+// Posición del nodo en el reloj y largo de los relojes nuevos
+func (r *Registros) posicion() (int, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.indice, len(r.nodos)
+}
+
+// Posición en el reloj del nodo donde se realizó un cambio
+func (r *Registros) indiceOrigen(origen string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return IndiceNodo(r.nodos, origen)
+}
+
+// Debe llamarse manteniendo el mutex
+func (r *Registros) nuevoRegistroZF(dominio string) *RegistroZF {
+	registro := new(RegistroZF)
+	registro.dominio = dominio
+	registro.rutaReg = r.rutaRegistros + dominio
+	registro.rutaLog = r.rutaLogs + dominio + ".log"
+	registro.rutaReloj = r.rutaLogs + dominio + ".reloj"
+	registro.reloj = make([]int32, len(r.nodos))
+	registro.conjuntos = make(map[string][]*recursos.Recurso)
+	return registro
+}
+
+func (r *Registros) obtenerRegistro(dominio string) (*RegistroZF, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	registro, ok := r.dominios[dominio]
+	return registro, ok
+}
+
+// Obtiene el registro de un dominio, iniciándolo (y cargando sus archivos si existen) si no está en memoria
+func (r *Registros) obtenerOCrearRegistro(dominio string) (*RegistroZF, error) {
+	if registro, ok := r.obtenerRegistro(dominio); ok {
+		return registro, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if registro, ok := r.dominios[dominio]; ok { // Otro llamador lo inició mientras se esperaba el mutex
+		return registro, nil
+	}
+	registro := r.nuevoRegistroZF(dominio)
+	if err := registro.cargar(r.idDNS, len(r.nodos)); err != nil {
+		return nil, err
+	}
+	r.dominios[dominio] = registro
+	return registro, nil
+}
+
+func (r *Registros) ExisteRegistroMemoria(dominio string) bool {
+	_, ok := r.obtenerRegistro(dominio)
+	return ok
+}
+
+// Separa un nombre de dominio en su nombre relativo y la zona más específica que lo contiene
+func (r *Registros) SepararNombre(nombreDominio string) (string, string, error) {
+	return nombres.Separar(nombreDominio, append(r.Dominios(), r.zonas...))
+}
+
+// Busca la zona de este nodo que contiene a un nombre (ya normalizado), sin recurrir al dominio padre
+func (r *Registros) BuscarZona(nombre string) (string, string, bool) {
+	zona, ok := nombres.BuscarZona(nombre, append(r.Dominios(), r.zonas...))
+	if !ok {
+		return "", "", false
+	}
+	return nombres.Relativo(nombre, zona), zona, true
+}
+
+func (r *Registros) Dominios() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dominios := make([]string, 0, len(r.dominios))
+	for d := range r.dominios {
+		dominios = append(dominios, d)
+	}
+	sort.Strings(dominios)
+	return dominios
+}
+
+// Carga en memoria todos los registros ZF almacenados por este nodo. Los dominios se obtienen
+// tanto de los archivos de registro como de los logs, ya que la instantánea puede no existir aún.
+func (r *Registros) Cargar() error {
+	dominios := make(map[string]bool)
+	for _, dir := range []string{r.rutaRegistros, r.rutaLogs} {
+		entradas, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entrada := range entradas {
+			// Ignorar directorios y archivos temporales de escrituras interrumpidas
+			if entrada.IsDir() || strings.HasPrefix(entrada.Name(), ".") {
+				continue
+			}
+			if dir == r.rutaRegistros {
+				dominios[entrada.Name()] = true
+			} else if strings.HasSuffix(entrada.Name(), ".log") {
+				dominios[strings.TrimSuffix(entrada.Name(), ".log")] = true
 			}
-			return errors.New("El nombre que se quiere agregar ya existe en el registro")
 		}
-		return errors.New("No es posible encontrar el archivo de registro asociado al dominio")
 	}
-	return errors.New("El dominio no se encuentra registrado en memoria")
+
+	for dominio := range dominios {
+		r.mu.Lock()
+		registro := r.nuevoRegistroZF(dominio)
+		r.mu.Unlock()
+		_, largo := r.posicion()
+		if err := registro.cargar(r.idDNS, largo); err != nil {
+			return errors.New("Error al cargar el registro " + dominio + ": " + err.Error())
+		}
+		r.mu.Lock()
+		r.dominios[dominio] = registro
+		r.mu.Unlock()
+	}
+	return nil
 }
 
-func (r Registros) Init(id string) error {
-	rutaRegistros = RUTA_REGISTROS + id + "/"
-	rutaLogs = RUTA_LOGS + id + "/"
-	ID_DNS = id
+func (r *Registros) Reloj(dominio string) ([]int32, error) {
+	registro, ok := r.obtenerRegistro(dominio)
+	if !ok {
+		return nil, errors.New("No se encuentra el dominio registrado: " + dominio)
+	}
+	registro.mu.RLock()
+	defer registro.mu.RUnlock()
+	return registro.relojCopia(), nil
+}
 
-	r = make(Registros)
+// Comando GET: retorna los registros del tipo indicado asociados al nombre y el reloj del dominio.
+// Un tipo vacío retorna todos los registros del nombre. Si el nombre es un alias (CNAME) se
+// retorna el CNAME, un nombre sin registros del tipo consultado retorna una lista vacía.
+func (r *Registros) Obtener(nombre string, dominio string, tipo string) ([]*recursos.Recurso, []int32, error) {
+	registro, ok := r.obtenerRegistro(dominio)
+	if !ok { // Si no se encuentra el dominio registrado
+		return nil, nil, errors.New("No se encuentra el dominio registrado: " + dominio)
+	}
 
-	// Verificar que existan los directorios asociados al registro
-	if err := CrearDirectorio(RUTA_REGISTROS); err != nil {
-		return err
+	registro.mu.RLock()
+	defer registro.mu.RUnlock()
+	if !registro.ExisteNombre(nombre) {
+		return nil, nil, errors.New("No es posible encontrar en el registro ZF el nombre: " + nombre)
 	}
-	if err := CrearDirectorio(RUTA_LOGS); err != nil {
-		return err
-	} 
-	if err := CrearDirectorio(rutaRegistros); err != nil {
-		return err
+
+	resultado := []*recursos.Recurso{}
+	for _, rr := range registro.conjuntoCopia(nombre) {
+		if tipo == "" || rr.Tipo == tipo || rr.Tipo == recursos.CNAME {
+			resultado = append(resultado, rr)
+		}
 	}
-	if err := CrearDirectorio(rutaLogs); err != nil {
-		return err
+	return resultado, registro.relojCopia(), nil
+}
+
+// Verifica que un registro pueda agregarse a los registros de un nombre: no puede repetirse
+// y un CNAME no puede coexistir con otros registros
+func verificarConjunto(conjunto []*recursos.Recurso, rr *recursos.Recurso) error {
+	for _, actual := range conjunto {
+		if actual.Igual(rr) {
+			return nuevoError(YA_EXISTE, "El registro que se intenta agregar ya existe en este servidor")
+		}
+		if actual.Tipo == recursos.CNAME || rr.Tipo == recursos.CNAME {
+			return nuevoError(CONFLICTO, "Un registro CNAME no puede coexistir con otros registros del nombre " + rr.Nombre)
+		}
 	}
 	return nil
 }
 
-func (r Registros) ExisteRegistroMemoria(dominio string) bool {
-    if _, ok := r[dominio]; ok {
-		return true
+// Comando CREATE: agrega un registro al nombre, creando el registro del dominio si no existe.
+// Un nombre puede tener varios registros, por ejemplo varios registros A.
+func (r *Registros) Crear(nombre string, dominio string, rr *recursos.Recurso) ([]int32, error) {
+	return r.crear(nombre, dominio, rr, r.idDNS)
+}
+
+func (r *Registros) crear(nombre string, dominio string, rr *recursos.Recurso, origen string) ([]int32, error) {
+	registro, err := r.obtenerOCrearRegistro(dominio)
+	if err != nil {
+		return nil, err
+	}
+
+	nuevo := rr.Copia()
+	nuevo.Nombre = nombres.Unir(nombre, dominio)
+	if nuevo.TTL == 0 {
+		nuevo.TTL = recursos.TTL_POR_DEFECTO
+	}
+	if err := nuevo.Validar(); err != nil {
+		return nil, err
+	}
+
+	registro.mu.Lock()
+	defer registro.mu.Unlock()
+	if err := verificarConjunto(registro.conjuntos[nombre], nuevo); err != nil {
+		return nil, err
+	}
+
+	op := &cambios.Operacion{Tipo: cambios.CREATE, NombreDominio: nuevo.Nombre, Recurso: nuevo}
+	indice, err := r.indiceOrigen(origen)
+	if err != nil {
+		return nil, err
+	}
+	if err := registro.registrarCambio(origen, indice, op); err != nil {
+		return nil, err
+	}
+	registro.conjuntos[nombre] = append(registro.conjuntos[nombre], nuevo)
+	return registro.relojCopia(), nil
+}
+
+// Comando DELETE: elimina los registros de un nombre. Si se indica un tipo solo se eliminan los
+// registros de ese tipo y si además se indican datos solo el registro con esos datos.
+func (r *Registros) Eliminar(nombre string, dominio string, tipo string, datos string) ([]int32, error) {
+	return r.eliminar(nombre, dominio, tipo, datos, r.idDNS)
+}
+
+func (r *Registros) eliminar(nombre string, dominio string, tipo string, datos string, origen string) ([]int32, error) {
+	if tipo != "" && !recursos.TipoValido(tipo) {
+		return nil, validacion.Nuevo("tipo", "Tipo de registro no soportado: " + tipo)
+	}
+	registro, ok := r.obtenerRegistro(dominio)
+	if !ok { // Si no se encuentra el dominio registrado
+		return nil, nuevoError(DOMINIO_DESCONOCIDO, "No se encuentra el dominio registrado: " + dominio)
+	}
+
+	registro.mu.Lock()
+	defer registro.mu.Unlock()
+	if !registro.ExisteNombre(nombre) {
+		return nil, nuevoError(NO_EXISTE, "No es posible encontrar en el registro ZF el nombre: " + nombre)
+	}
+	restantes := cambios.EliminarRecursos(registro.conjuntos[nombre], tipo, datos)
+	if len(restantes) == len(registro.conjuntos[nombre]) {
+		return nil, nuevoError(NO_EXISTE, "No existen registros " + tipo + " que eliminar para el nombre: " + nombre)
+	}
+
+	op := &cambios.Operacion{Tipo: cambios.DELETE, NombreDominio: nombres.Unir(nombre, dominio), Opcion: tipo, Valor: datos}
+	indice, err := r.indiceOrigen(origen)
+	if err != nil {
+		return nil, err
+	}
+	if err := registro.registrarCambio(origen, indice, op); err != nil {
+		return nil, err
+	}
+	if len(restantes) == 0 {
+		delete(registro.conjuntos, nombre)
+	} else {
+		registro.conjuntos[nombre] = restantes
+	}
+	return registro.relojCopia(), nil
+}
+
+// Verifica el parámetro de un update de ip o ttl
+func validarUpdate(opcion string, param string) error {
+	switch opcion {
+	case "ip":
+		if recursos.TipoDireccion(param) == recursos.AAAA {
+			return validacion.IPv6("param", param)
+		}
+		return validacion.IPv4("param", param)
+	case "ttl":
+		ttl, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return validacion.Nuevo("param", param + " no es un TTL válido")
+		}
+		return validacion.TTL("param", ttl)
 	}
-	return false
+	return validacion.Nuevo("opcion", "Opción de update desconocida: " + opcion + ", debe ser ip, name o ttl")
+}
+
+// Comando UPDATE: cambia la dirección (opción ip), el nombre (opción name) o el TTL (opción ttl)
+// de los registros asociados a un nombre
+func (r *Registros) Actualizar(nombre string, dominio string, opcion string, param string) ([]int32, error) {
+	return r.actualizar(nombre, dominio, opcion, param, r.idDNS)
 }
 
-func (r Registros) ExisteArchivoRegistro(dominio string) bool {
-	if 	_, err := os.Stat(rutaRegistros + dominio); os.IsNotExist(err){
-		return false
+func (r *Registros) actualizar(nombre string, dominio string, opcion string, param string, origen string) ([]int32, error) {
+	registro, ok := r.obtenerRegistro(dominio)
+	if !ok { // Si no se encuentra el dominio registrado
+		return nil, nuevoError(DOMINIO_DESCONOCIDO, "No se encuentra el dominio registrado: " + dominio)
+	}
+
+	registro.mu.Lock()
+	defer registro.mu.Unlock()
+	conjunto, ok := registro.conjuntos[nombre]
+	if !ok {
+		return nil, nuevoError(NO_EXISTE, "No es posible encontrar en el registro ZF el nombre: " + nombre)
+	}
+
+	// Calcular los registros actualizados
+	nombreNuevo := nombre
+	var actualizado []*recursos.Recurso
+	var err error
+	if opcion == "name" {
+		// El nuevo nombre puede indicarse relativo a la zona o completo
+		nombreNuevo = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(param)), ".")
+		if nombres.EnZona(nombreNuevo, dominio) {
+			nombreNuevo = nombres.Relativo(nombreNuevo, dominio)
+		}
+		if _, err := nombres.NormalizarCampo("param", nombres.Unir(nombreNuevo, dominio)); err != nil || nombreNuevo == "" {
+			return nil, validacion.Nuevo("param", param + " no es un nombre válido dentro de la zona " + dominio)
+		}
+		if nombreNuevo != nombre && registro.ExisteNombre(nombreNuevo) {
+			return nil, nuevoError(YA_EXISTE, "El nombre " + nombres.Unir(nombreNuevo, dominio) + " ya existe en este servidor")
+		}
+		param = nombres.Unir(nombreNuevo, dominio)
+		actualizado = cambios.Renombrar(conjunto, param)
+	} else {
+		if err := validarUpdate(opcion, param); err != nil {
+			return nil, err
+		}
+		if opcion == "ip" && conjunto[0].Tipo == recursos.CNAME {
+			return nil, nuevoError(CONFLICTO, "El nombre " + nombres.Unir(nombre, dominio) + " es un alias (CNAME) y no puede tener direcciones")
+		}
+		if actualizado, err = cambios.Actualizar(conjunto, opcion, param); err != nil {
+			return nil, err
+		}
 	}
-	return true
+
+	op := &cambios.Operacion{Tipo: cambios.UPDATE, NombreDominio: nombres.Unir(nombre, dominio), Opcion: opcion, Valor: param}
+	indice, err := r.indiceOrigen(origen)
+	if err != nil {
+		return nil, err
+	}
+	if err := registro.registrarCambio(origen, indice, op); err != nil {
+		return nil, err
+	}
+	delete(registro.conjuntos, nombre)
+	registro.conjuntos[nombreNuevo] = actualizado
+	return registro.relojCopia(), nil
+}
+
+// Aplica un cambio realizado en otro nodo (op.Origen), avanzando la posición de ese nodo en el
+// reloj. Las zonas replicadas con Raft aplican en todos los nodos los mismos cambios en el mismo
+// orden, por lo que sus registros y relojes coinciden. De la operación se utilizan el tipo, el
+// registro (create), la opción y el valor.
+func (r *Registros) Ejecutar(nombre string, dominio string, op *cambios.Operacion) ([]int32, error) {
+	switch op.Tipo {
+	case cambios.CREATE:
+		if op.Recurso == nil {
+			return nil, errors.New("Operación create sin registro")
+		}
+		return r.crear(nombre, dominio, op.Recurso, op.Origen)
+	case cambios.DELETE:
+		return r.eliminar(nombre, dominio, op.Opcion, op.Valor, op.Origen)
+	case cambios.UPDATE:
+		return r.actualizar(nombre, dominio, op.Opcion, op.Valor, op.Origen)
+	}
+	return nil, errors.New("Operación desconocida: " + op.Tipo)
 }
 
-func (r Registros) CrearRegistro(dominio string) error {
-	if !r.ExisteRegistroMemoria(dominio){
-		// Iniciar nuevo registro ZF en memoria
-		r[dominio] = new(RegistroZF)
-			
-		// Asociar las rutas correspondientes al registro ZF
-		r[dominio].rutaReg = rutaRegistros + dominio
-		r[dominio].rutaLog = rutaLogs + dominio + ".log"
+// Obtiene una copia del registro ZF, el log de cambios y el reloj de un dominio
+func (r *Registros) Estado(dominio string) (*Estado, error) {
+	registro, ok := r.obtenerRegistro(dominio)
+	if !ok {
+		return nil, errors.New("No se encuentra el dominio registrado: " + dominio)
+	}
 
-		// Inicializar variables del registro ZF
-		r[dominio].reloj = []int32{0, 0, 0}
-		r[dominio].lineas = make(map[string]int)
-		r[dominio].cantLineas = 0
+	registro.mu.RLock()
+	defer registro.mu.RUnlock()
 
-		// Verificar que no exista el archivo de registro asociado a el dominio
-		if 	!r.ExisteArchivoRegistro(dominio) { // Si no existe el archivo de registros
-			// Generar archivo de registros
-			regFile, err := os.Create(r[dominio].rutaReg)
-			if err != nil {
-				return err
-			}
-			regFile.Close()
+	estado := new(Estado)
+	estado.IdNodo = r.idDNS
+	estado.Reloj = registro.relojCopia()
+	estado.Registro = registro.lineasRegistro()
+	var err error
+	if estado.Log, err = leerLineas(registro.rutaLog); err != nil {
+		return nil, err
+	}
+	return estado, nil
+}
 
-			// Generar archivo de logs
-			logFile, err := os.Create(r[dominio].rutaLog)
-			if err != nil {
-				return err
-			}
-			logFile.Close()
-		} else { // Si existe el archivo de registro ZF
-			r[dominio].CargarArchivoRegistro()
+// Reemplaza el registro ZF, el log de cambios y el reloj de un dominio por el estado recibido
+func (r *Registros) Aplicar(dominio string, estado *Estado) error {
+	return r.AplicarTodos(map[string]*Estado{dominio: estado})
+}
+
+// Reemplaza el estado de varios dominios a la vez: se mantienen los mutex de todos sus registros
+// (tomados en orden para evitar bloqueos mutuos) mientras se aplican, de modo que las consultas
+// observan el estado anterior o el nuevo de todos ellos
+func (r *Registros) AplicarTodos(estados map[string]*Estado) error {
+	dominios := make([]string, 0, len(estados))
+	for dominio := range estados {
+		dominios = append(dominios, dominio)
+	}
+	sort.Strings(dominios)
+
+	registrosZF := make([]*RegistroZF, len(dominios))
+	for i, dominio := range dominios {
+		registro, err := r.obtenerOCrearRegistro(dominio)
+		if err != nil {
+			return err
 		}
+		registrosZF[i] = registro
+	}
+	for _, registro := range registrosZF {
+		registro.mu.Lock()
+		defer registro.mu.Unlock()
+	}
 
-		
-		return nil
+	_, largo := r.posicion()
+	for i, registro := range registrosZF {
+		if err := registro.aplicar(estados[dominios[i]], largo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Escribe las instantáneas de los registros ZF con cambios pendientes
+func (r *Registros) Guardar() error {
+	for _, dominio := range r.Dominios() {
+		registro, _ := r.obtenerRegistro(dominio)
+		registro.mu.Lock()
+		err := registro.guardar()
+		registro.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Descarta el log de cambios de un dominio, cuya instantánea pasa a incluir todos sus cambios. Se
+// utiliza en las zonas replicadas con Raft, cuyos cambios no se fusionan en las rondas, una vez
+// persistido el snapshot del grupo. La instantánea se escribe antes de truncar el log, de modo que
+// si el proceso termina entre ambos el registro se recupera igualmente desde el log.
+func (r *Registros) Compactar(dominio string) error {
+	registro, ok := r.obtenerRegistro(dominio)
+	if !ok {
+		return errors.New("No se encuentra el dominio registrado: " + dominio)
+	}
+	registro.mu.Lock()
+	defer registro.mu.Unlock()
+	registro.pendiente = true
+	if err := registro.guardar(); err != nil {
+		return err
 	}
-	return errors.New("El registro del dominio " + dominio + " ya existe")
-}
\ No newline at end of file
+	return archivos.EscribirArchivoAtomico(registro.rutaLog, []byte{})
+}
+
+
+//// ERRORES gRPC
+
+// Convierte los errores de los comandos en errores gRPC: AlreadyExists, NotFound o
+// FailedPrecondition según su motivo, e InvalidArgument los de validación. Los demás errores se
+// retornan sin cambios.
+func ErrorGRPC(err error) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return validacion.ErrorGRPC(err)
+	}
+	switch e.Motivo {
+	case YA_EXISTE:
+		return status.Error(codes.AlreadyExists, e.Descripcion)
+	case NO_EXISTE:
+		return status.Error(codes.NotFound, e.Descripcion)
+	}
+	return status.Error(codes.FailedPrecondition, e.Descripcion)
+}
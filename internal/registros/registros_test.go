@@ -0,0 +1,365 @@
+package registros
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/cambios"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func direccion(ip string) *recursos.Recurso {
+	return &recursos.Recurso{Tipo: recursos.TipoDireccion(ip), Valor: ip}
+}
+
+// Obtiene la única dirección A asociada a un nombre
+func obtenerIP(r *Registros, nombre string, dominio string) (string, []int32, error) {
+	conjunto, reloj, err := r.Obtener(nombre, dominio, recursos.A)
+	if err != nil || len(conjunto) != 1 {
+		return "", reloj, err
+	}
+	return conjunto[0].Valor, reloj, nil
+}
+
+var nodosPrueba = []string{"DNS1", "DNS2", "DNS3"}
+
+// Crea un conjunto de registros vacío dentro de un directorio temporal
+func nuevoRegistrosPrueba(t *testing.T, id string) *Registros {
+	dir, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(dir) })
+
+	r, err := NuevoRegistros(id, nodosPrueba, nil)
+	assert.Nil(t, err)
+	return r
+}
+
+func TestCrearObtener(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS2")
+
+	reloj, err := r.Crear("google", "com", direccion("8.8.8.8"))
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{0,1,0}, reloj)
+
+	_, err = r.Crear("google", "com", direccion("8.8.8.8"))
+	assert.Equal(t, codes.AlreadyExists, status.Code(ErrorGRPC(err)))
+
+	ip, reloj, err := obtenerIP(r, "google", "com")
+	assert.Nil(t, err)
+	assert.Equal(t, "8.8.8.8", ip)
+	assert.Equal(t, []int32{0,1,0}, reloj)
+
+	_, _, err = r.Obtener("bing", "com", recursos.A)
+	assert.NotNil(t, err)
+}
+
+func TestActualizarEliminar(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS1")
+	r.Crear("google", "com", direccion("8.8.8.8"))
+	r.Crear("bing", "com", direccion("1.1.1.1"))
+
+	_, err := r.Actualizar("google", "com", "name", "bing")
+	assert.Equal(t, codes.AlreadyExists, status.Code(ErrorGRPC(err))) // El nombre ya existe
+	_, err = r.Actualizar("yahoo", "com", "ttl", "60")
+	assert.Equal(t, codes.NotFound, status.Code(ErrorGRPC(err)))
+	_, err = r.Eliminar("google", "com", recursos.MX, "")
+	assert.Equal(t, codes.NotFound, status.Code(ErrorGRPC(err)))
+	_, err = r.Eliminar("google", "org", "", "")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(ErrorGRPC(err)))
+	_, err = r.Actualizar("google", "com", "ip", "8.8")
+	assert.Equal(t, codes.InvalidArgument, status.Code(ErrorGRPC(err)))
+
+	_, err = r.Actualizar("google", "com", "name", "gmail")
+	assert.Nil(t, err)
+	_, _, err = r.Obtener("google", "com", recursos.A)
+	assert.NotNil(t, err)
+
+	reloj, err := r.Actualizar("gmail", "com", "ip", "8.8.4.4")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{4,0,0}, reloj)
+
+	reloj, err = r.Eliminar("bing", "com", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{5,0,0}, reloj)
+
+	// Al recargar desde disco se recupera el mismo estado
+	recargado, err := NuevoRegistros("DNS1", nodosPrueba, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, recargado.Cargar())
+	ip, reloj, err := obtenerIP(recargado, "gmail", "com")
+	assert.Nil(t, err)
+	assert.Equal(t, "8.8.4.4", ip)
+	assert.Equal(t, []int32{5,0,0}, reloj)
+	_, _, err = recargado.Obtener("bing", "com", recursos.A)
+	assert.NotNil(t, err)
+}
+
+// Las posiciones del reloj siguen el orden de los nodos en la configuración, con cualquier ID
+func TestPosicionesReloj(t *testing.T) {
+	dir, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(dir)
+
+	nodos := []string{"DNS1", "DNS2", "DNS10", "norte"}
+	_, err = NuevoRegistros("DNS4", nodos, nil)
+	assert.NotNil(t, err)
+
+	r, err := NuevoRegistros("DNS10", nodos, nil)
+	assert.Nil(t, err)
+	reloj, err := r.Crear("google", "com", direccion("8.8.8.8"))
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{0,0,1,0}, reloj)
+
+	// Un estado de un clúster más pequeño se completa con ceros
+	assert.Nil(t, r.Aplicar("cl", &Estado{Reloj: []int32{2,1}, Registro: []string{}, Log: []string{}}))
+	reloj, err = r.Reloj("cl")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{2,1,0,0}, reloj)
+}
+
+// Los cambios de otro nodo avanzan la posición de ese nodo en el reloj
+func TestEjecutar(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS1")
+	reloj, err := r.Ejecutar("www", "cl", &cambios.Operacion{Tipo: cambios.CREATE, Recurso: direccion("1.1.1.1"), Origen: "DNS2"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{0,1,0}, reloj)
+	reloj, err = r.Ejecutar("www", "cl", &cambios.Operacion{Tipo: cambios.UPDATE, Opcion: "ip", Valor: "2.2.2.2", Origen: "DNS3"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{0,1,1}, reloj)
+	ip, _, err := obtenerIP(r, "www", "cl")
+	assert.Nil(t, err)
+	assert.Equal(t, "2.2.2.2", ip)
+
+	_, err = r.Ejecutar("www", "cl", &cambios.Operacion{Tipo: cambios.DELETE, Origen: "DNS4"})
+	assert.NotNil(t, err) // Nodo fuera de la configuración
+	reloj, err = r.Ejecutar("www", "cl", &cambios.Operacion{Tipo: cambios.DELETE, Opcion: recursos.A, Origen: "DNS1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{1,1,1}, reloj)
+	assert.False(t, r.ExisteRegistroMemoria("cl") && len(r.dominios["cl"].conjuntos) > 0)
+}
+
+func TestGuardar(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS1")
+	r.Crear("yahoo", "com", direccion("2.2.2.2"))
+	r.Crear("google", "com", direccion("8.8.8.8"))
+	r.Crear("bing", "com", direccion("1.1.1.1"))
+	r.Eliminar("bing", "com", "", "")
+
+	// La instantánea solo se escribe al guardar
+	contenido, _ := os.ReadFile(RUTA_REGISTROS + "DNS1/com")
+	assert.Equal(t, "", string(contenido))
+
+	assert.Nil(t, r.Guardar())
+	contenido, err := os.ReadFile(RUTA_REGISTROS + "DNS1/com")
+	assert.Nil(t, err)
+	assert.Equal(t, "google.com IN A 8.8.8.8\nyahoo.com IN A 2.2.2.2", string(contenido))
+
+	// Al compactar se descarta el log y el registro se recupera desde la instantánea
+	r.Crear("bing", "com", direccion("1.1.1.1"))
+	assert.Nil(t, r.Compactar("com"))
+	assert.NotNil(t, r.Compactar("org"))
+	contenido, err = os.ReadFile(RUTA_LOGS + "DNS1/com.log")
+	assert.Nil(t, err)
+	assert.Equal(t, "", string(contenido))
+	recargado, err := NuevoRegistros("DNS1", nodosPrueba, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, recargado.Cargar())
+	ip, reloj, err := obtenerIP(recargado, "bing", "com")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.1.1.1", ip)
+	assert.Equal(t, []int32{5,0,0}, reloj)
+}
+
+func TestCambioNoRegistrado(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS1")
+	_, err := r.Crear("google", "com", direccion("8.8.8.8"))
+	assert.Nil(t, err)
+	logAnterior, err := os.ReadFile(RUTA_LOGS + "DNS1/com.log")
+	assert.Nil(t, err)
+
+	// Si no se puede escribir el reloj el cambio se descarta del log y de memoria
+	assert.Nil(t, os.Remove(RUTA_LOGS + "DNS1/com.reloj"))
+	assert.Nil(t, os.MkdirAll(RUTA_LOGS + "DNS1/com.reloj/ocupado", 0755))
+	_, err = r.Crear("yahoo", "com", direccion("1.1.1.1"))
+	assert.NotNil(t, err)
+	contenido, err := os.ReadFile(RUTA_LOGS + "DNS1/com.log")
+	assert.Nil(t, err)
+	assert.Equal(t, string(logAnterior), string(contenido))
+	_, _, err = r.Obtener("yahoo", "com", recursos.A)
+	assert.NotNil(t, err)
+	reloj, err := r.Reloj("com")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{1,0,0}, reloj)
+
+	// El siguiente cambio continúa desde el reloj anterior
+	assert.Nil(t, os.RemoveAll(RUTA_LOGS + "DNS1/com.reloj"))
+	reloj, err = r.Crear("yahoo", "com", direccion("1.1.1.1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{2,0,0}, reloj)
+	recargado, err := NuevoRegistros("DNS1", nodosPrueba, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, recargado.Cargar())
+	reloj, err = recargado.Reloj("com")
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{2,0,0}, reloj)
+}
+
+func TestVariosRegistros(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS1")
+
+	// Un nombre puede tener varias direcciones y registros de distintos tipos, pero no repetidos
+	for _, ip := range []string{"1.1.1.1", "1.1.1.2", "2001:db8::1"} {
+		_, err := r.Crear("www", "example.com", direccion(ip))
+		assert.Nil(t, err)
+	}
+	_, err := r.Crear("www", "example.com", direccion("1.1.1.1"))
+	assert.NotNil(t, err)
+
+	mx, _ := recursos.ParsearDatos(recursos.MX, "10 mail.example.com.")
+	mx.TTL = 300
+	_, err = r.Crear("@", "example.com", mx)
+	assert.Nil(t, err)
+	txt, _ := recursos.ParsearDatos(recursos.TXT, "\"v=spf1 mx -all\"")
+	_, err = r.Crear("@", "example.com", txt)
+	assert.Nil(t, err)
+
+	// Un CNAME no puede coexistir con otros registros y se entrega al consultar cualquier tipo
+	cname := &recursos.Recurso{Tipo: recursos.CNAME, Valor: "example.com"}
+	_, err = r.Crear("www", "example.com", cname)
+	assert.NotNil(t, err)
+	_, err = r.Crear("ftp", "example.com", cname)
+	assert.Nil(t, err)
+	conjunto, _, err := r.Obtener("ftp", "example.com", recursos.A)
+	assert.Nil(t, err)
+	assert.Equal(t, recursos.CNAME, conjunto[0].Tipo)
+
+	conjunto, _, err = r.Obtener("www", "example.com", recursos.A)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(conjunto))
+	conjunto, _, err = r.Obtener("www", "example.com", recursos.MX)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(conjunto))
+
+	// update ip reemplaza solo las direcciones del mismo tipo, delete puede indicar tipo y datos
+	_, err = r.Actualizar("www", "example.com", "ip", "3.3.3.3")
+	assert.Nil(t, err)
+	_, err = r.Eliminar("www", "example.com", recursos.AAAA, "2001:db8::2")
+	assert.NotNil(t, err)
+	_, err = r.Eliminar("www", "example.com", recursos.AAAA, "2001:db8::1")
+	assert.Nil(t, err)
+
+	assert.Nil(t, r.Guardar())
+	contenido, err := os.ReadFile(RUTA_REGISTROS + "DNS1/example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com 300 IN MX 10 mail.example.com\n" +
+		"example.com IN TXT \"v=spf1 mx -all\"\n" +
+		"ftp.example.com IN CNAME example.com\n" +
+		"www.example.com IN A 3.3.3.3", string(contenido))
+
+	// Al recargar desde el log de cambios se recupera el mismo registro
+	recargado, err := NuevoRegistros("DNS1", nodosPrueba, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, recargado.Cargar())
+	estado, err := recargado.Estado("example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, string(contenido), strings.Join(estado.Registro, "\n"))
+}
+
+// Ejecutar con go test -race: escrituras concurrentes sobre un mismo dominio junto a
+// lecturas, copias de estado y reemplazos de otro dominio.
+func TestConcurrencia(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS3")
+	const escritores = 8
+	const nombresPorEscritor = 25
+
+	var wg sync.WaitGroup
+	for e := 0; e < escritores; e++ {
+		wg.Add(1)
+		go func(e int) {
+			defer wg.Done()
+			for n := 0; n < nombresPorEscritor; n++ {
+				nombre := "n" + strconv.Itoa(e) + "x" + strconv.Itoa(n)
+				_, err := r.Crear(nombre, "com", direccion("10.0." + strconv.Itoa(e) + "." + strconv.Itoa(n)))
+				assert.Nil(t, err)
+				if n % 5 == 0 {
+					_, err = r.Actualizar(nombre, "com", "ip", "10.1." + strconv.Itoa(e) + "." + strconv.Itoa(n))
+					assert.Nil(t, err)
+				}
+			}
+		}(e)
+	}
+
+	// Lectores en paralelo a los escritores
+	for l := 0; l < 4; l++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				r.Obtener("n0x0", "com", recursos.A)
+				r.Dominios()
+				if estado, err := r.Estado("com"); err == nil {
+					assert.True(t, len(estado.Registro) <= escritores * nombresPorEscritor)
+				}
+			}
+		}()
+	}
+
+	// Reemplazos completos de otro dominio, como los realiza SetFile
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			estado := &Estado{Reloj: []int32{int32(i), 0, 0}, Registro: []string{"a.cl IN A 1.1.1." + strconv.Itoa(i)}, Log: []string{}}
+			assert.Nil(t, r.Aplicar("cl", estado))
+			r.Obtener("a", "cl", recursos.A)
+		}
+	}()
+	wg.Wait()
+
+	// Cada cambio avanzó el reloj exactamente una vez y todos los nombres son legibles
+	reloj, err := r.Reloj("com")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(escritores * nombresPorEscritor + escritores * nombresPorEscritor / 5), reloj[2])
+	for e := 0; e < escritores; e++ {
+		ip, _, err := obtenerIP(r, "n" + strconv.Itoa(e) + "x1", "com")
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0." + strconv.Itoa(e) + ".1", ip)
+	}
+	ip, _, err := obtenerIP(r, "a", "cl")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.1.1.49", ip)
+
+	estado, err := r.Estado("com")
+	assert.Nil(t, err)
+	assert.Equal(t, escritores * nombresPorEscritor, len(estado.Registro))
+	assert.Equal(t, escritores * nombresPorEscritor + escritores * nombresPorEscritor / 5, len(estado.Log))
+}
+
+func TestValidacion(t *testing.T) {
+	r := nuevoRegistrosPrueba(t, "DNS1")
+	for _, rr := range []*recursos.Recurso{
+		direccion("8.8.8"),
+		direccion("8.8.8.8 IN A 1.1.1.1"),
+		{Tipo: recursos.AAAA, Valor: "8.8.8.8"},
+		{Tipo: recursos.CNAME, Valor: "no valido.com"},
+		{Tipo: recursos.A, Valor: "8.8.8.8", TTL: 1 << 31},
+	} {
+		_, err := r.Crear("google", "com", rr)
+		assert.NotNil(t, err, rr.Valor)
+	}
+
+	r.Crear("google", "com", direccion("8.8.8.8"))
+	for _, update := range [][]string{{"ip", "8.8.8"}, {"ttl", "-1"}, {"name", "no/valido"}, {"alias", "x"}} {
+		_, err := r.Actualizar("google", "com", update[0], update[1])
+		assert.NotNil(t, err, update[1])
+	}
+	_, err := r.Eliminar("google", "com", "HINFO", "")
+	assert.NotNil(t, err)
+}
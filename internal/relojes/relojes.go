@@ -0,0 +1,105 @@
+package relojes
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Orden entre dos relojes de vector
+const (
+	IGUAL = iota
+	ANTERIOR
+	POSTERIOR
+	CONCURRENTE
+)
+
+// Reloj de vector de un dominio, con una posición por servidor DNS. Las posiciones faltantes se
+// consideran en 0, de modo que se pueden comparar relojes de distinto largo.
+type Reloj []int32
+
+func (r Reloj) valor(i int) int32 {
+	if i < len(r) {
+		return r[i]
+	}
+	return 0
+}
+
+// Compara el reloj con otro: IGUAL, ANTERIOR (ocurre antes), POSTERIOR o CONCURRENTE (incomparables)
+func (r Reloj) Comparar(otro Reloj) int {
+	menor, mayor := false, false
+	for i := 0; i < len(r) || i < len(otro); i++ {
+		if r.valor(i) < otro.valor(i) {
+			menor = true
+		} else if r.valor(i) > otro.valor(i) {
+			mayor = true
+		}
+	}
+	switch {
+	case menor && mayor:
+		return CONCURRENTE
+	case menor:
+		return ANTERIOR
+	case mayor:
+		return POSTERIOR
+	}
+	return IGUAL
+}
+
+// Indica si el reloj ocurre antes que otro (relación happens-before)
+func (r Reloj) OcurreAntes(otro Reloj) bool {
+	return r.Comparar(otro) == ANTERIOR
+}
+
+func (r Reloj) Concurrente(otro Reloj) bool {
+	return r.Comparar(otro) == CONCURRENTE
+}
+
+// Indica si el reloj incluye todos los eventos de otro, es decir si es igual o posterior
+func (r Reloj) Incluye(otro Reloj) bool {
+	orden := r.Comparar(otro)
+	return orden == IGUAL || orden == POSTERIOR
+}
+
+// Obtiene un nuevo reloj con el máximo de cada posición
+func (r Reloj) Fusionar(otro Reloj) Reloj {
+	largo := len(r)
+	if len(otro) > largo {
+		largo = len(otro)
+	}
+	fusion := make(Reloj, largo)
+	for i := range fusion {
+		fusion[i] = r.valor(i)
+		if otro.valor(i) > fusion[i] {
+			fusion[i] = otro.valor(i)
+		}
+	}
+	return fusion
+}
+
+// Obtiene una copia del reloj con al menos el largo indicado, completando con ceros. Nunca se
+// quitan posiciones, ya que un reloj de un clúster más grande puede tener eventos en ellas.
+func (r Reloj) Ajustar(largo int) Reloj {
+	return r.Fusionar(make(Reloj, largo))
+}
+
+func (r Reloj) EsCero() bool {
+	for _, v := range r {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Reloj) Copia() Reloj {
+	return append(Reloj{}, r...)
+}
+
+// Texto del reloj con sus posiciones separadas por comas, por ejemplo "1,0,2"
+func (r Reloj) String() string {
+	valores := make([]string, len(r))
+	for i, v := range r {
+		valores[i] = strconv.Itoa(int(v))
+	}
+	return strings.Join(valores, ",")
+}
@@ -0,0 +1,30 @@
+package relojes
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparar(t *testing.T) {
+	assert.Equal(t, IGUAL, Reloj{1,2,0}.Comparar(Reloj{1,2}))
+	assert.Equal(t, ANTERIOR, Reloj{1,1,0}.Comparar(Reloj{1,2,0}))
+	assert.Equal(t, POSTERIOR, Reloj{1,2,1}.Comparar(Reloj{1,2,0}))
+	assert.Equal(t, CONCURRENTE, Reloj{2,1,0}.Comparar(Reloj{1,2,0}))
+
+	assert.True(t, Reloj{1,1}.OcurreAntes(Reloj{1,2}))
+	assert.True(t, Reloj{2,1}.Concurrente(Reloj{1,2}))
+	assert.True(t, Reloj{1,2}.Incluye(Reloj{1,2,0}))
+	assert.True(t, Reloj{1,2}.Incluye(nil))
+	assert.False(t, Reloj{2,1}.Incluye(Reloj{1,2}))
+}
+
+func TestFusionar(t *testing.T) {
+	a := Reloj{2,1}
+	assert.Equal(t, Reloj{2,3,1}, a.Fusionar(Reloj{1,3,1}))
+	assert.Equal(t, Reloj{2,1}, a)
+	assert.Equal(t, Reloj{2,1,0}, a.Ajustar(3))
+	assert.Equal(t, Reloj{2,1}, a.Ajustar(1))
+	assert.True(t, Reloj(nil).EsCero())
+	assert.True(t, Reloj{0,0}.EsCero())
+	assert.Equal(t, "2,3,1", Reloj{2,3,1}.String())
+}
@@ -0,0 +1,170 @@
+package resolucion
+
+import (
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/miekg/dns"
+)
+
+// Códigos de respuesta utilizados por los resolutores
+const (
+	NOERROR = dns.RcodeSuccess
+	SERVFAIL = dns.RcodeServerFailure
+	NXDOMAIN = dns.RcodeNameError
+	REFUSED = dns.RcodeRefused
+)
+
+// Respuesta a una consulta DNS estándar (RFC 1035)
+type Respuesta struct{
+	Codigo int // código de respuesta, por ejemplo dns.RcodeSuccess o dns.RcodeNameError (NXDOMAIN)
+	Autoritativa bool // la respuesta proviene de un servidor autoritativo de la zona
+	Recursos []*recursos.Recurso
+}
+
+// Obtiene la respuesta a una consulta por un nombre (normalizado) y un tipo de registro.
+// Un tipo vacío corresponde a una consulta ANY.
+type Resolutor func(nombre string, tipo string) *Respuesta
+
+
+//// CONVERSIÓN DE REGISTROS
+
+// Genera el registro DNS correspondiente a un registro de la zona
+func ARR(r *recursos.Recurso) (dns.RR, error) {
+	cabecera := dns.RR_Header{Name: dns.Fqdn(r.Nombre), Rrtype: dns.StringToType[r.Tipo], Class: dns.ClassINET, Ttl: r.TTL}
+	switch r.Tipo {
+	case recursos.A:
+		ip := net.ParseIP(r.Valor).To4()
+		if ip == nil {
+			return nil, errors.New("Dirección IPv4 inválida: " + r.Valor)
+		}
+		return &dns.A{Hdr: cabecera, A: ip}, nil
+	case recursos.AAAA:
+		ip := net.ParseIP(r.Valor)
+		if ip == nil {
+			return nil, errors.New("Dirección IPv6 inválida: " + r.Valor)
+		}
+		return &dns.AAAA{Hdr: cabecera, AAAA: ip}, nil
+	case recursos.CNAME:
+		return &dns.CNAME{Hdr: cabecera, Target: dns.Fqdn(r.Valor)}, nil
+	case recursos.MX:
+		return &dns.MX{Hdr: cabecera, Preference: r.Prioridad, Mx: dns.Fqdn(r.Valor)}, nil
+	case recursos.TXT:
+		return &dns.TXT{Hdr: cabecera, Txt: []string{r.Valor}}, nil
+	case recursos.NS:
+		return &dns.NS{Hdr: cabecera, Ns: dns.Fqdn(r.Valor)}, nil
+	case recursos.SRV:
+		return &dns.SRV{Hdr: cabecera, Priority: r.Prioridad, Weight: r.Peso, Port: r.Puerto, Target: dns.Fqdn(r.Valor)}, nil
+	case recursos.PTR:
+		return &dns.PTR{Hdr: cabecera, Ptr: dns.Fqdn(r.Valor)}, nil
+	}
+	return nil, errors.New("Tipo de registro no soportado: " + r.Tipo)
+}
+
+func sinPunto(nombre string) string {
+	return strings.TrimSuffix(strings.ToLower(nombre), ".")
+}
+
+// Obtiene el registro de la zona correspondiente a un registro DNS, nil si su tipo no está soportado
+func DesdeRR(rr dns.RR) *recursos.Recurso {
+	cabecera := rr.Header()
+	r := &recursos.Recurso{Nombre: sinPunto(cabecera.Name), TTL: cabecera.Ttl, Tipo: dns.TypeToString[cabecera.Rrtype]}
+	switch v := rr.(type) {
+	case *dns.A:
+		r.Valor = v.A.String()
+	case *dns.AAAA:
+		r.Valor = v.AAAA.String()
+	case *dns.CNAME:
+		r.Valor = sinPunto(v.Target)
+	case *dns.MX:
+		r.Prioridad = v.Preference
+		r.Valor = sinPunto(v.Mx)
+	case *dns.TXT:
+		r.Valor = strings.Join(v.Txt, "")
+	case *dns.NS:
+		r.Valor = sinPunto(v.Ns)
+	case *dns.SRV:
+		r.Prioridad = v.Priority
+		r.Peso = v.Weight
+		r.Puerto = v.Port
+		r.Valor = sinPunto(v.Target)
+	case *dns.PTR:
+		r.Valor = sinPunto(v.Ptr)
+	default:
+		return nil
+	}
+	return r
+}
+
+
+//// SERVIDOR
+
+// Genera el manejador de consultas DNS que responde con el resolutor indicado
+func Manejador(resolver Resolutor) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, consulta *dns.Msg) {
+		respuesta := new(dns.Msg)
+		respuesta.SetReply(consulta)
+
+		// Solo se atienden consultas estándar por un único nombre de la clase IN
+		if consulta.Opcode != dns.OpcodeQuery {
+			respuesta.SetRcode(consulta, dns.RcodeNotImplemented)
+			w.WriteMsg(respuesta)
+			return
+		}
+		if len(consulta.Question) != 1 {
+			respuesta.SetRcode(consulta, dns.RcodeFormatError)
+			w.WriteMsg(respuesta)
+			return
+		}
+		pregunta := consulta.Question[0]
+		if pregunta.Qclass != dns.ClassINET && pregunta.Qclass != dns.ClassANY {
+			respuesta.SetRcode(consulta, dns.RcodeRefused)
+			w.WriteMsg(respuesta)
+			return
+		}
+
+		tipo := dns.TypeToString[pregunta.Qtype]
+		if pregunta.Qtype == dns.TypeANY {
+			tipo = ""
+		}
+		resultado := resolver(sinPunto(pregunta.Name), tipo)
+		respuesta.Rcode = resultado.Codigo
+		respuesta.Authoritative = resultado.Autoritativa
+		for _, r := range resultado.Recursos {
+			rr, err := ARR(r)
+			if err != nil {
+				log.Printf("[ERROR] %s\n", err)
+				continue
+			}
+			respuesta.Answer = append(respuesta.Answer, rr)
+		}
+
+		// Por UDP la respuesta se trunca al tamaño anunciado por el cliente
+		if _, esUDP := w.RemoteAddr().(*net.UDPAddr); esUDP {
+			tamano := dns.MinMsgSize
+			if opt := consulta.IsEdns0(); opt != nil {
+				tamano = int(opt.UDPSize())
+			}
+			respuesta.Truncate(tamano)
+		}
+		if err := w.WriteMsg(respuesta); err != nil {
+			log.Printf("Error al responder la consulta DNS: %s\n", err)
+		}
+	}
+}
+
+// Atiende consultas DNS por UDP y TCP en el puerto indicado. Retorna cuando alguno de los
+// dos servidores falla.
+func Iniciar(port string, manejador dns.Handler) error {
+	errores := make(chan error, 2)
+	for _, red := range []string{"udp", "tcp"} {
+		servidor := &dns.Server{Addr: ":" + port, Net: red, Handler: manejador}
+		go func() {
+			errores <- servidor.ListenAndServe()
+		}()
+	}
+	return <-errores
+}
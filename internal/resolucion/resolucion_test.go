@@ -0,0 +1,62 @@
+package resolucion
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/recursos"
+	"github.com/miekg/dns"
+)
+
+func resolverPrueba(nombre string, tipo string) *Respuesta {
+	if !strings.HasSuffix(nombre, "example.com") {
+		return &Respuesta{Codigo: REFUSED}
+	}
+	if nombre != "www.example.com" {
+		return &Respuesta{Codigo: NXDOMAIN, Autoritativa: true}
+	}
+	respuesta := &Respuesta{Codigo: NOERROR, Autoritativa: true}
+	if tipo == recursos.A || tipo == "" {
+		respuesta.Recursos = []*recursos.Recurso{{Nombre: nombre, TTL: 300, Tipo: recursos.A, Valor: "1.1.1.1"}}
+	}
+	return respuesta
+}
+
+func TestManejador(t *testing.T) {
+	conexion, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	servidor := &dns.Server{PacketConn: conexion, Handler: Manejador(resolverPrueba)}
+	go servidor.ActivateAndServe()
+	defer servidor.Shutdown()
+
+	consultar := func(nombre string, tipo uint16) *dns.Msg {
+		consulta := new(dns.Msg)
+		consulta.SetQuestion(nombre, tipo)
+		respuesta, err := dns.Exchange(consulta, conexion.LocalAddr().String())
+		assert.Nil(t, err)
+		return respuesta
+	}
+
+	respuesta := consultar("WWW.example.com.", dns.TypeA)
+	assert.Equal(t, dns.RcodeSuccess, respuesta.Rcode)
+	assert.True(t, respuesta.Authoritative)
+	assert.Equal(t, 1, len(respuesta.Answer))
+	assert.Equal(t, "1.1.1.1", respuesta.Answer[0].(*dns.A).A.String())
+
+	respuesta = consultar("www.example.com.", dns.TypeAAAA)
+	assert.Equal(t, dns.RcodeSuccess, respuesta.Rcode)
+	assert.Equal(t, 0, len(respuesta.Answer))
+
+	assert.Equal(t, dns.RcodeNameError, consultar("ftp.example.com.", dns.TypeA).Rcode)
+	assert.Equal(t, dns.RcodeRefused, consultar("google.com.", dns.TypeA).Rcode)
+}
+
+func TestConversion(t *testing.T) {
+	for _, linea := range []string{"example.com 300 IN MX 10 mail.example.com", "_sip._tcp.example.com IN SRV 1 2 5060 sip.example.com", "example.com IN TXT \"a b\""} {
+		r, _ := recursos.ParsearLinea(linea)
+		rr, err := ARR(r)
+		assert.Nil(t, err)
+		assert.Equal(t, linea, DesdeRR(rr).Linea())
+	}
+}
@@ -0,0 +1,294 @@
+package ronda
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jfomu/DNSDistribuido/internal/archivos"
+	"github.com/jfomu/DNSDistribuido/internal/registros"
+)
+
+// Fases que el coordinador envía a los participantes
+const (
+	CONGELAR = "CONGELAR"
+	PREPARAR = "PREPARAR"
+	CONFIRMAR = "CONFIRMAR"
+	ABORTAR = "ABORTAR"
+	CONSULTAR = "CONSULTAR"
+)
+
+// Estados de una ronda en un participante
+const (
+	CONGELADA = "CONGELADA" // las escrituras esperan, el coordinador reúne y envía el estado fusionado
+	PREPARADA = "PREPARADA" // se recibió todo el estado fusionado, solo el coordinador decide el resultado
+	CONFIRMADA = "CONFIRMADA" // en curso: se registró la decisión de confirmarla pero aún no se aplica
+	ABORTADA = "ABORTADA"
+	DESCONOCIDA = "DESCONOCIDA"
+)
+
+const RONDAS_RECORDADAS = 32 // resultados de rondas terminadas que se recuerdan para CONSULTAR
+
+// Ronda de consistencia en curso en un participante
+type Ronda struct{
+	Id string
+	Coordinador string
+	Participantes []string // IDs de los nodos de la ronda, recibidos al prepararla, para consultar su resultado si el coordinador cae
+	Estado string
+	Limite time.Time // pasado este momento la ronda se considera vencida y el participante la resuelve
+	preparados map[string]*registros.Estado
+}
+
+// Participante de las rondas de consistencia con commit en dos fases. Mientras una ronda está en
+// curso las escrituras esperan, de modo que el estado fusionado que se confirma incluye todos los
+// cambios del nodo. El estado fusionado de cada dominio se recibe antes de preparar la ronda y solo
+// se aplica, para todos los dominios a la vez, al confirmarse. La decisión de confirmar una ronda
+// se registra en disco antes de aplicarla, por lo que se recuerda aunque el nodo se reinicie.
+type Participante struct{
+	mu sync.Mutex // protege los campos siguientes
+	drenadas *sync.Cond // señala el término de una escritura en curso
+	actual *Ronda
+	liberada chan struct{} // se cierra al terminar la ronda en curso
+	escrituras int // escrituras en curso
+	resultados map[string]string
+	terminadas []string // IDs de las rondas terminadas, de la más antigua a la más reciente
+	confirmadas []string // IDs de las últimas rondas confirmadas, registradas en disco
+	ruta string // archivo de las rondas confirmadas, vacío si no se registran en disco
+	duracion time.Duration
+	ahora func() time.Time
+}
+
+func NuevoParticipante(duracion time.Duration) *Participante {
+	p := &Participante{resultados: make(map[string]string), duracion: duracion, ahora: time.Now}
+	p.drenadas = sync.NewCond(&p.mu)
+	return p
+}
+
+// Recupera las rondas confirmadas registradas en el archivo indicado, en el que se registran las
+// siguientes
+func (p *Participante) Cargar(ruta string) error {
+	if err := archivos.CrearDirectorio(filepath.Dir(ruta)); err != nil {
+		return err
+	}
+	contenido, err := os.ReadFile(ruta)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ruta = ruta
+	for _, id := range registros.DividirLineas(string(contenido)) {
+		if _, ok := p.resultados[id]; !ok && id != "" {
+			p.resultados[id] = CONFIRMADA
+			p.terminadas = append(p.terminadas, id)
+			p.confirmadas = append(p.confirmadas, id)
+		}
+	}
+	return nil
+}
+
+// Inicia una escritura, esperando a que termine la ronda en curso. La función retornada marca su término.
+func (p *Participante) IniciarEscritura(ctx context.Context) (func(), error) {
+	for {
+		p.mu.Lock()
+		if p.actual == nil {
+			p.escrituras += 1
+			p.mu.Unlock()
+			return p.terminarEscritura, nil
+		}
+		liberada := p.liberada
+		p.mu.Unlock()
+
+		select {
+		case <-liberada:
+		case <-ctx.Done():
+			return nil, errors.New("Ronda de consistencia en curso, la escritura no se realizó")
+		}
+	}
+}
+
+func (p *Participante) terminarEscritura() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.escrituras -= 1
+	p.drenadas.Broadcast()
+}
+
+// Inicia una ronda, congelando las escrituras y esperando a que terminen las que están en curso.
+// Falla si hay otra ronda en curso, que debe resolverse antes.
+func (p *Participante) Congelar(id string, coordinador string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.actual != nil {
+		if p.actual.Id == id {
+			return nil
+		}
+		return errors.New("La ronda " + p.actual.Id + " de " + p.actual.Coordinador + " aún está en curso")
+	}
+	if _, ok := p.resultados[id]; ok {
+		return errors.New("La ronda " + id + " ya terminó")
+	}
+
+	p.actual = &Ronda{
+		Id: id, Coordinador: coordinador,
+		Estado: CONGELADA, Limite: p.ahora().Add(p.duracion), preparados: make(map[string]*registros.Estado),
+	}
+	p.liberada = make(chan struct{})
+	for p.escrituras > 0 {
+		p.drenadas.Wait()
+	}
+	return nil
+}
+
+// Debe llamarse manteniendo el mutex
+func (p *Participante) enCurso(id string, estados ...string) error {
+	if p.actual == nil || p.actual.Id != id {
+		return errors.New("La ronda " + id + " no está en curso")
+	}
+	for _, estado := range estados {
+		if p.actual.Estado == estado {
+			return nil
+		}
+	}
+	return errors.New("La ronda " + id + " está " + p.actual.Estado)
+}
+
+// Recibe el estado fusionado de un dominio, que se guarda hasta confirmar la ronda
+func (p *Participante) Recibir(id string, dominio string, estado *registros.Estado) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.enCurso(id, CONGELADA); err != nil {
+		return err
+	}
+	p.actual.preparados[dominio] = estado
+	return nil
+}
+
+// Prepara la ronda una vez recibido todo el estado fusionado. Desde entonces el participante ya no
+// puede abortarla por su cuenta, ya que el coordinador podría haber decidido confirmarla: si el
+// coordinador no la termina, debe consultar su resultado.
+func (p *Participante) Preparar(id string, participantes []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.enCurso(id, CONGELADA, PREPARADA); err != nil {
+		return err
+	}
+	p.actual.Participantes = participantes
+	p.actual.Estado = PREPARADA
+	return nil
+}
+
+// Registra en disco la decisión de confirmar una ronda preparada, antes de aplicarla. Desde
+// entonces la ronda ya no puede abortarse.
+func (p *Participante) Decidir(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.enCurso(id, PREPARADA, CONFIRMADA); err != nil {
+		return err
+	}
+	return p.decidir()
+}
+
+// Debe llamarse manteniendo el mutex
+func (p *Participante) decidir() error {
+	if p.actual.Estado == CONFIRMADA {
+		return nil
+	}
+	confirmadas := append(append([]string{}, p.confirmadas...), p.actual.Id)
+	if len(confirmadas) > RONDAS_RECORDADAS {
+		confirmadas = confirmadas[len(confirmadas) - RONDAS_RECORDADAS:]
+	}
+	if p.ruta != "" {
+		if err := archivos.EscribirArchivoAtomico(p.ruta, []byte(strings.Join(confirmadas, "\n"))); err != nil {
+			return err
+		}
+	}
+	p.confirmadas = confirmadas
+	p.actual.Estado = CONFIRMADA
+	return nil
+}
+
+// Confirma la ronda: registra la decisión si aún no lo estaba y aplica los estados recibidos con
+// la función indicada antes de liberar las escrituras, de modo que ninguna escritura se pierda al
+// reemplazar los registros. Si la función falla la ronda sigue en curso, ya confirmada, para
+// reintentarla. Confirmar una ronda ya confirmada y aplicada no tiene efecto.
+func (p *Participante) Confirmar(id string, aplicar func(map[string]*registros.Estado) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resultados[id] == CONFIRMADA {
+		return nil
+	}
+	if err := p.enCurso(id, PREPARADA, CONFIRMADA); err != nil {
+		return err
+	}
+	if err := p.decidir(); err != nil {
+		return err
+	}
+	if err := aplicar(p.actual.preparados); err != nil {
+		return err
+	}
+	p.terminar(CONFIRMADA)
+	return nil
+}
+
+// Aborta la ronda descartando los estados recibidos. Falla si ya se decidió confirmarla.
+// Abortar una ronda que no está en curso no tiene efecto.
+func (p *Participante) Abortar(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.actual == nil || p.actual.Id != id {
+		return nil
+	}
+	if p.actual.Estado == CONFIRMADA {
+		return errors.New("La ronda " + id + " ya fue confirmada")
+	}
+	p.terminar(ABORTADA)
+	return nil
+}
+
+// Debe llamarse manteniendo el mutex
+func (p *Participante) terminar(resultado string) {
+	p.resultados[p.actual.Id] = resultado
+	p.terminadas = append(p.terminadas, p.actual.Id)
+	if len(p.terminadas) > RONDAS_RECORDADAS {
+		delete(p.resultados, p.terminadas[0])
+		p.terminadas = p.terminadas[1:]
+	}
+	p.actual = nil
+	close(p.liberada)
+}
+
+// Estado de una ronda en este participante
+func (p *Participante) Estado(id string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.actual != nil && p.actual.Id == id {
+		return p.actual.Estado
+	}
+	if resultado, ok := p.resultados[id]; ok {
+		return resultado
+	}
+	return DESCONOCIDA
+}
+
+// Copia de la ronda en curso, nil si no hay una
+func (p *Participante) EnCurso() *Ronda {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.actual == nil {
+		return nil
+	}
+	ronda := *p.actual
+	ronda.preparados = nil
+	return &ronda
+}
+
+// Indica si la ronda ya debió terminar, por ejemplo porque su coordinador cayó
+func (r *Ronda) Vencida(ahora time.Time) bool {
+	return !ahora.Before(r.Limite)
+}
@@ -0,0 +1,100 @@
+package ronda
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/registros"
+)
+
+func TestParticipante(t *testing.T) {
+	p := NuevoParticipante(30 * time.Second)
+
+	// Congelar espera a que termine la escritura en curso
+	terminar, err := p.IniciarEscritura(context.Background())
+	assert.Nil(t, err)
+	congelada := make(chan bool)
+	go func() {
+		assert.Nil(t, p.Congelar("R1", "DNS1"))
+		congelada <- true
+	}()
+	select {
+	case <-congelada:
+		t.Fatal("La ronda se congeló con una escritura en curso")
+	case <-time.After(50 * time.Millisecond):
+	}
+	terminar()
+	<-congelada
+	assert.Equal(t, CONGELADA, p.Estado("R1"))
+	assert.NotNil(t, p.Congelar("R2", "DNS2"))
+
+	// Las escrituras esperan hasta que la ronda termina
+	ctx, cancel := context.WithTimeout(context.Background(), 50 * time.Millisecond)
+	_, err = p.IniciarEscritura(ctx)
+	cancel()
+	assert.NotNil(t, err)
+	liberada := make(chan bool)
+	go func() {
+		terminar, err := p.IniciarEscritura(context.Background())
+		assert.Nil(t, err)
+		terminar()
+		liberada <- true
+	}()
+
+	// Los estados recibidos se aplican al confirmar, antes de liberar las escrituras
+	assert.NotNil(t, p.Recibir("R2", "cl", &registros.Estado{}))
+	assert.Nil(t, p.Recibir("R1", "cl", &registros.Estado{Reloj: []int32{1,1}}))
+	assert.Equal(t, CONGELADA, p.Estado("R1"))
+	assert.NotNil(t, p.Confirmar("R1", nil)) // Solo se confirma una ronda preparada
+	assert.Nil(t, p.Preparar("R1", []string{"DNS1", "DNS2"}))
+	assert.Equal(t, PREPARADA, p.Estado("R1"))
+	assert.NotNil(t, p.Recibir("R1", "com", &registros.Estado{}))
+	assert.Equal(t, "DNS1", p.EnCurso().Coordinador)
+	assert.Equal(t, []string{"DNS1", "DNS2"}, p.EnCurso().Participantes)
+	assert.False(t, p.EnCurso().Vencida(time.Now()))
+	var aplicados map[string]*registros.Estado
+	assert.Nil(t, p.Confirmar("R1", func(estados map[string]*registros.Estado) error {
+		aplicados = estados
+		return nil
+	}))
+	<-liberada
+	assert.Equal(t, []int32{1,1}, aplicados["cl"].Reloj)
+	assert.Equal(t, CONFIRMADA, p.Estado("R1"))
+	assert.Nil(t, p.Confirmar("R1", nil)) // Confirmar de nuevo no tiene efecto
+	assert.Nil(t, p.EnCurso())
+
+	// Una ronda abortada descarta sus estados y no puede iniciarse de nuevo
+	assert.Nil(t, p.Congelar("R2", "DNS2"))
+	assert.Nil(t, p.Abortar("R2"))
+	assert.Equal(t, ABORTADA, p.Estado("R2"))
+	assert.NotNil(t, p.Congelar("R2", "DNS2"))
+	assert.NotNil(t, p.Confirmar("R2", nil))
+	assert.Equal(t, DESCONOCIDA, p.Estado("R3"))
+}
+
+func TestDecision(t *testing.T) {
+	ruta := t.TempDir() + "/rondas/DNS1"
+	p := NuevoParticipante(30 * time.Second)
+	assert.Nil(t, p.Cargar(ruta))
+
+	// Una ronda decidida ya no se aborta, aunque aún no se aplique
+	assert.Nil(t, p.Congelar("R1", "DNS1"))
+	assert.NotNil(t, p.Decidir("R1"))
+	assert.Nil(t, p.Preparar("R1", []string{"DNS1", "DNS2"}))
+	assert.Nil(t, p.Decidir("R1"))
+	assert.Equal(t, CONFIRMADA, p.Estado("R1"))
+	assert.NotNil(t, p.Abortar("R1"))
+	assert.NotNil(t, p.Confirmar("R1", func(map[string]*registros.Estado) error { return errors.New("Falla") }))
+	assert.Equal(t, CONFIRMADA, p.EnCurso().Estado)
+
+	// La decisión se recuerda al reiniciar el nodo
+	reiniciado := NuevoParticipante(30 * time.Second)
+	assert.Nil(t, reiniciado.Cargar(ruta))
+	assert.Equal(t, CONFIRMADA, reiniciado.Estado("R1"))
+	assert.NotNil(t, reiniciado.Congelar("R1", "DNS1"))
+
+	assert.Nil(t, p.Confirmar("R1", func(map[string]*registros.Estado) error { return nil }))
+	assert.Nil(t, p.EnCurso())
+}
@@ -0,0 +1,294 @@
+package seleccion
+
+import (
+	"errors"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ALEATORIA = "aleatoria"
+	ROUND_ROBIN = "round-robin"
+	MENOS_PENDIENTES = "menos-pendientes"
+	HASH_CONSISTENTE = "hash-consistente"
+
+	UMBRAL_FALLOS = 3 // fallos consecutivos para considerar inactivo un nodo
+	NODOS_VIRTUALES = 64 // puntos de cada nodo en el anillo de hash consistente
+
+	RAZON_NODO_FIJADO = "NODO_FIJADO_NO_DISPONIBLE" // razón del error cuando no responde el servidor fijado por el cliente
+)
+
+// Servidor DNS que puede ser elegido por el broker
+type Nodo struct{
+	Id string
+	Ip string
+	Port string
+	pendientes int64 // solicitudes en curso, se modifica de forma atómica
+	mu sync.Mutex // protege los campos siguientes
+	activo bool
+	fallos int // fallos consecutivos
+}
+
+func (n *Nodo) Activo() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.activo
+}
+
+func (n *Nodo) Pendientes() int64 {
+	return atomic.LoadInt64(&n.pendientes)
+}
+
+// Registra el resultado de una solicitud o verificación. Retorna true si el estado del nodo cambió.
+func (n *Nodo) registrar(exito bool) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	anterior := n.activo
+	if exito {
+		n.fallos = 0
+		n.activo = true
+	} else {
+		n.fallos += 1
+		if n.fallos >= UMBRAL_FALLOS {
+			n.activo = false
+		}
+	}
+	return anterior != n.activo
+}
+
+
+//// ESTRATEGIAS
+
+// Elige un nodo entre los candidatos (todos activos, en el orden de la configuración). La clave
+// identifica la consulta, por ejemplo su dominio, y solo es utilizada por algunas estrategias.
+type Estrategia interface {
+	Elegir(candidatos []*Nodo, clave string) *Nodo
+}
+
+type aleatoria struct{}
+
+func (aleatoria) Elegir(candidatos []*Nodo, clave string) *Nodo {
+	return candidatos[rand.Intn(len(candidatos))]
+}
+
+type roundRobin struct{
+	siguiente uint64
+}
+
+func (e *roundRobin) Elegir(candidatos []*Nodo, clave string) *Nodo {
+	return candidatos[(atomic.AddUint64(&e.siguiente, 1) - 1) % uint64(len(candidatos))]
+}
+
+// Elige el nodo con menos solicitudes en curso, desempatando de forma aleatoria
+type menosPendientes struct{}
+
+func (menosPendientes) Elegir(candidatos []*Nodo, clave string) *Nodo {
+	var elegidos []*Nodo
+	for _, n := range candidatos {
+		if len(elegidos) == 0 || n.Pendientes() < elegidos[0].Pendientes() {
+			elegidos = []*Nodo{n}
+		} else if n.Pendientes() == elegidos[0].Pendientes() {
+			elegidos = append(elegidos, n)
+		}
+	}
+	return elegidos[rand.Intn(len(elegidos))]
+}
+
+// Asigna cada clave al primer nodo activo que la sigue en un anillo de hash, de modo que las
+// consultas de un mismo dominio lleguen al mismo nodo y solo se reasignen las claves del nodo que falla
+type hashConsistente struct{}
+
+func hash(texto string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(texto))
+	return h.Sum32()
+}
+
+func (hashConsistente) Elegir(candidatos []*Nodo, clave string) *Nodo {
+	type punto struct{
+		valor uint32
+		nodo *Nodo
+	}
+	anillo := make([]punto, 0, len(candidatos) * NODOS_VIRTUALES)
+	for _, n := range candidatos {
+		for i := 0; i < NODOS_VIRTUALES; i++ {
+			anillo = append(anillo, punto{hash(n.Id + "#" + strconv.Itoa(i)), n})
+		}
+	}
+	sort.Slice(anillo, func(i, j int) bool { return anillo[i].valor < anillo[j].valor })
+
+	valor := hash(clave)
+	i := sort.Search(len(anillo), func(i int) bool { return anillo[i].valor >= valor })
+	return anillo[i % len(anillo)].nodo
+}
+
+// Obtiene la estrategia correspondiente a su nombre en la configuración, por defecto la aleatoria
+func NuevaEstrategia(nombre string) (Estrategia, error) {
+	switch nombre {
+	case "", ALEATORIA:
+		return aleatoria{}, nil
+	case ROUND_ROBIN:
+		return new(roundRobin), nil
+	case MENOS_PENDIENTES:
+		return menosPendientes{}, nil
+	case HASH_CONSISTENTE:
+		return hashConsistente{}, nil
+	}
+	return nil, errors.New("Estrategia de selección desconocida: " + nombre)
+}
+
+
+//// SELECTOR
+
+// Selecciona servidores DNS considerando su estado: los nodos se marcan inactivos tras varios
+// fallos consecutivos, ya sea en las verificaciones periódicas (detección activa) o en las
+// solicitudes reenviadas (detección pasiva), y vuelven a estar activos al responder.
+type Selector struct{
+	mu sync.RWMutex // protege la lista de nodos
+	nodos []*Nodo
+	estrategia Estrategia
+}
+
+func NuevoSelector(nodos []config.NodeInfo, estrategia Estrategia) *Selector {
+	s := &Selector{estrategia: estrategia}
+	s.Actualizar(nodos)
+	return s
+}
+
+// Reemplaza los nodos a elegir por los de la configuración indicada. Los nodos que se mantienen
+// conservan su estado y sus solicitudes en curso.
+func (s *Selector) Actualizar(nodos []config.NodeInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var actualizados []*Nodo
+	for _, info := range nodos {
+		n, ok := s.buscar(info.Ip, info.Port)
+		if !ok || n.Id != info.Id {
+			n = &Nodo{Id: info.Id, Ip: info.Ip, Port: info.Port, activo: true}
+		}
+		actualizados = append(actualizados, n)
+	}
+	s.nodos = actualizados
+}
+
+func (s *Selector) Nodos() []*Nodo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*Nodo{}, s.nodos...)
+}
+
+// Busca un nodo por su dirección
+func (s *Selector) Buscar(ip string, port string) (*Nodo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.buscar(ip, port)
+}
+
+func (s *Selector) buscar(ip string, port string) (*Nodo, bool) {
+	for _, n := range s.nodos {
+		if n.Ip == ip && n.Port == port {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Elige un nodo activo, excluyendo los indicados (por ejemplo los que ya fallaron en un reintento)
+func (s *Selector) Elegir(clave string, excluidos ...*Nodo) (*Nodo, error) {
+	var candidatos []*Nodo
+	for _, n := range s.Nodos() {
+		excluido := false
+		for _, e := range excluidos {
+			excluido = excluido || e == n
+		}
+		if !excluido && n.Activo() {
+			candidatos = append(candidatos, n)
+		}
+	}
+	if len(candidatos) == 0 {
+		return nil, errors.New("No hay servidores DNS disponibles")
+	}
+	return s.estrategia.Elegir(candidatos, clave), nil
+}
+
+// Marca el comienzo de una solicitud a un nodo
+func (s *Selector) Iniciar(n *Nodo) {
+	atomic.AddInt64(&n.pendientes, 1)
+}
+
+// Marca el término de una solicitud a un nodo, registrando si el nodo falló
+func (s *Selector) Terminar(n *Nodo, err error) {
+	atomic.AddInt64(&n.pendientes, -1)
+	s.Registrar(n, !EsFallaDeNodo(err))
+}
+
+func (s *Selector) Registrar(n *Nodo, exito bool) {
+	if n.registrar(exito) {
+		if exito {
+			log.Printf("Servidor DNS %s (%s:%s) ACTIVO\n", n.Id, n.Ip, n.Port)
+		} else {
+			log.Printf("Servidor DNS %s (%s:%s) INACTIVO\n", n.Id, n.Ip, n.Port)
+		}
+	}
+}
+
+// Verifica periódicamente el estado de todos los nodos con la función indicada
+func (s *Selector) IniciarVerificaciones(intervalo time.Duration, verificar func(*Nodo) error) {
+	for range time.Tick(intervalo) {
+		var wg sync.WaitGroup
+		for _, n := range s.Nodos() {
+			wg.Add(1)
+			go func(n *Nodo) {
+				defer wg.Done()
+				s.Registrar(n, verificar(n) == nil)
+			}(n)
+		}
+		wg.Wait()
+	}
+}
+
+// Indica si un error gRPC se debe a que el nodo no está disponible, a diferencia de los errores
+// propios de la consulta (por ejemplo un nombre inexistente). Los errores sin código gRPC, que el
+// servidor retorna con errors.New y llegan como Unknown, son errores de la consulta.
+func EsFallaDeNodo(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// Genera el error que se retorna cuando no está disponible el servidor fijado por el cliente en la
+// consulta. El broker no reintenta en otra réplica, ya que esta podría tener una versión más antigua.
+func ErrorNodoFijado(ip string, port string, err error) error {
+	estado := status.New(codes.Unavailable, "El servidor DNS " + ip + ":" + port + " indicado en la consulta no está disponible: " + status.Convert(err).Message())
+	detalle := &errdetails.ErrorInfo{Reason: RAZON_NODO_FIJADO, Metadata: map[string]string{"ip": ip, "port": port}}
+	if conDetalle, err := estado.WithDetails(detalle); err == nil {
+		estado = conDetalle
+	}
+	return estado.Err()
+}
+
+// Indica si un error recibido desde el broker corresponde a ErrorNodoFijado
+func EsNodoFijadoNoDisponible(err error) bool {
+	for _, detalle := range status.Convert(err).Details() {
+		if info, ok := detalle.(*errdetails.ErrorInfo); ok && info.Reason == RAZON_NODO_FIJADO {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package seleccion
+
+import (
+	"errors"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/jfomu/DNSDistribuido/internal/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func nuevoSelectorPrueba(t *testing.T, estrategia string) *Selector {
+	e, err := NuevaEstrategia(estrategia)
+	assert.Nil(t, err)
+	return NuevoSelector([]config.NodeInfo{
+		{Id: "DNS1", Ip: "127.0.0.1", Port: "9001"},
+		{Id: "DNS2", Ip: "127.0.0.1", Port: "9002"},
+		{Id: "DNS3", Ip: "127.0.0.1", Port: "9003"},
+		{Id: "DNS4", Ip: "127.0.0.1", Port: "9004"},
+	}, e)
+}
+
+func elegir(t *testing.T, s *Selector, clave string) string {
+	n, err := s.Elegir(clave)
+	assert.Nil(t, err)
+	return n.Id
+}
+
+func TestEstrategias(t *testing.T) {
+	_, err := NuevaEstrategia("primero")
+	assert.NotNil(t, err)
+
+	// Round-robin recorre todos los nodos configurados, no solo los tres primeros
+	s := nuevoSelectorPrueba(t, ROUND_ROBIN)
+	for _, id := range []string{"DNS1", "DNS2", "DNS3", "DNS4", "DNS1"} {
+		assert.Equal(t, id, elegir(t, s, ""))
+	}
+
+	// Menos pendientes evita los nodos con solicitudes en curso
+	s = nuevoSelectorPrueba(t, MENOS_PENDIENTES)
+	for _, n := range s.Nodos()[:3] {
+		s.Iniciar(n)
+	}
+	assert.Equal(t, "DNS4", elegir(t, s, ""))
+
+	// Hash consistente asigna siempre el mismo nodo a una zona y, si ese nodo falla,
+	// solo se reasignan las zonas que tenía
+	s = nuevoSelectorPrueba(t, HASH_CONSISTENTE)
+	zonas := []string{"com", "cl", "org", "net", "example.com", "uchile.cl", "go.dev", "io"}
+	asignados := map[string]string{}
+	for _, zona := range zonas {
+		asignados[zona] = elegir(t, s, zona)
+		assert.Equal(t, asignados[zona], elegir(t, s, zona))
+	}
+	caido, _ := s.Buscar("127.0.0.1", "9002")
+	for i := 0; i < UMBRAL_FALLOS; i++ {
+		s.Registrar(caido, false)
+	}
+	for _, zona := range zonas {
+		if asignados[zona] != "DNS2" {
+			assert.Equal(t, asignados[zona], elegir(t, s, zona), zona)
+		} else {
+			assert.NotEqual(t, "DNS2", elegir(t, s, zona), zona)
+		}
+	}
+}
+
+func TestDeteccionFallos(t *testing.T) {
+	s := nuevoSelectorPrueba(t, ROUND_ROBIN)
+	n, _ := s.Buscar("127.0.0.1", "9001")
+
+	// Los errores propios de la consulta no cuentan como fallos del nodo, tampoco los que el
+	// servidor retorna sin código gRPC
+	for i := 0; i < UMBRAL_FALLOS; i++ {
+		s.Iniciar(n)
+		s.Terminar(n, status.Error(codes.NotFound, "no existe"))
+		s.Iniciar(n)
+		s.Terminar(n, status.Error(codes.Unknown, "El nombre ya existe en este servidor"))
+	}
+	assert.True(t, n.Activo())
+
+	// Tras varios fallos consecutivos el nodo deja de ser elegido hasta que vuelve a responder
+	for i := 0; i < UMBRAL_FALLOS; i++ {
+		s.Iniciar(n)
+		s.Terminar(n, status.Error(codes.Unavailable, "sin conexión"))
+	}
+	assert.False(t, n.Activo())
+	assert.Equal(t, int64(0), n.Pendientes())
+	for i := 0; i < 6; i++ {
+		assert.NotEqual(t, "DNS1", elegir(t, s, ""))
+	}
+
+	s.Registrar(n, true)
+	assert.True(t, n.Activo())
+
+	// Sin nodos activos no es posible elegir
+	for _, n := range s.Nodos() {
+		for i := 0; i < UMBRAL_FALLOS; i++ {
+			s.Registrar(n, false)
+		}
+	}
+	_, err := s.Elegir("")
+	assert.NotNil(t, err)
+	assert.False(t, EsFallaDeNodo(nil))
+	assert.False(t, EsFallaDeNodo(status.Error(codes.InvalidArgument, "")))
+	assert.False(t, EsFallaDeNodo(errors.New("No existen registros que eliminar")))
+	assert.False(t, EsFallaDeNodo(status.Error(codes.Internal, "")))
+	assert.True(t, EsFallaDeNodo(status.Error(codes.DeadlineExceeded, "")))
+	assert.True(t, EsFallaDeNodo(status.Error(codes.ResourceExhausted, "")))
+}
+
+func TestErrorNodoFijado(t *testing.T) {
+	err := ErrorNodoFijado("127.0.0.1", "9001", status.Error(codes.Unavailable, "sin conexión"))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.True(t, EsNodoFijadoNoDisponible(err))
+	assert.False(t, EsNodoFijadoNoDisponible(status.Error(codes.Unavailable, "sin conexión")))
+	assert.False(t, EsNodoFijadoNoDisponible(nil))
+}
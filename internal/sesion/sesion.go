@@ -0,0 +1,308 @@
+package sesion
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/relojes"
+	"github.com/jfomu/DNSDistribuido/internal/seleccion"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Garantías de sesión sobre las réplicas de cada dominio
+type Garantia int
+
+const (
+	LECTURAS_MONOTONICAS Garantia = iota // una lectura no entrega una versión anterior a otra ya leída
+	LEER_ESCRITURAS_PROPIAS // una lectura incluye las escrituras ya realizadas en la sesión
+	ESCRITURAS_MONOTONICAS // una escritura se aplica sobre las escrituras anteriores de la sesión
+	ESCRITURAS_SIGUEN_LECTURAS // una escritura se aplica sobre las versiones ya leídas en la sesión
+)
+
+// Todas las garantías, activas por defecto en una sesión
+var Garantias = []Garantia{LECTURAS_MONOTONICAS, LEER_ESCRITURAS_PROPIAS, ESCRITURAS_MONOTONICAS, ESCRITURAS_SIGUEN_LECTURAS}
+
+func (g Garantia) String() string {
+	switch g {
+	case LECTURAS_MONOTONICAS:
+		return "lecturas monotónicas"
+	case LEER_ESCRITURAS_PROPIAS:
+		return "lectura de escrituras propias"
+	case ESCRITURAS_MONOTONICAS:
+		return "escrituras monotónicas"
+	}
+	return "escrituras siguen a lecturas"
+}
+
+// Resultado de una operación respecto de las garantías de la sesión
+type Resultado struct{
+	Garantias []Garantia // garantías activas que aplican a la operación
+	Cumplida bool // la réplica que atendió la operación tenía todas las versiones requeridas
+	Fijada bool // la operación se realizó en la réplica fijada por la sesión
+}
+
+func (r Resultado) String() string {
+	if len(r.Garantias) == 0 {
+		return "sin garantías de sesión"
+	}
+	nombres := make([]string, len(r.Garantias))
+	for i, g := range r.Garantias {
+		nombres[i] = g.String()
+	}
+	if !r.Cumplida {
+		return "NO garantizadas (" + strings.Join(nombres, ", ") + "), la réplica podría estar desactualizada"
+	}
+	if r.Fijada {
+		return strings.Join(nombres, ", ") + " (en la réplica fijada)"
+	}
+	return strings.Join(nombres, ", ")
+}
+
+// Estado de la sesión para un dominio
+type dominio struct{
+	lecturas relojes.Reloj // versiones leídas
+	escrituras relojes.Reloj // versiones producidas por las escrituras de la sesión
+	ip string // réplica cuya versión incluye todas las lecturas y escrituras de la sesión
+	port string
+}
+
+// Sesión de un cliente o administrador que aplica las garantías de sesión por dominio, aunque el
+// broker elija réplicas distintas en cada operación. Las lecturas se realizan a través del broker
+// y, si la réplica elegida no tiene las versiones requeridas, se repiten en la réplica fijada por
+// la sesión. Las escrituras que requieren versiones previas se realizan directamente en la réplica
+// fijada. Si esta no está disponible, la operación se entrega indicando que las garantías no se
+// cumplieron.
+type Sesion struct{
+	mu sync.Mutex
+	activas map[Garantia]bool
+	dominios map[string]*dominio
+}
+
+// Crea una sesión con las garantías indicadas, o con todas si no se indica ninguna
+func NuevaSesion(garantias ...Garantia) *Sesion {
+	if len(garantias) == 0 {
+		garantias = Garantias
+	}
+	s := &Sesion{activas: make(map[Garantia]bool), dominios: make(map[string]*dominio)}
+	for _, g := range garantias {
+		s.activas[g] = true
+	}
+	return s
+}
+
+// Dominios sobre los que se ha operado en la sesión
+func (s *Sesion) Dominios() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dominios []string
+	for d := range s.dominios {
+		dominios = append(dominios, d)
+	}
+	return dominios
+}
+
+// Reloj de las versiones leídas de un dominio, nil si aún no se ha leído
+func (s *Sesion) Lecturas(nombreDominio string) relojes.Reloj {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.dominios[nombreDominio]; ok {
+		return d.lecturas.Copia()
+	}
+	return nil
+}
+
+// Reloj de las escrituras realizadas sobre un dominio, nil si aún no se ha escrito
+func (s *Sesion) Escrituras(nombreDominio string) relojes.Reloj {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.dominios[nombreDominio]; ok {
+		return d.escrituras.Copia()
+	}
+	return nil
+}
+
+// Réplica fijada por la sesión para un dominio, cuya versión incluye todas sus lecturas y
+// escrituras; nil si aún no hay una
+func (s *Sesion) Fijacion(nombreDominio string) *pb.Fijacion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.dominios[nombreDominio]; ok && d.ip != "" {
+		return &pb.Fijacion{Ip: d.ip, Port: d.port}
+	}
+	return nil
+}
+
+// Versión que debe incluir una réplica para cumplir las garantías activas de una operación, y la
+// réplica fijada que la tiene
+type requisito struct{
+	reloj relojes.Reloj
+	garantias []Garantia
+	ip string
+	port string
+}
+
+// Obtiene el requisito de una operación a partir de la garantía que depende de las lecturas de la
+// sesión y la que depende de sus escrituras
+func (s *Sesion) requisito(nombreDominio string, porLecturas Garantia, porEscrituras Garantia) requisito {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var req requisito
+	d, ok := s.dominios[nombreDominio]
+	if s.activas[porLecturas] {
+		req.garantias = append(req.garantias, porLecturas)
+		if ok {
+			req.reloj = req.reloj.Fusionar(d.lecturas)
+		}
+	}
+	if s.activas[porEscrituras] {
+		req.garantias = append(req.garantias, porEscrituras)
+		if ok {
+			req.reloj = req.reloj.Fusionar(d.escrituras)
+		}
+	}
+	if ok {
+		req.ip, req.port = d.ip, d.port
+	}
+	return req
+}
+
+// Registra una operación realizada en una réplica. La réplica pasa a ser la fijada solo si su
+// versión incluye todas las lecturas y escrituras de la sesión.
+func (s *Sesion) registrar(nombreDominio string, reloj relojes.Reloj, escritura bool, ip string, port string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.dominios[nombreDominio]
+	if !ok {
+		d = new(dominio)
+		s.dominios[nombreDominio] = d
+	}
+	if escritura {
+		d.escrituras = d.escrituras.Fusionar(reloj)
+	} else {
+		d.lecturas = d.lecturas.Fusionar(reloj)
+	}
+	if reloj.Incluye(d.lecturas.Fusionar(d.escrituras)) {
+		d.ip, d.port = ip, port
+	}
+}
+
+// Indica si un error de Get puede deberse a una réplica desactualizada, que aún no conoce el
+// nombre o el dominio
+func inexistente(err error) bool {
+	codigo := status.Code(err)
+	return codigo == codes.NotFound || codigo == codes.FailedPrecondition
+}
+
+
+//// LECTURAS
+
+// Realiza un Get sobre un nombre del dominio indicado aplicando las lecturas monotónicas y la
+// lectura de escrituras propias. Si la réplica elegida por el broker no incluye las versiones
+// requeridas (su reloj es anterior o concurrente), o no conoce un nombre de un dominio ya
+// utilizado, la consulta se repite en la réplica fijada.
+func (s *Sesion) Get(ctx context.Context, broker pb.ServicioNodoClient, nombreDominio string, consulta *pb.Consulta) (*pb.Respuesta, Resultado, error) {
+	req := s.requisito(nombreDominio, LECTURAS_MONOTONICAS, LEER_ESCRITURAS_PROPIAS)
+	resultado := Resultado{Garantias: req.garantias}
+
+	respuesta, err := broker.Get(ctx, consulta)
+	if err == nil && relojes.Reloj(respuesta.Reloj).Incluye(req.reloj) {
+		s.registrar(nombreDominio, respuesta.Reloj, false, respuesta.Ip, respuesta.Port)
+		resultado.Cumplida = true
+		return respuesta, resultado, nil
+	}
+	if req.ip == "" || (err != nil && !inexistente(err)) {
+		return respuesta, resultado, err
+	}
+
+	// La réplica elegida está desactualizada, se repite la lectura en la réplica fijada
+	fijada := &pb.Consulta{NombreDominio: consulta.NombreDominio, Tipo: consulta.Tipo, Fijacion: &pb.Fijacion{Ip: req.ip, Port: req.port}}
+	repetida, errFijada := broker.Get(ctx, fijada)
+	if seleccion.EsNodoFijadoNoDisponible(errFijada) {
+		return respuesta, resultado, err
+	}
+	resultado.Fijada = true
+	if errFijada != nil {
+		return nil, resultado, errFijada
+	}
+	if relojes.Reloj(repetida.Reloj).Incluye(req.reloj) {
+		s.registrar(nombreDominio, repetida.Reloj, false, repetida.Ip, repetida.Port)
+		resultado.Cumplida = true
+	}
+	return repetida, resultado, nil
+}
+
+
+//// ESCRITURAS
+
+// Realiza una escritura aplicando las escrituras monotónicas y las escrituras que siguen a las
+// lecturas. Si la sesión ya tiene versiones requeridas del dominio, la escritura se realiza en la
+// réplica fijada; si esta no está disponible, se realiza en la réplica elegida por el broker. Como
+// una réplica solo avanza su propia posición del reloj al escribir, el reloj resultante incluye las
+// versiones requeridas solo si la réplica ya las tenía.
+func (s *Sesion) escribir(nombreDominio string, escribir func(fijacion *pb.Fijacion) (relojes.Reloj, string, string, error)) (Resultado, error) {
+	req := s.requisito(nombreDominio, ESCRITURAS_SIGUEN_LECTURAS, ESCRITURAS_MONOTONICAS)
+	resultado := Resultado{Garantias: req.garantias}
+
+	if req.ip != "" && !req.reloj.EsCero() {
+		reloj, ip, port, err := escribir(&pb.Fijacion{Ip: req.ip, Port: req.port})
+		if !seleccion.EsNodoFijadoNoDisponible(err) {
+			resultado.Fijada = true
+			if err != nil {
+				return resultado, err
+			}
+			resultado.Cumplida = reloj.Incluye(req.reloj)
+			s.registrar(nombreDominio, reloj, true, ip, port)
+			return resultado, nil
+		}
+	}
+
+	reloj, ip, port, err := escribir(nil)
+	if err != nil {
+		return resultado, err
+	}
+	resultado.Cumplida = reloj.Incluye(req.reloj)
+	s.registrar(nombreDominio, reloj, true, ip, port)
+	return resultado, nil
+}
+
+func (s *Sesion) Create(ctx context.Context, broker pb.ServicioNodoClient, nombreDominio string, consulta *pb.Consulta) (*pb.Respuesta, Resultado, error) {
+	var respuesta *pb.Respuesta
+	resultado, err := s.escribir(nombreDominio, func(fijacion *pb.Fijacion) (relojes.Reloj, string, string, error) {
+		c := &pb.Consulta{NombreDominio: consulta.NombreDominio, Ip: consulta.Ip, Tipo: consulta.Tipo, Recurso: consulta.Recurso, Fijacion: fijacion}
+		var err error
+		if respuesta, err = broker.Create(ctx, c); err != nil {
+			return nil, "", "", err
+		}
+		return respuesta.Reloj, respuesta.Ip, respuesta.Port, nil
+	})
+	return respuesta, resultado, err
+}
+
+func (s *Sesion) Update(ctx context.Context, broker pb.ServicioNodoClient, nombreDominio string, consulta *pb.ConsultaUpdate) (*pb.RespuestaAdmin, Resultado, error) {
+	var respuesta *pb.RespuestaAdmin
+	resultado, err := s.escribir(nombreDominio, func(fijacion *pb.Fijacion) (relojes.Reloj, string, string, error) {
+		c := &pb.ConsultaUpdate{NombreDominio: consulta.NombreDominio, Opcion: consulta.Opcion, Param: consulta.Param, Fijacion: fijacion}
+		var err error
+		if respuesta, err = broker.Update(ctx, c); err != nil {
+			return nil, "", "", err
+		}
+		return respuesta.Reloj, respuesta.Ip, respuesta.Port, nil
+	})
+	return respuesta, resultado, err
+}
+
+func (s *Sesion) Delete(ctx context.Context, broker pb.ServicioNodoClient, nombreDominio string, consulta *pb.ConsultaAdmin) (*pb.RespuestaAdmin, Resultado, error) {
+	var respuesta *pb.RespuestaAdmin
+	resultado, err := s.escribir(nombreDominio, func(fijacion *pb.Fijacion) (relojes.Reloj, string, string, error) {
+		c := &pb.ConsultaAdmin{NombreDominio: consulta.NombreDominio, Recurso: consulta.Recurso, Fijacion: fijacion}
+		var err error
+		if respuesta, err = broker.Delete(ctx, c); err != nil {
+			return nil, "", "", err
+		}
+		return respuesta.Reloj, respuesta.Ip, respuesta.Port, nil
+	})
+	return respuesta, resultado, err
+}
@@ -0,0 +1,154 @@
+package sesion
+
+import (
+	"context"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	pb "github.com/jfomu/DNSDistribuido/internal/proto"
+	"github.com/jfomu/DNSDistribuido/internal/relojes"
+	"github.com/jfomu/DNSDistribuido/internal/seleccion"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Broker de prueba: entrega las respuestas de cada réplica según la consulta, sin fijar usa la réplica indicada
+type brokerPrueba struct{
+	pb.ServicioNodoClient
+	replicas map[string]*pb.Respuesta // por puerto, nil si la réplica no está disponible
+	siguiente string
+	consultas int
+	escrituras []string // puerto de la réplica que recibió cada escritura
+}
+
+func (b *brokerPrueba) replica(fijacion *pb.Fijacion) (*pb.Respuesta, error) {
+	port := b.siguiente
+	if fijacion != nil {
+		port = fijacion.Port
+	}
+	respuesta := b.replicas[port]
+	if respuesta == nil {
+		if fijacion != nil {
+			return nil, seleccion.ErrorNodoFijado(fijacion.Ip, port, status.Error(codes.Unavailable, "sin conexión"))
+		}
+		return nil, status.Error(codes.NotFound, "no existe")
+	}
+	return respuesta, nil
+}
+
+func (b *brokerPrueba) Get(ctx context.Context, in *pb.Consulta, opts ...grpc.CallOption) (*pb.Respuesta, error) {
+	b.consultas += 1
+	return b.replica(in.Fijacion)
+}
+
+// Una escritura avanza la posición de la réplica (su puerto menos 9001) en su reloj
+func (b *brokerPrueba) Update(ctx context.Context, in *pb.ConsultaUpdate, opts ...grpc.CallOption) (*pb.RespuestaAdmin, error) {
+	respuesta, err := b.replica(in.Fijacion)
+	if err != nil {
+		return nil, err
+	}
+	i := int(respuesta.Port[3] - '1')
+	respuesta.Reloj = relojes.Reloj(respuesta.Reloj).Fusionar(make(relojes.Reloj, i+1))
+	respuesta.Reloj[i] += 1
+	b.escrituras = append(b.escrituras, respuesta.Port)
+	return &pb.RespuestaAdmin{Reloj: append([]int32{}, respuesta.Reloj...), Ip: respuesta.Ip, Port: respuesta.Port}, nil
+}
+
+func TestLecturasMonotonicas(t *testing.T) {
+	b := &brokerPrueba{replicas: map[string]*pb.Respuesta{
+		"9001": {Respuesta: "1.1.1.2", Reloj: []int32{2,1,0}, Ip: "127.0.0.1", Port: "9001"},
+		"9002": {Respuesta: "1.1.1.1", Reloj: []int32{1,1,0}, Ip: "127.0.0.1", Port: "9002"},
+		"9003": {Respuesta: "1.1.1.3", Reloj: []int32{1,1,1}, Ip: "127.0.0.1", Port: "9003"},
+	}}
+	s := NuevaSesion(LECTURAS_MONOTONICAS)
+	consulta := &pb.Consulta{NombreDominio: "www.example.com"}
+
+	b.siguiente = "9001"
+	r, res, err := s.Get(context.Background(), b, "example.com", consulta)
+	assert.Nil(t, err)
+	assert.True(t, res.Cumplida)
+	assert.Equal(t, relojes.Reloj{2,1,0}, s.Lecturas("example.com"))
+
+	// Una réplica con una versión anterior se descarta y se repite la lectura en la fijada
+	b.siguiente = "9002"
+	r, res, err = s.Get(context.Background(), b, "example.com", consulta)
+	assert.Nil(t, err)
+	assert.True(t, res.Cumplida && res.Fijada)
+	assert.Equal(t, "1.1.1.2", r.Respuesta)
+	assert.Equal(t, 3, b.consultas)
+
+	// También una réplica con una versión concurrente
+	b.siguiente = "9003"
+	r, res, _ = s.Get(context.Background(), b, "example.com", consulta)
+	assert.True(t, res.Cumplida && res.Fijada)
+	assert.Equal(t, "1.1.1.2", r.Respuesta)
+
+	// Un nombre que la réplica no conoce también se repite en la fijada
+	b.siguiente = "9004"
+	r, res, err = s.Get(context.Background(), b, "example.com", consulta)
+	assert.Nil(t, err)
+	assert.True(t, res.Cumplida && res.Fijada)
+
+	// Si la réplica fijada no está disponible se entrega lo obtenido, indicándolo
+	b.replicas["9001"] = nil
+	b.siguiente = "9002"
+	r, res, err = s.Get(context.Background(), b, "example.com", consulta)
+	assert.Nil(t, err)
+	assert.False(t, res.Cumplida)
+	assert.Equal(t, "1.1.1.1", r.Respuesta)
+	assert.Equal(t, relojes.Reloj{2,1,0}, s.Lecturas("example.com"))
+
+	// Las versiones posteriores avanzan la sesión y fijan la nueva réplica
+	b.replicas["9003"].Reloj = []int32{2,1,1}
+	b.siguiente = "9003"
+	_, res, _ = s.Get(context.Background(), b, "example.com", consulta)
+	assert.True(t, res.Cumplida && !res.Fijada)
+	assert.Equal(t, relojes.Reloj{2,1,1}, s.Lecturas("example.com"))
+	assert.Nil(t, s.Lecturas("cl"))
+}
+
+func TestEscrituras(t *testing.T) {
+	b := &brokerPrueba{replicas: map[string]*pb.Respuesta{
+		"9001": {Respuesta: "1.1.1.1", Reloj: []int32{0,0}, Ip: "127.0.0.1", Port: "9001"},
+		"9002": {Respuesta: "1.1.1.1", Reloj: []int32{0,0}, Ip: "127.0.0.1", Port: "9002"},
+	}}
+	s := NuevaSesion()
+	update := &pb.ConsultaUpdate{NombreDominio: "www.example.com", Opcion: "ttl", Param: "60"}
+
+	// La primera escritura no requiere versiones, se realiza donde indique el broker
+	assert.Nil(t, s.Fijacion("example.com"))
+	b.siguiente = "9001"
+	_, res, err := s.Update(context.Background(), b, "example.com", update)
+	assert.Nil(t, err)
+	assert.True(t, res.Cumplida && !res.Fijada)
+	assert.Equal(t, relojes.Reloj{1,0}, s.Escrituras("example.com"))
+	assert.Equal(t, "9001", s.Fijacion("example.com").GetPort())
+
+	// Las siguientes se fijan a la réplica que tiene las escrituras anteriores (escrituras monotónicas)
+	b.siguiente = "9002"
+	_, res, _ = s.Update(context.Background(), b, "example.com", update)
+	assert.True(t, res.Cumplida && res.Fijada)
+	assert.Equal(t, []string{"9001", "9001"}, b.escrituras)
+
+	// Otra sesión no depende de las escrituras de esta, las suyas se realizan donde indique el broker
+	otra := NuevaSesion()
+	_, res, err = otra.Update(context.Background(), b, "example.com", update)
+	assert.Nil(t, err)
+	assert.True(t, res.Cumplida && !res.Fijada)
+	assert.Equal(t, []string{"9001", "9001", "9002"}, b.escrituras)
+	assert.Equal(t, "9002", otra.Fijacion("example.com").GetPort())
+
+	// Una lectura en una réplica sin las escrituras propias se repite en la fijada
+	_, res, err = s.Get(context.Background(), b, "example.com", &pb.Consulta{NombreDominio: "www.example.com"})
+	assert.Nil(t, err)
+	assert.True(t, res.Cumplida && res.Fijada)
+	assert.Equal(t, relojes.Reloj{2,0}, s.Lecturas("example.com"))
+
+	// Si la réplica fijada no está disponible la escritura se realiza en otra, indicándolo
+	b.replicas["9001"] = nil
+	_, res, err = s.Update(context.Background(), b, "example.com", update)
+	assert.Nil(t, err)
+	assert.False(t, res.Cumplida)
+	assert.Equal(t, relojes.Reloj{2,2}, s.Escrituras("example.com"))
+	assert.Contains(t, res.String(), "NO garantizadas")
+}
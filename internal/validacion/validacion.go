@@ -0,0 +1,146 @@
+package validacion
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	LARGO_MAXIMO_NOMBRE = 253 // RFC 1035, sin el punto final
+	LARGO_MAXIMO_ETIQUETA = 63
+	LARGO_MAXIMO_TEXTO = 255 // largo de un texto de registro TXT
+	TTL_MAXIMO = 2147483647 // RFC 2181
+)
+
+// Error de validación de un dato recibido, indica el campo que no cumple el formato
+type Error struct{
+	Campo string
+	Descripcion string
+}
+
+func (e *Error) Error() string {
+	return e.Descripcion
+}
+
+func Nuevo(campo string, descripcion string) *Error {
+	return &Error{Campo: campo, Descripcion: descripcion}
+}
+
+// Verifica que un nombre de dominio (ya normalizado, en minúsculas y sin punto final) cumpla
+// las reglas de RFC 1123: etiquetas de 1 a 63 caracteres formadas por letras, dígitos y guiones,
+// sin guiones al comienzo ni al final. Se admite además un guion bajo al comienzo de una etiqueta,
+// utilizado en los nombres de servicios (por ejemplo _sip._tcp.example.com).
+func Nombre(campo string, nombre string) error {
+	if len(nombre) > LARGO_MAXIMO_NOMBRE {
+		return Nuevo(campo, "El nombre " + nombre + " supera los " + strconv.Itoa(LARGO_MAXIMO_NOMBRE) + " caracteres")
+	}
+	for _, etiqueta := range strings.Split(nombre, ".") {
+		if etiqueta == "" {
+			return Nuevo(campo, "El nombre " + nombre + " contiene una etiqueta vacía")
+		}
+		if len(etiqueta) > LARGO_MAXIMO_ETIQUETA {
+			return Nuevo(campo, "La etiqueta " + etiqueta + " supera los " + strconv.Itoa(LARGO_MAXIMO_ETIQUETA) + " caracteres")
+		}
+		if etiqueta[0] == '-' || etiqueta[len(etiqueta) - 1] == '-' {
+			return Nuevo(campo, "La etiqueta " + etiqueta + " no puede comenzar ni terminar con un guion")
+		}
+		for i, c := range etiqueta {
+			valido := (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || (c == '_' && i == 0)
+			if !valido {
+				return Nuevo(campo, "La etiqueta " + etiqueta + " contiene caracteres inválidos, solo se admiten letras, dígitos y guiones")
+			}
+		}
+	}
+	return nil
+}
+
+func IPv4(campo string, ip string) error {
+	if ip == "" {
+		return Nuevo(campo, "No se ha especificado la dirección IP")
+	}
+	direccion := net.ParseIP(ip)
+	if direccion == nil || direccion.To4() == nil || strings.Contains(ip, ":") {
+		return Nuevo(campo, ip + " no es una dirección IPv4 válida")
+	}
+	return nil
+}
+
+func IPv6(campo string, ip string) error {
+	if ip == "" {
+		return Nuevo(campo, "No se ha especificado la dirección IP")
+	}
+	direccion := net.ParseIP(ip)
+	if direccion == nil || !strings.Contains(ip, ":") {
+		return Nuevo(campo, ip + " no es una dirección IPv6 válida")
+	}
+	return nil
+}
+
+func TTL(campo string, ttl uint64) error {
+	if ttl > TTL_MAXIMO {
+		return Nuevo(campo, "El TTL no puede superar " + strconv.Itoa(TTL_MAXIMO) + " segundos")
+	}
+	return nil
+}
+
+func Texto(campo string, texto string) error {
+	if len(texto) > LARGO_MAXIMO_TEXTO {
+		return Nuevo(campo, "El texto supera los " + strconv.Itoa(LARGO_MAXIMO_TEXTO) + " caracteres")
+	}
+	for _, c := range texto {
+		if c < ' ' || c == 0x7f {
+			return Nuevo(campo, "El texto contiene caracteres de control")
+		}
+	}
+	return nil
+}
+
+
+//// ERRORES gRPC
+
+// Convierte un error de validación en un error gRPC InvalidArgument que detalla el campo inválido,
+// los demás errores se retornan sin cambios
+func ErrorGRPC(err error) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	estado := status.New(codes.InvalidArgument, e.Descripcion)
+	detalle := &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+		{Field: e.Campo, Description: e.Descripcion},
+	}}
+	if conDetalle, err := estado.WithDetails(detalle); err == nil {
+		estado = conDetalle
+	}
+	return estado.Err()
+}
+
+// Genera un texto legible a partir de un error recibido desde un servidor gRPC
+func Describir(err error) string {
+	estado, ok := status.FromError(err)
+	if !ok {
+		return err.Error()
+	}
+	if estado.Code() != codes.InvalidArgument {
+		return estado.Message()
+	}
+
+	var campos []string
+	for _, detalle := range estado.Details() {
+		if solicitud, ok := detalle.(*errdetails.BadRequest); ok {
+			for _, violacion := range solicitud.FieldViolations {
+				campos = append(campos, violacion.Field)
+			}
+		}
+	}
+	if len(campos) == 0 {
+		return "Argumento inválido: " + estado.Message()
+	}
+	return "Argumento inválido (" + strings.Join(campos, ", ") + "): " + estado.Message()
+}
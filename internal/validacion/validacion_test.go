@@ -0,0 +1,35 @@
+package validacion
+
+import (
+	"errors"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNombre(t *testing.T) {
+	for _, valido := range []string{"google.com", "www.example.com", "_sip._tcp.example.com", "1.1.1.1.in-addr.arpa", "a-b.cl"} {
+		assert.Nil(t, Nombre("nombre", valido), valido)
+	}
+	for _, invalido := range []string{"a..com", "-a.com", "a-.com", "a b.com", "a_b.com", "ñandu.cl", string(make([]byte, 64)) + ".com"} {
+		assert.NotNil(t, Nombre("nombre", invalido), invalido)
+	}
+}
+
+func TestDirecciones(t *testing.T) {
+	assert.Nil(t, IPv4("ip", "8.8.8.8"))
+	assert.NotNil(t, IPv4("ip", "8.8.8"))
+	assert.NotNil(t, IPv4("ip", "8.8.8.8 IN A"))
+	assert.NotNil(t, IPv4("ip", "::ffff:8.8.8.8"))
+	assert.Nil(t, IPv6("ip", "2001:db8::1"))
+	assert.NotNil(t, IPv6("ip", "8.8.8.8"))
+}
+
+func TestErrorGRPC(t *testing.T) {
+	err := ErrorGRPC(IPv4("ip", "8.8.8"))
+	assert.Equal(t, "Argumento inválido (ip): 8.8.8 no es una dirección IPv4 válida", Describir(err))
+
+	// Los errores que no son de validación no se modifican
+	otro := errors.New("No se encuentra el dominio registrado: cl")
+	assert.Equal(t, otro, ErrorGRPC(otro))
+	assert.Equal(t, otro.Error(), Describir(otro))
+}